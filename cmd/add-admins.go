@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -45,11 +47,11 @@ var adminsAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := adminBuildTableWriter()
-		createdList := []*models.Admin{}
-		total := 0
+		createdList := []interface{}{}
+		var total int64
 		err := forAllInput(cmd, args, true,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				admin := &apiadmins.CreateAdminParamsBodyAdmin{}
 				err := placeInputValues(cmd, values, admin,
 					func(s string) { admin.Name = s },
@@ -76,10 +78,10 @@ var adminsAddCmd = &cobra.Command{
 				createdList = append(createdList, admin)
 				adminTableWriterAppend(tw, admin)
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, nil)
+				createdList = append(createdList, inputErrorListItem(err, nil))
 				adminTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -98,6 +100,7 @@ func init() {
 
 	initOutputFlags(adminsAddCmd)
 	initLoopControlFlags(adminsAddCmd)
+	initParallelFlags(adminsAddCmd)
 	initTenantFlags(adminsAddCmd)
 
 	initInputFlags(adminsAddCmd, "admin",
@@ -118,6 +121,7 @@ func init() {
 			Mandatory:       true,
 			DefaultValue:    "",
 			MainField:       true,
+			SchemaName:      "email",
 		},
 		inputField{
 			Name:            "Authentication Type",
@@ -155,4 +159,5 @@ func init() {
 			DefaultValue:    []string{},
 			SchemaName:      "role_names",
 		})
+	initSkipExistingFlags(adminsAddCmd, "admin", "email")
 }
@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -45,11 +47,11 @@ var domainsAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := domainBuildTableWriter()
-		createdList := []*models.Asset{}
-		total := 0
+		createdList := []interface{}{}
+		var total int64
 		err := forAllInput(cmd, args, true,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				asset := &apiassets.CreateAssetBody{
 					Category: "domain",
 				}
@@ -73,10 +75,10 @@ var domainsAddCmd = &cobra.Command{
 				createdList = append(createdList, asset)
 				domainTableWriterAppend(tw, asset)
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, nil)
+				createdList = append(createdList, inputErrorListItem(err, nil))
 				domainTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -95,6 +97,7 @@ func init() {
 
 	initOutputFlags(domainsAddCmd)
 	initLoopControlFlags(domainsAddCmd)
+	initParallelFlags(domainsAddCmd)
 	initTenantFlags(domainsAddCmd)
 	initInputFlags(domainsAddCmd, "domain",
 		inputField{
@@ -114,5 +117,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       true,
 			DefaultValue:    "",
+			SchemaName:      "asset_source_id",
 		})
+	initSkipExistingFlags(domainsAddCmd, "domain", "name")
 }
@@ -19,6 +19,7 @@ limitations under the License.
 
 import (
 	"regexp"
+	"sync/atomic"
 
 	"github.com/spf13/cobra"
 
@@ -45,11 +46,11 @@ var groupsAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := groupBuildTableWriter()
-		createdList := []*apigroups.CreateGroupCreatedBody{}
-		total := 0
+		createdList := []interface{}{}
+		var total int64
 		err := forAllInput(cmd, args, true,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				group := &apigroups.CreateGroupParamsBodyGroup{}
 				err := placeInputValues(cmd, values, group,
 					func(s string) { group.Name = s },
@@ -73,10 +74,10 @@ var groupsAddCmd = &cobra.Command{
 				createdList = append(createdList, group)
 				groupTableWriterAppendFromSingle(tw, group.Group, len(group.Users))
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, nil)
+				createdList = append(createdList, inputErrorListItem(err, nil))
 				groupTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -95,6 +96,7 @@ func init() {
 
 	initOutputFlags(groupsAddCmd)
 	initLoopControlFlags(groupsAddCmd)
+	initParallelFlags(groupsAddCmd)
 	initTenantFlags(groupsAddCmd)
 	initInputFlags(groupsAddCmd, "group",
 		inputField{
@@ -114,6 +116,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "description",
 		},
 		inputField{
 			Name:            "Color",
@@ -123,7 +126,9 @@ func init() {
 			Mandatory:       false,
 			DefaultValue:    "",
 			Validator:       validateHTMLHexColor,
+			SchemaName:      "color",
 		})
+	initSkipExistingFlags(groupsAddCmd, "group", "name")
 }
 
 func validateHTMLHexColor(input interface{}) bool {
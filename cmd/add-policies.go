@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -44,11 +46,11 @@ var policiesAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := policyBuildTableWriter()
-		createdList := []*apipolicies.CreatePolicyCreatedBody{}
-		total := 0
+		createdList := []interface{}{}
+		var total int64
 		err := forAllInput(cmd, args, true,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				policy := &apipolicies.CreatePolicyParamsBodyAccessPolicy{}
 				enableRBAC := false
 				called := false
@@ -116,10 +118,10 @@ var policiesAddCmd = &cobra.Command{
 				createdList = append(createdList, policy)
 				policyTableWriterAppend(tw, policy.AccessPolicy, len(policy.AccessResources))
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, nil)
+				createdList = append(createdList, inputErrorListItem(err, nil))
 				policyTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -138,6 +140,7 @@ func init() {
 
 	initOutputFlags(policiesAddCmd)
 	initLoopControlFlags(policiesAddCmd)
+	initParallelFlags(policiesAddCmd)
 	initTenantFlags(policiesAddCmd)
 	initInputFlags(policiesAddCmd, "policy",
 		inputField{
@@ -157,6 +160,8 @@ func init() {
 			VarType:         "[]string",
 			Mandatory:       false,
 			DefaultValue:    []string{},
+			SchemaName:      "access_resource_ids",
+			Reference:       "resource",
 		},
 		inputField{
 			Name:            "RBAC",
@@ -165,6 +170,7 @@ func init() {
 			VarType:         "bool",
 			Mandatory:       false,
 			DefaultValue:    false,
+			SchemaName:      "conditions.rbac.enabled",
 		},
 		inputField{
 			Name:            "Groups",
@@ -173,6 +179,8 @@ func init() {
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "conditions.rbac.group_ids",
+			Reference:       "group",
 		},
 		inputField{
 			Name:            "Users",
@@ -181,5 +189,8 @@ func init() {
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "conditions.rbac.user_ids",
+			Reference:       "user",
 		})
+	initSkipExistingFlags(policiesAddCmd, "policy", "name")
 }
@@ -19,6 +19,7 @@ limitations under the License.
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
@@ -47,11 +48,11 @@ var proxiesAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := proxyBuildTableWriterForCreation()
-		createdList := []*apiproxies.CreateProxyCreatedBody{}
-		total := 0
+		createdList := []interface{}{}
+		var total int64
 		err := forAllInput(cmd, args, true,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				proxy := &apiproxies.CreateProxyBody{}
 				err := placeInputValues(cmd, values, proxy,
 					func(s string) { proxy.Name = s },
@@ -75,10 +76,10 @@ var proxiesAddCmd = &cobra.Command{
 				createdList = append(createdList, proxy)
 				proxyTableWriterAppendForCreation(tw, proxy)
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, nil)
+				createdList = append(createdList, inputErrorListItem(err, nil))
 				proxyTableWriterAppendErrorForCreation(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -113,7 +114,7 @@ func proxyTableWriterAppendForCreation(tw table.Writer, proxy *apiproxies.Create
 }
 
 func proxyTableWriterAppendErrorForCreation(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
@@ -141,6 +142,7 @@ func init() {
 
 	initOutputFlags(proxiesAddCmd)
 	initLoopControlFlags(proxiesAddCmd)
+	initParallelFlags(proxiesAddCmd)
 	initTenantFlags(proxiesAddCmd)
 	initInputFlags(proxiesAddCmd, "proxy",
 		inputField{
@@ -160,6 +162,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "location",
 		},
 		inputField{
 			Name:            "Host",
@@ -168,6 +171,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       true,
 			DefaultValue:    "",
+			SchemaName:      "host",
 		},
 		inputField{
 			Name:            "Port",
@@ -176,5 +180,7 @@ func init() {
 			VarType:         "int",
 			Mandatory:       true,
 			DefaultValue:    0,
+			SchemaName:      "port",
 		})
+	initSkipExistingFlags(proxiesAddCmd, "proxy", "name")
 }
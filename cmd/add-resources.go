@@ -19,6 +19,7 @@ limitations under the License.
 
 import (
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
@@ -48,11 +49,11 @@ var resourcesAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := resourceBuildTableWriter()
-		createdList := []*models.AccessResource{}
-		total := 0
+		createdList := []interface{}{}
+		var total int64
 		err := forAllInput(cmd, args, true,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				resource := &apiresources.CreateResourceParamsBodyAccessResource{}
 				resource.Enabled = true
 				err := placeInputValues(cmd, values, resource,
@@ -95,10 +96,10 @@ var resourcesAddCmd = &cobra.Command{
 				createdList = append(createdList, resp)
 				resourceTableWriterAppend(tw, *resp)
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, nil)
+				createdList = append(createdList, inputErrorListItem(err, nil))
 				resourceTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -140,6 +141,7 @@ func init() {
 
 	initOutputFlags(resourcesAddCmd)
 	initLoopControlFlags(resourcesAddCmd)
+	initParallelFlags(resourcesAddCmd)
 	initTenantFlags(resourcesAddCmd)
 	initInputFlags(resourcesAddCmd, "resource",
 		inputField{
@@ -159,6 +161,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       true,
 			DefaultValue:    "",
+			SchemaName:      "public_host",
 		},
 		inputField{
 			Name:            "Resource host",
@@ -167,6 +170,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       true,
 			DefaultValue:    "",
+			SchemaName:      "internal_host",
 		},
 		inputField{
 			Name:            "Port mappings",
@@ -175,22 +179,29 @@ func init() {
 			VarType:         "[]string.skipcomma",
 			Mandatory:       true,
 			DefaultValue:    []string{},
+			SchemaName:      "port_mappings",
+			SchemaVarType:   "[]portmapping",
+			Validator:       validatePortMappings,
 		},
 		inputField{
 			Name:            "Proxy",
 			FlagName:        "proxy",
-			FlagDescription: "specify the proxy ID for the created resource",
+			FlagDescription: "specify the proxy ID or name for the created resource",
 			VarType:         "string",
 			Mandatory:       true,
 			DefaultValue:    "",
+			SchemaName:      "access_proxy_id",
+			Reference:       "proxy",
 		},
 		inputField{
 			Name:            "Policies",
 			FlagName:        "policies",
-			FlagDescription: "specify a list of comma-separated policy IDs for the created resource",
+			FlagDescription: "specify a list of comma-separated policy IDs or names for the created resource",
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "access_policy_ids",
+			Reference:       "policy",
 		},
 		inputField{
 			Name:            "Wildcard Exceptions",
@@ -199,6 +210,7 @@ func init() {
 			VarType:         "[]string",
 			Mandatory:       false,
 			DefaultValue:    []string{},
+			SchemaName:      "wildcard_exceptions",
 		},
 		inputField{
 			Name:            "Notes",
@@ -207,6 +219,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "notes",
 		},
 		inputField{
 			Name:            "Fixed Last Octet",
@@ -215,5 +228,8 @@ func init() {
 			VarType:         "string", // use string to read "null" pseudo value
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "fixed_last_octet",
+			SchemaVarType:   "int.nullable",
 		})
+	initSkipExistingFlags(resourcesAddCmd, "resource", "public_host")
 }
@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -28,7 +30,7 @@ import (
 // usersAddCmd represents the add command
 var usersAddCmd = &cobra.Command{
 	Use:     "add",
-	Aliases: []string{"create", "new"},
+	Aliases: []string{"create", "new", "import"},
 	Short:   "Add users",
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		err := preRunCheckAuth(cmd, args)
@@ -45,8 +47,8 @@ var usersAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := userBuildTableWriter()
-		createdList := []*models.User{}
-		total := 0
+		createdList := []interface{}{}
+		var total int64
 
 		// Assign deprecated username if name was not supplied
 		name, _ := cmd.Flags().GetString("name")
@@ -57,7 +59,7 @@ var usersAddCmd = &cobra.Command{
 
 		err := forAllInput(cmd, args, true,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				user := &struct {
 					apiusers.CreateUserParamsBodyUser
 					Groups []struct {
@@ -98,10 +100,10 @@ var usersAddCmd = &cobra.Command{
 				createdList = append(createdList, &user)
 				userTableWriterAppend(tw, user)
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, nil)
+				createdList = append(createdList, inputErrorListItem(err, nil))
 				userTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -120,6 +122,7 @@ func init() {
 
 	initOutputFlags(usersAddCmd)
 	initLoopControlFlags(usersAddCmd)
+	initParallelFlags(usersAddCmd)
 	initTenantFlags(usersAddCmd)
 
 	initInputFlags(usersAddCmd, "user",
@@ -149,6 +152,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "email",
 		},
 		inputField{
 			Name:            "Phone",
@@ -157,14 +161,18 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "phone_number",
 		},
 		inputField{
 			Name:            "Groups",
 			FlagName:        "groups",
-			FlagDescription: "specify the group IDs for the created user",
+			FlagDescription: "specify the group IDs or names for the created user",
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "group_ids",
+			SchemaAliases:   []string{"groups"},
+			Reference:       "group",
 		},
 		inputField{
 			Name:            "Enabled",
@@ -173,6 +181,7 @@ func init() {
 			VarType:         "bool",
 			Mandatory:       false,
 			DefaultValue:    true,
+			SchemaName:      "enabled",
 		},
 		inputField{
 			Name:            "Send email invitation",
@@ -181,7 +190,10 @@ func init() {
 			VarType:         "bool",
 			Mandatory:       false,
 			DefaultValue:    false,
+			SchemaName:      "send_email_invitation",
 		})
+	initSkipExistingFlags(usersAddCmd, "user", "email")
+	initSCIMInputFlags(usersAddCmd, scimSchemaUser)
 	usersAddCmd.Flags().MarkDeprecated("username", "use name instead")
 
 }
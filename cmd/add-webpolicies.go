@@ -44,17 +44,21 @@ var webPoliciesAddCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := setWebPolicyBuildTableWriter()
-		gparams := apiwebpolicies.NewListWebPoliciesParams()
-		setTenant(cmd, gparams)
-		resp, err := global.Client.WebPolicies.ListWebPolicies(gparams, global.AuthWriter)
+		resp := &apiwebpolicies.ListWebPoliciesOK{Payload: &models.WebPolicy{}}
+		var err error
+		if !inputNeedsNoServer(cmd) {
+			gparams := apiwebpolicies.NewListWebPoliciesParams()
+			setTenant(cmd, gparams)
+			resp, err = global.Client.WebPolicies.ListWebPolicies(gparams, global.AuthWriter)
 
-		if err != nil {
-			return processErrorResponse(err)
+			if err != nil {
+				return processErrorResponse(err)
+			}
 		}
 		mainRulesetId := resp.Payload.ID
 
 		total := 0
-		createdList := []*models.WebPolicyData{}
+		createdList := []interface{}{}
 		createdPolicy := &models.WebPolicyData{}
 		policy := &apiwebpolicies.AddWebPolicyParamsBodyData{}
 
@@ -117,7 +121,7 @@ var webPoliciesAddCmd = &cobra.Command{
 				createdList = append(createdList, createdPolicy)
 				setWebPolicyTableWriterAppend(tw, ruleId, *createdPolicy)
 			}, func(err error, id interface{}) { // doOnError func
-				createdList = append(createdList, createdPolicy)
+				createdList = append(createdList, inputErrorListItem(err, createdPolicy))
 				webPolicyTableWriterAppendError(tw, err, id)
 			})
 		return printListOutputAndError(cmd, createdList, tw, total, err)
@@ -194,22 +198,24 @@ func init() {
 		inputField{
 			Name:            "Users",
 			FlagName:        "user_ids",
-			FlagDescription: "specify the user ids for the created policy",
+			FlagDescription: "specify the user IDs, names or emails for the created policy",
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
 			MainField:       true,
 			SchemaName:      "user_ids",
+			Reference:       "user",
 		},
 		inputField{
 			Name:            "Groups",
 			FlagName:        "group_ids",
-			FlagDescription: "specify the group ids for the created policy",
+			FlagDescription: "specify the group IDs or names for the created policy",
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
 			MainField:       true,
 			SchemaName:      "group_ids",
+			Reference:       "group",
 		},
 		inputField{
 			Name:            "Index",
@@ -262,4 +268,5 @@ func init() {
 			SchemaName:      "alert",
 		},
 	)
+	initSkipExistingFlags(webPoliciesAddCmd, "webpolicy", "label")
 }
@@ -0,0 +1,196 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+
+	apigroups "github.com/barracuda-cloudgen-access/access-cli/client/groups"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// groupsApplyCmd represents the apply command
+var groupsApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create or update groups",
+	Long: `Create or update groups, matching existing groups by name.
+Groups that do not exist are created, and existing groups are updated
+when their description or color differ from the ones provided.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tw, j := applyBuildTableWriter()
+		var total int64
+		err := forAllInput(cmd, args, false,
+			func(values *inputEntry) (interface{}, error) { // do func
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
+				group := &struct {
+					Name        *string `json:"name"`
+					Description *string `json:"description"`
+					Color       *string `json:"color"`
+				}{}
+				err := placeInputValues(cmd, values, group,
+					func(s string) { group.Name = &s },
+					func(s string) { group.Description = &s },
+					func(s string) { group.Color = &s })
+				if err != nil {
+					return nil, err
+				}
+				if group.Name == nil || *group.Name == "" {
+					return nil, fmt.Errorf("missing group name")
+				}
+
+				existing, err := findGroupByName(cmd, *group.Name)
+				if err != nil {
+					return nil, err
+				}
+
+				if existing == nil {
+					body := apigroups.CreateGroupBody{Group: &apigroups.CreateGroupParamsBodyGroup{
+						Name: *group.Name,
+					}}
+					if group.Description != nil {
+						body.Group.Description = *group.Description
+					}
+					if group.Color != nil {
+						body.Group.Color = *group.Color
+					}
+					params := apigroups.NewCreateGroupParams()
+					setTenant(cmd, params)
+					params.SetGroup(body)
+
+					resp, err := global.Client.Groups.CreateGroup(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return &applyOutcome{Key: *group.Name, ID: resp.Payload.ID, Result: applyResultCreated}, nil
+				}
+
+				edited := models.Group{
+					Name:        existing.Name,
+					Description: existing.Description,
+					Color:       existing.Color,
+				}
+				if group.Description != nil {
+					edited.Description = *group.Description
+				}
+				if group.Color != nil {
+					edited.Color = *group.Color
+				}
+				if edited.Description == existing.Description && strings.EqualFold(edited.Color, existing.Color) {
+					return &applyOutcome{Key: *group.Name, ID: existing.ID, Result: applyResultUnchanged}, nil
+				}
+
+				params := apigroups.NewEditGroupParams()
+				setTenant(cmd, params)
+				params.SetID(existing.ID)
+				params.SetGroup(apigroups.EditGroupBody{Group: &edited})
+
+				_, err = global.Client.Groups.EditGroup(params, global.AuthWriter)
+				if err != nil {
+					return nil, err
+				}
+				return &applyOutcome{Key: *group.Name, ID: existing.ID, Result: applyResultUpdated}, nil
+			}, func(data interface{}) { // printSuccess func
+				applyTableWriterAppend(tw, &j, data.(*applyOutcome))
+			}, func(err error, id interface{}) { // doOnError func
+				applyTableWriterAppendError(tw, &j, err, id)
+			})
+		return printListOutputAndError(cmd, j, tw, int(total), err)
+	},
+}
+
+// findGroupByName returns the group with the given name (ignoring case),
+// or nil if there is none
+func findGroupByName(cmd *cobra.Command, name string) (*apigroups.ListGroupsOKBodyItems0, error) {
+	items, err := listAllGroups(cmd, name)
+	if err != nil {
+		return nil, err
+	}
+	found := []*apigroups.ListGroupsOKBodyItems0{}
+	for _, item := range items {
+		if strings.EqualFold(item.Name, name) {
+			found = append(found, item)
+		}
+	}
+	ids := []string{}
+	for _, group := range found {
+		ids = append(ids, fmt.Sprint(group.ID))
+	}
+	if err := applySingleMatch("group", name, ids); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return found[0], nil
+}
+
+func init() {
+	groupsCmd.AddCommand(groupsApplyCmd)
+
+	initOutputFlags(groupsApplyCmd)
+	initLoopControlFlags(groupsApplyCmd)
+	initParallelFlags(groupsApplyCmd)
+	initTenantFlags(groupsApplyCmd)
+	initInputFlags(groupsApplyCmd, "group",
+		inputField{
+			Name:            "Name",
+			FlagName:        "name",
+			FlagDescription: "specify the name of the group, used to find an existing group",
+			VarType:         "string",
+			Mandatory:       true,
+			DefaultValue:    "",
+			MainField:       true,
+			SchemaName:      "name",
+		},
+		inputField{
+			Name:            "Description",
+			FlagName:        "description",
+			FlagDescription: "specify the description for the group",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "description",
+		},
+		inputField{
+			Name:            "Color",
+			FlagName:        "color",
+			FlagDescription: "specify the color for the group (hexadecimal #RRGGBB format)",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			Validator:       validateHTMLHexColor,
+			SchemaName:      "color",
+		})
+}
@@ -0,0 +1,398 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/spf13/cobra"
+
+	apiresources "github.com/barracuda-cloudgen-access/access-cli/client/access_resources"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+	"github.com/barracuda-cloudgen-access/access-cli/serial"
+)
+
+// resourcesApplyCmd represents the apply command
+var resourcesApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create or update resources",
+	Long: `Create or update resources, matching existing resources by public host.
+Resources that do not exist are created, and existing resources are updated
+when any of the provided fields differ from the current ones.
+Creating a resource requires its name, resource host, port mappings and proxy.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tw, j := applyBuildTableWriter()
+		var total int64
+		err := forAllInput(cmd, args, false,
+			func(values *inputEntry) (interface{}, error) { // do func
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
+				resource := &struct {
+					PublicHost         *string                             `json:"public_host"`
+					Name               *string                             `json:"name"`
+					InternalHost       *string                             `json:"internal_host"`
+					PortMappings       []*models.AccessResourcePortMapping `json:"port_mappings"`
+					AccessProxyID      *strfmt.UUID                        `json:"access_proxy_id"`
+					AccessPolicyIds    []int64                             `json:"access_policy_ids"`
+					WildcardExceptions []string                            `json:"wildcard_exceptions"`
+					Notes              *string                             `json:"notes"`
+					FixedLastOctet     *serial.NullableOptionalInt         `json:"fixed_last_octet"`
+				}{}
+				err := placeInputValues(cmd, values, resource,
+					func(s string) { resource.PublicHost = &s },
+					func(s string) { resource.Name = &s },
+					func(s string) { resource.InternalHost = &s },
+					func(s []string) {
+						resource.PortMappings = []*models.AccessResourcePortMapping{}
+						for _, mapping := range s {
+							resource.PortMappings = append(resource.PortMappings, colonMappingToPortMapping(mapping))
+						}
+					},
+					func(s string) {
+						id := strfmt.UUID(s)
+						resource.AccessProxyID = &id
+					},
+					func(s []int64) { resource.AccessPolicyIds = s },
+					func(s []string) { resource.WildcardExceptions = s },
+					func(s string) { resource.Notes = &s },
+					func(s string) {
+						resource.FixedLastOctet = &serial.NullableOptionalInt{}
+						resource.FixedLastOctet.AssignFromString(s)
+					})
+				if err != nil {
+					return nil, err
+				}
+				if resource.PublicHost == nil || *resource.PublicHost == "" {
+					return nil, fmt.Errorf("missing resource public host")
+				}
+
+				existing, err := findResourceByPublicHost(cmd, *resource.PublicHost)
+				if err != nil {
+					return nil, err
+				}
+
+				if existing == nil {
+					missing := []string{}
+					if resource.Name == nil || *resource.Name == "" {
+						missing = append(missing, "name")
+					}
+					if resource.InternalHost == nil || *resource.InternalHost == "" {
+						missing = append(missing, "resource host")
+					}
+					if len(resource.PortMappings) == 0 {
+						missing = append(missing, "port mappings")
+					}
+					if resource.AccessProxyID == nil || *resource.AccessProxyID == "" {
+						missing = append(missing, "proxy")
+					}
+					if err := applyMissingFields("resource", missing); err != nil {
+						return nil, err
+					}
+					created := &apiresources.CreateResourceParamsBodyAccessResource{
+						Name:               *resource.Name,
+						PublicHost:         *resource.PublicHost,
+						InternalHost:       *resource.InternalHost,
+						PortMappings:       resource.PortMappings,
+						AccessProxyID:      *resource.AccessProxyID,
+						WildcardExceptions: resource.WildcardExceptions,
+						FixedLastOctet:     resource.FixedLastOctet,
+						Enabled:            true,
+					}
+					if len(resource.AccessPolicyIds) > 0 {
+						created.AccessPolicyIds = resource.AccessPolicyIds
+					}
+					if resource.Notes != nil {
+						created.Notes = *resource.Notes
+					}
+					params := apiresources.NewCreateResourceParams()
+					setTenant(cmd, params)
+					params.SetResource(apiresources.CreateResourceBody{AccessResource: created})
+
+					resp, err := global.Client.AccessResources.CreateResource(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return &applyOutcome{Key: *resource.PublicHost, ID: resp.Payload.ID, Result: applyResultCreated}, nil
+				}
+
+				body := apiresources.EditResourceBody{}
+				edited := &body.AccessResource
+				edited.Name = existing.Name
+				edited.PublicHost = existing.PublicHost
+				edited.InternalHost = existing.InternalHost
+				edited.PortMappings = existing.PortMappings
+				edited.WildcardExceptions = existing.WildcardExceptions
+				edited.Notes = existing.Notes
+				edited.FixedLastOctet = existing.FixedLastOctet
+				edited.Enabled = true
+				if existing.AccessProxy != nil {
+					edited.AccessProxyID = existing.AccessProxy.ID
+				}
+				existingPolicyIDs := []int64{}
+				for _, policy := range existing.AccessPolicies {
+					existingPolicyIDs = append(existingPolicyIDs, policy.ID)
+				}
+				edited.AccessPolicyIds = existingPolicyIDs
+
+				if resource.Name != nil && *resource.Name != "" {
+					edited.Name = *resource.Name
+				}
+				if resource.InternalHost != nil && *resource.InternalHost != "" {
+					edited.InternalHost = *resource.InternalHost
+				}
+				if resource.PortMappings != nil {
+					edited.PortMappings = resource.PortMappings
+				}
+				if resource.AccessProxyID != nil && *resource.AccessProxyID != "" {
+					edited.AccessProxyID = *resource.AccessProxyID
+				}
+				if resource.AccessPolicyIds != nil {
+					edited.AccessPolicyIds = resource.AccessPolicyIds
+				}
+				if resource.WildcardExceptions != nil {
+					edited.WildcardExceptions = resource.WildcardExceptions
+				}
+				if resource.Notes != nil {
+					edited.Notes = resource.Notes
+				}
+				if resource.FixedLastOctet != nil {
+					edited.FixedLastOctet = resource.FixedLastOctet
+				}
+
+				if resourceUnchanged(existing, &edited.AccessResource, edited.AccessProxyID, edited.AccessPolicyIds) {
+					return &applyOutcome{Key: *resource.PublicHost, ID: existing.ID, Result: applyResultUnchanged}, nil
+				}
+
+				params := apiresources.NewEditResourceParams()
+				setTenant(cmd, params)
+				params.SetID(existing.ID)
+				params.SetResource(body)
+
+				_, err = global.Client.AccessResources.EditResource(params, global.AuthWriter)
+				if err != nil {
+					return nil, err
+				}
+				return &applyOutcome{Key: *resource.PublicHost, ID: existing.ID, Result: applyResultUpdated}, nil
+			}, func(data interface{}) { // printSuccess func
+				applyTableWriterAppend(tw, &j, data.(*applyOutcome))
+			}, func(err error, id interface{}) { // doOnError func
+				applyTableWriterAppendError(tw, &j, err, id)
+			})
+		return printListOutputAndError(cmd, j, tw, int(total), err)
+	},
+}
+
+// resourceUnchanged returns whether editing existing with the fields of
+// edited, proxyID and policyIDs would not change the resource
+func resourceUnchanged(existing, edited *models.AccessResource, proxyID strfmt.UUID, policyIDs []int64) bool {
+	if edited.Name != existing.Name || edited.InternalHost != existing.InternalHost {
+		return false
+	}
+	if existing.AccessProxy == nil || proxyID != existing.AccessProxy.ID {
+		return false
+	}
+	existingPolicyIDs := []int64{}
+	for _, policy := range existing.AccessPolicies {
+		existingPolicyIDs = append(existingPolicyIDs, policy.ID)
+	}
+	if !sameInt64Set(policyIDs, existingPolicyIDs) {
+		return false
+	}
+	if !sameStringSet(portMappingStrings(edited.PortMappings), portMappingStrings(existing.PortMappings)) {
+		return false
+	}
+	if !sameStringSet(edited.WildcardExceptions, existing.WildcardExceptions) {
+		return false
+	}
+	if stringPointerValue(edited.Notes) != stringPointerValue(existing.Notes) {
+		return false
+	}
+	return fixedLastOctetString(edited.FixedLastOctet) == fixedLastOctetString(existing.FixedLastOctet)
+}
+
+// portMappingStrings converts port mappings to the
+// external:internal:protocol format, for comparison
+func portMappingStrings(mappings []*models.AccessResourcePortMapping) []string {
+	s := []string{}
+	for _, mapping := range mappings {
+		if mapping == nil {
+			continue
+		}
+		protocol := "tcp"
+		if mapping.Protocol != nil {
+			protocol = strings.ToLower(fmt.Sprint(mapping.Protocol))
+		}
+		s = append(s, fmt.Sprintf("%s:%s:%s",
+			strings.Join(mapping.PublicPorts, ","),
+			strings.Join(mapping.InternalPorts, ","),
+			protocol))
+	}
+	return s
+}
+
+func stringPointerValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func fixedLastOctetString(n *serial.NullableOptionalInt) string {
+	if n == nil {
+		return serial.NullableOptionalInt{}.String()
+	}
+	return n.String()
+}
+
+// findResourceByPublicHost returns the resource with the given public host
+// (ignoring case), or nil if there is none
+func findResourceByPublicHost(cmd *cobra.Command, host string) (*models.AccessResource, error) {
+	items, err := listAllResources(cmd, host)
+	if err != nil {
+		return nil, err
+	}
+	found := []*models.AccessResource{}
+	for _, item := range items {
+		if strings.EqualFold(item.PublicHost, host) {
+			found = append(found, item)
+		}
+	}
+	ids := []string{}
+	for _, resource := range found {
+		ids = append(ids, string(resource.ID))
+	}
+	if err := applySingleMatch("resource", host, ids); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return found[0], nil
+}
+
+func init() {
+	resourcesCmd.AddCommand(resourcesApplyCmd)
+
+	initOutputFlags(resourcesApplyCmd)
+	initLoopControlFlags(resourcesApplyCmd)
+	initParallelFlags(resourcesApplyCmd)
+	initTenantFlags(resourcesApplyCmd)
+	initInputFlags(resourcesApplyCmd, "resource",
+		inputField{
+			Name:            "Public host",
+			FlagName:        "public-host",
+			FlagDescription: "specify the public host of the resource, used to find an existing resource",
+			VarType:         "string",
+			Mandatory:       true,
+			DefaultValue:    "",
+			MainField:       true,
+			SchemaName:      "public_host",
+		},
+		inputField{
+			Name:            "Name",
+			FlagName:        "name",
+			FlagDescription: "specify the name for the resource (required when the resource is created)",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "name",
+		},
+		inputField{
+			Name:            "Resource host",
+			FlagName:        "resource-host",
+			FlagDescription: "specify the resource host for the resource (required when the resource is created)",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "internal_host",
+		},
+		inputField{
+			Name:            "Port mappings",
+			FlagName:        "ports",
+			FlagDescription: "specify the port mappings (external:internal:protocol) for the resource (required when the resource is created). Also accepts (external:internal), considers TCP by default.",
+			VarType:         "[]string.skipcomma",
+			Mandatory:       false,
+			DefaultValue:    []string{},
+			SchemaName:      "port_mappings",
+			SchemaVarType:   "[]portmapping",
+			Validator:       validatePortMappings,
+		},
+		inputField{
+			Name:            "Proxy",
+			FlagName:        "proxy",
+			FlagDescription: "specify the proxy ID or name for the resource (required when the resource is created)",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "access_proxy_id",
+			Reference:       "proxy",
+		},
+		inputField{
+			Name:            "Policies",
+			FlagName:        "policies",
+			FlagDescription: "specify a list of comma-separated policy IDs or names for the resource",
+			VarType:         "[]int",
+			Mandatory:       false,
+			DefaultValue:    []int{},
+			SchemaName:      "access_policy_ids",
+			Reference:       "policy",
+		},
+		inputField{
+			Name:            "Wildcard Exceptions",
+			FlagName:        "exceptions",
+			FlagDescription: "specify a list of of sub-domain wildcard exceptions that wont be proxied over (comma separated)",
+			VarType:         "[]string",
+			Mandatory:       false,
+			DefaultValue:    []string{},
+			SchemaName:      "wildcard_exceptions",
+		},
+		inputField{
+			Name:            "Notes",
+			FlagName:        "notes",
+			FlagDescription: "specify notes for the resource",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "notes",
+		},
+		inputField{
+			Name:            "Fixed Last Octet",
+			FlagName:        "fixed-last-octet",
+			FlagDescription: "forces the agent to bind the resource to a local IP in the format 192.0.2.X (null to disable)",
+			VarType:         "string", // use string to read "null" pseudo value
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "fixed_last_octet",
+			SchemaVarType:   "int.nullable",
+		})
+}
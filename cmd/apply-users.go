@@ -0,0 +1,261 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/spf13/cobra"
+
+	apiusers "github.com/barracuda-cloudgen-access/access-cli/client/users"
+)
+
+// usersApplyCmd represents the apply command
+var usersApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Create or update users",
+	Long: `Create or update users, matching existing users by email.
+Users that do not exist are created, and existing users are updated
+when their name, groups or enabled status differ from the ones provided.
+Phone numbers are sent when users are created or updated, but as they are
+not reported by the console, a different phone number alone does not
+cause an existing user to be updated.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tw, j := applyBuildTableWriter()
+		var total int64
+		err := forAllInput(cmd, args, false,
+			func(values *inputEntry) (interface{}, error) { // do func
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
+				user := &struct {
+					Email               *string `json:"email"`
+					Name                *string `json:"name"`
+					PhoneNumber         *string `json:"phone_number"`
+					GroupIds            []int64 `json:"group_ids"`
+					Enabled             *bool   `json:"enabled"`
+					SendEmailInvitation *bool   `json:"send_email_invitation"`
+					Groups              []struct {
+						ID int64 `json:"id"`
+					}
+				}{}
+				err := placeInputValues(cmd, values, user,
+					func(s string) { user.Email = &s },
+					func(s string) { user.Name = &s },
+					func(s string) { user.PhoneNumber = &s },
+					func(s []int64) { user.GroupIds = s },
+					func(s bool) { user.Enabled = &s },
+					func(s bool) { user.SendEmailInvitation = &s })
+				if err != nil {
+					return nil, err
+				}
+				if user.Email == nil || *user.Email == "" {
+					return nil, fmt.Errorf("missing user email")
+				}
+				// map group ids since GET and POST are not exactly the same (when applying from file)
+				for _, group := range user.Groups {
+					user.GroupIds = append(user.GroupIds, group.ID)
+				}
+
+				existing, err := findUserByEmail(cmd, *user.Email)
+				if err != nil {
+					return nil, err
+				}
+
+				if existing == nil {
+					if user.Name == nil || *user.Name == "" {
+						return nil, applyMissingFields("user", []string{"name"})
+					}
+					body := apiusers.CreateUserBody{User: &apiusers.CreateUserParamsBodyUser{
+						Name:     *user.Name,
+						Email:    strfmt.Email(*user.Email),
+						GroupIds: user.GroupIds,
+						Enabled:  true, // the UI on the web console enables by default
+					}}
+					if user.PhoneNumber != nil {
+						body.User.PhoneNumber = *user.PhoneNumber
+					}
+					if user.Enabled != nil {
+						body.User.Enabled = *user.Enabled
+					}
+					if user.SendEmailInvitation != nil {
+						body.User.SendEmailInvitation = *user.SendEmailInvitation
+					}
+					params := apiusers.NewCreateUserParams()
+					setTenant(cmd, params)
+					params.SetUser(body)
+
+					resp, err := global.Client.Users.CreateUser(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return &applyOutcome{Key: *user.Email, ID: resp.Payload.ID, Result: applyResultCreated}, nil
+				}
+
+				existingGroupIDs := []int64{}
+				for _, group := range existing.Groups {
+					existingGroupIDs = append(existingGroupIDs, group.ID)
+				}
+				enabled := existing.Enabled
+				edited := apiusers.EditUserParamsBodyUser{
+					Name:     existing.Name,
+					Enabled:  &enabled,
+					GroupIds: existingGroupIDs,
+				}
+				if user.Name != nil && *user.Name != "" {
+					edited.Name = *user.Name
+				}
+				if user.Enabled != nil {
+					edited.Enabled = user.Enabled
+				}
+				if user.GroupIds != nil {
+					edited.GroupIds = user.GroupIds
+				}
+				if edited.Name == existing.Name && *edited.Enabled == existing.Enabled &&
+					sameInt64Set(edited.GroupIds, existingGroupIDs) {
+					return &applyOutcome{Key: *user.Email, ID: existing.ID, Result: applyResultUnchanged}, nil
+				}
+				if user.PhoneNumber != nil {
+					edited.PhoneNumber = *user.PhoneNumber
+				}
+
+				params := apiusers.NewEditUserParams()
+				setTenant(cmd, params)
+				params.SetID(existing.ID)
+				params.SetUser(apiusers.EditUserBody{User: &edited})
+
+				_, err = global.Client.Users.EditUser(params, global.AuthWriter)
+				if err != nil {
+					return nil, err
+				}
+				return &applyOutcome{Key: *user.Email, ID: existing.ID, Result: applyResultUpdated}, nil
+			}, func(data interface{}) { // printSuccess func
+				applyTableWriterAppend(tw, &j, data.(*applyOutcome))
+			}, func(err error, id interface{}) { // doOnError func
+				applyTableWriterAppendError(tw, &j, err, id)
+			})
+		return printListOutputAndError(cmd, j, tw, int(total), err)
+	},
+}
+
+// findUserByEmail returns the user with the given email (ignoring case),
+// or nil if there is none
+func findUserByEmail(cmd *cobra.Command, email string) (*apiusers.ListUsersOKBodyItems0, error) {
+	items, err := listAllUsers(cmd, email)
+	if err != nil {
+		return nil, err
+	}
+	found := []*apiusers.ListUsersOKBodyItems0{}
+	for _, item := range items {
+		if strings.EqualFold(string(item.Email), email) {
+			found = append(found, item)
+		}
+	}
+	ids := []string{}
+	for _, user := range found {
+		ids = append(ids, fmt.Sprint(user.ID))
+	}
+	if err := applySingleMatch("user", email, ids); err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, nil
+	}
+	return found[0], nil
+}
+
+func init() {
+	usersCmd.AddCommand(usersApplyCmd)
+
+	initOutputFlags(usersApplyCmd)
+	initLoopControlFlags(usersApplyCmd)
+	initParallelFlags(usersApplyCmd)
+	initTenantFlags(usersApplyCmd)
+	initInputFlags(usersApplyCmd, "user",
+		inputField{
+			Name:            "Email",
+			FlagName:        "email",
+			FlagDescription: "specify the email of the user, used to find an existing user",
+			VarType:         "string",
+			Mandatory:       true,
+			DefaultValue:    "",
+			MainField:       true,
+			SchemaName:      "email",
+		},
+		inputField{
+			Name:            "Name",
+			FlagName:        "name",
+			FlagDescription: "specify the name for the user (required when the user is created)",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "name",
+		},
+		inputField{
+			Name:            "Phone Number",
+			FlagName:        "phone",
+			FlagDescription: "specify the phone number for the user",
+			VarType:         "string",
+			Mandatory:       false,
+			DefaultValue:    "",
+			SchemaName:      "phone_number",
+		},
+		inputField{
+			Name:            "Groups",
+			FlagName:        "groups",
+			FlagDescription: "specify the group IDs or names for the user",
+			VarType:         "[]int",
+			Mandatory:       false,
+			DefaultValue:    []int{},
+			SchemaName:      "group_ids",
+			SchemaAliases:   []string{"groups"},
+			Reference:       "group",
+		},
+		inputField{
+			Name:            "Enabled",
+			FlagName:        "enabled",
+			FlagDescription: "specify whether the user is enabled (created users are enabled by default)",
+			VarType:         "bool",
+			Mandatory:       false,
+			DefaultValue:    true,
+			SchemaName:      "enabled",
+		},
+		inputField{
+			Name:            "Send Email Invitation",
+			FlagName:        "invitation",
+			FlagDescription: "send an email invitation to users that are created",
+			VarType:         "bool",
+			Mandatory:       false,
+			DefaultValue:    false,
+			SchemaName:      "send_email_invitation",
+		})
+}
@@ -0,0 +1,142 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+const (
+	applyResultCreated   = "created"
+	applyResultUpdated   = "updated"
+	applyResultUnchanged = "unchanged"
+)
+
+// applyOutcome is the result of applying one record with an apply command
+type applyOutcome struct {
+	Key    string      `json:"key"`
+	ID     interface{} `json:"id"`
+	Result string      `json:"result"`
+}
+
+type applyJSONResult struct {
+	Key    string      `json:"key"`
+	ID     interface{} `json:"id"`
+	OK     bool        `json:"ok"`
+	Result string      `json:"result"`
+}
+
+func applyBuildTableWriter() (table.Writer, []applyJSONResult) {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Key",
+		"ID",
+		"Result",
+	})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 40},
+		{Number: 2, WidthMax: 36, Align: text.AlignRight},
+		{Number: 3, WidthMax: 60, Align: text.AlignLeft},
+	})
+	return tw, make([]applyJSONResult, 0)
+}
+
+func applyTableWriterAppend(tw table.Writer, j *[]applyJSONResult, outcome *applyOutcome) {
+	tw.AppendRow(table.Row{
+		outcome.Key,
+		outcome.ID,
+		outcome.Result,
+	})
+	*j = append(*j, applyJSONResult{
+		Key:    outcome.Key,
+		ID:     outcome.ID,
+		OK:     true,
+		Result: outcome.Result,
+	})
+}
+
+func applyTableWriterAppendError(tw table.Writer, j *[]applyJSONResult, err error, key interface{}) {
+	k := ""
+	if key != nil {
+		k = fmt.Sprint(key)
+	}
+	tw.AppendRow(table.Row{
+		k,
+		"",
+		processErrorResponse(err),
+	})
+	*j = append(*j, applyJSONResult{
+		Key:    k,
+		OK:     false,
+		Result: processErrorResponse(err).Error(),
+	})
+}
+
+// applySingleMatch returns an error when more than one existing record
+// matches the natural key of a record being applied
+func applySingleMatch(kind, key string, ids []string) error {
+	if len(ids) > 1 {
+		return fmt.Errorf("%q matches more than one existing %s (IDs %s)", key, kind, strings.Join(ids, ", "))
+	}
+	return nil
+}
+
+// applyMissingFields returns an error listing the fields that are required
+// to create a record, but were not provided
+func applyMissingFields(kind string, missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s does not exist and can not be created without %s", kind, strings.Join(missing, ", "))
+}
+
+// sameInt64Set returns whether a and b contain the same values, in any order
+func sameInt64Set(a, b []int64) bool {
+	as := make([]string, len(a))
+	for i, v := range a {
+		as[i] = fmt.Sprint(v)
+	}
+	bs := make([]string, len(b))
+	for i, v := range b {
+		bs[i] = fmt.Sprint(v)
+	}
+	return sameStringSet(as, bs)
+}
+
+// sameStringSet returns whether a and b contain the same values, in any order
+func sameStringSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]string{}, a...)
+	bs := append([]string{}, b...)
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestApplyGroups(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer groupsApplyCmd.Flags().Set("from-file", "")
+
+	inputFile := filepath.Join(dir, "groups.ndjson")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(
+		`{"name": "Engineering", "description": "Engineers"}
+{"name": "ops", "description": "Operations"}
+{"name": "Sales", "description": "Sales team", "color": "#ff0000"}
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "Engineering").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Post("/groups").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 10, "name": "Engineering", "description": "Engineers"})
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "ops").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 2, "name": "Ops", "description": "Old description"},
+			{"id": 3, "name": "DevOps", "description": "Other group"},
+		})
+	gock.New(baseURIinTests()).
+		Patch("/groups/2").
+		Reply(200).
+		JSON(map[string]interface{}{"id": 2, "name": "Ops", "description": "Operations"})
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "Sales").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{
+			{"id": 4, "name": "Sales", "description": "Sales team", "color": "#FF0000"},
+		})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"groups",
+		"apply",
+		"-o=json",
+		"--continue-on-error=false",
+		"--from-file=" + inputFile,
+		"--file-format=ndjson",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	results := []applyJSONResult{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &results), nil)
+	st.Assert(t, len(results), 3)
+	st.Expect(t, results[0].Result, applyResultCreated)
+	st.Expect(t, results[1].Result, applyResultUpdated)
+	st.Expect(t, results[1].Key, "ops")
+	st.Expect(t, results[2].Result, applyResultUnchanged)
+}
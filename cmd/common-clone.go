@@ -0,0 +1,352 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	apiagentconfiguration "github.com/barracuda-cloudgen-access/access-cli/client/settings_agent_configuration"
+	apianalytics "github.com/barracuda-cloudgen-access/access-cli/client/settings_analytics"
+	apienrollment "github.com/barracuda-cloudgen-access/access-cli/client/settings_enrollment"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// cloneKinds lists the kinds of objects that can be cloned between tenants
+var cloneKinds = []string{"groups", "proxies", "resources", "policies", "webpolicies", "settings"}
+
+// cloneDependencies lists, for each kind, the kinds of the objects it references
+var cloneDependencies = map[string][]string{
+	"resources":   {"proxies"},
+	"policies":    {"groups", "resources"},
+	"webpolicies": {"groups"},
+}
+
+// cloneRecordKinds maps the kinds of objects to the record kinds used to
+// look up existing objects
+var cloneRecordKinds = map[string]string{
+	"groups":      "group",
+	"proxies":     "proxy",
+	"resources":   "resource",
+	"policies":    "policy",
+	"webpolicies": "webpolicy",
+}
+
+// cloneIncludedKinds returns the kinds in include, along with the kinds
+// they depend on
+func cloneIncludedKinds(include []string) (map[string]bool, error) {
+	kinds := make(map[string]bool)
+	var add func(kind string) error
+	add = func(kind string) error {
+		known := false
+		for _, k := range cloneKinds {
+			known = known || k == kind
+		}
+		if !known {
+			return fmt.Errorf("unknown kind %s, expected one of %s", kind, strings.Join(cloneKinds, ", "))
+		}
+		kinds[kind] = true
+		for _, dep := range cloneDependencies[kind] {
+			if err := add(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, kind := range include {
+		if err := add(kind); err != nil {
+			return nil, err
+		}
+	}
+	return kinds, nil
+}
+
+// resolveTenant returns the ID of the tenant with the given ID or name
+func resolveTenant(cmd *cobra.Command, value string) (string, error) {
+	tenants, err := listAllTenants(cmd)
+	if err != nil {
+		return "", processErrorResponse(err)
+	}
+	ids := []string{}
+	for _, tenant := range tenants {
+		if strings.EqualFold(string(tenant.ID), value) {
+			return string(tenant.ID), nil
+		}
+		if strings.EqualFold(tenant.Name, value) {
+			ids = append(ids, string(tenant.ID))
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("no tenant with ID or name %q", value)
+	case 1:
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("tenant name %q is ambiguous, it matches IDs %s", value, strings.Join(ids, ", "))
+}
+
+// cloneFetch returns the objects of kind in the current tenant, as JSON
+func cloneFetch(cmd *cobra.Command, kind string) ([]byte, error) {
+	for _, k := range exportKinds {
+		if k.name == kind {
+			objects, err := k.fetch(cmd)
+			if err != nil {
+				return nil, processErrorResponse(err)
+			}
+			return json.Marshal(objects)
+		}
+	}
+	panic("cloneFetch called for unknown kind " + kind + ". This is a bug!")
+}
+
+// cloneTenant creates the objects of the included kinds of tenant from in
+// tenant to, remapping references between them. Objects that exist in
+// tenant to with the same name are not created, and are referenced instead
+func cloneTenant(cmd *cobra.Command, from, to string, include []string, report func(importJSONResult)) error {
+	kinds, err := cloneIncludedKinds(include)
+	if err != nil {
+		return err
+	}
+	s := &importState{
+		cmd:     cmd,
+		mapping: make(importMapping),
+		// users are not cloned, and those that do not exist in the target
+		// tenant (by email) are left out of policies and web policies
+		optional: map[string]bool{"users": true},
+	}
+	s.existing = func(kind string, record importRecord) (interface{}, bool, error) {
+		var found interface{}
+		err := withTenant(cmd, to, func() error {
+			items, err := existingRecordLookups[cloneRecordKinds[kind]](cmd, record.name)
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				object, err := existingRecordObject(item)
+				if err != nil {
+					return err
+				}
+				if kind == "webpolicies" {
+					object = cloneWebPolicyObject(object)
+				}
+				if object != nil && strings.EqualFold(fmt.Sprint(object["name"]), record.name) {
+					found = object["id"]
+					if n, ok := found.(json.Number); ok {
+						if i, err := n.Int64(); err == nil {
+							found = i
+						}
+					}
+					return nil
+				}
+			}
+			return nil
+		})
+		return found, found != nil, err
+	}
+
+	if kinds["policies"] || kinds["webpolicies"] {
+		if err := cloneMapUsers(cmd, from, to, s.mapping); err != nil {
+			return err
+		}
+	}
+
+	for _, kind := range importKinds {
+		if !kinds[kind.name] {
+			continue
+		}
+		var data []byte
+		err := withTenant(cmd, from, func() (err error) {
+			data, err = cloneFetch(cmd, kind.name)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("fetching %s: %v", kind.name, err)
+		}
+		records, err := kind.records(data)
+		if err != nil {
+			return err
+		}
+		ok := true
+		withTenant(cmd, to, func() error {
+			ok = s.importRecords(kind.name, records, report)
+			return nil
+		})
+		if !ok {
+			return s.loopErr
+		}
+	}
+	ok := true
+	withTenant(cmd, to, func() error {
+		ok = s.linkPolicyResources(report)
+		return nil
+	})
+	if !ok {
+		return s.loopErr
+	}
+
+	if kinds["settings"] {
+		if !cloneSettings(s, from, to, report) {
+			return s.loopErr
+		}
+	}
+	return s.result()
+}
+
+// cloneWebPolicyObject returns the rule of the ruleset of a jump rule of
+// the main web policy ruleset, with its label as the name, or nil if object
+// is not a jump rule
+func cloneWebPolicyObject(object map[string]interface{}) map[string]interface{} {
+	jump, _ := object["ruleset_jump"].(map[string]interface{})
+	rules, _ := jump["rules"].([]interface{})
+	if len(rules) < 1 {
+		return nil
+	}
+	rule, _ := rules[0].(map[string]interface{})
+	if rule == nil {
+		return nil
+	}
+	return map[string]interface{}{"id": rule["id"], "name": rule["label"]}
+}
+
+// cloneMapUsers maps the users of tenant from to the users of tenant to
+// with the same email
+func cloneMapUsers(cmd *cobra.Command, from, to string, mapping importMapping) error {
+	emails := make(map[string]interface{})
+	err := withTenant(cmd, to, func() error {
+		users, err := existingRecordLookups["user"](cmd, "")
+		if err != nil {
+			return processErrorResponse(err)
+		}
+		for _, user := range users {
+			object, err := existingRecordObject(user)
+			if err != nil {
+				return err
+			}
+			emails[strings.ToLower(fmt.Sprint(object["email"]))] = object["id"]
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	mapping["users"] = make(map[string]interface{})
+	return withTenant(cmd, from, func() error {
+		users, err := existingRecordLookups["user"](cmd, "")
+		if err != nil {
+			return processErrorResponse(err)
+		}
+		for _, user := range users {
+			object, err := existingRecordObject(user)
+			if err != nil {
+				return err
+			}
+			if id, ok := emails[strings.ToLower(fmt.Sprint(object["email"]))]; ok {
+				mapping["users"][fmt.Sprint(object["id"])] = id
+			}
+		}
+		return nil
+	})
+}
+
+// cloneSettings copies the agent configuration, analytics and enrollment
+// settings of tenant from to tenant to
+func cloneSettings(s *importState, from, to string, report func(importJSONResult)) bool {
+	cmd := s.cmd
+	var agent *models.SettingsAgentConfiguration
+	var analytics *models.SettingsAnalytics
+	var enrollment *models.SettingsEnrollment
+	// when the settings can not be fetched, none of them is sent
+	fetchErr := withTenant(cmd, from, func() error {
+		agentParams := apiagentconfiguration.NewSettingsAgentConfigurationParams()
+		setTenant(cmd, agentParams)
+		agentResp, err := global.Client.SettingsAgentConfiguration.SettingsAgentConfiguration(agentParams, global.AuthWriter)
+		if err != nil {
+			return err
+		}
+		agent = agentResp.Payload
+
+		analyticsParams := apianalytics.NewSettingsAnalyticsParams()
+		setTenant(cmd, analyticsParams)
+		analyticsResp, err := global.Client.SettingsAnalytics.SettingsAnalytics(analyticsParams, global.AuthWriter)
+		if err != nil {
+			return err
+		}
+		analytics = analyticsResp.Payload
+
+		enrollmentParams := apienrollment.NewSettingsEnrollmentParams()
+		setTenant(cmd, enrollmentParams)
+		enrollmentResp, err := global.Client.SettingsEnrollment.SettingsEnrollment(enrollmentParams, global.AuthWriter)
+		if err != nil {
+			return err
+		}
+		enrollment = enrollmentResp.Payload
+		return nil
+	})
+
+	set := []struct {
+		name string
+		do   func() error
+	}{
+		{"agent_configuration", func() error {
+			params := apiagentconfiguration.NewEditSettingsAgentConfigurationParams()
+			setTenant(cmd, params)
+			params.SetAppConfiguration(apiagentconfiguration.EditSettingsAgentConfigurationBody{AppConfiguration: agent})
+			_, err := global.Client.SettingsAgentConfiguration.EditSettingsAgentConfiguration(params, global.AuthWriter)
+			return err
+		}},
+		{"analytics", func() error {
+			params := apianalytics.NewEditSettingsAnalyticsParams()
+			setTenant(cmd, params)
+			params.SetAnalyticsSettings(apianalytics.EditSettingsAnalyticsBody{AnalyticsSettings: analytics})
+			_, err := global.Client.SettingsAnalytics.EditSettingsAnalytics(params, global.AuthWriter)
+			return err
+		}},
+		{"enrollment", func() error {
+			params := apienrollment.NewEditSettingsEnrollmentParams()
+			setTenant(cmd, params)
+			params.SetEnrollmentSettings(apienrollment.EditSettingsEnrollmentBody{EnrollmentSettings: enrollment})
+			_, err := global.Client.SettingsEnrollment.EditSettingsEnrollment(params, global.AuthWriter)
+			return err
+		}},
+	}
+	for _, setting := range set {
+		result := importJSONResult{Kind: "settings", Name: setting.name, OK: true, Result: "updated"}
+		err := fetchErr
+		if err == nil {
+			err = withTenant(cmd, to, setting.do)
+			if isDryRunError(err) {
+				err = nil
+			}
+		}
+		if err != nil {
+			result.OK = false
+			result.Result = processErrorResponse(err).Error()
+			if s.loopErr == nil {
+				s.loopErr = err
+			}
+		}
+		report(result)
+		if err != nil && !loopControlContinueOnError(cmd) {
+			return false
+		}
+	}
+	return true
+}
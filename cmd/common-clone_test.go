@@ -0,0 +1,236 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"github.com/spf13/pflag"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func resetTenantsCloneIncludeFlag() {
+	// slice flags append to their value once set
+	f := tenantsCloneCmd.Flags().Lookup("include")
+	f.Value.(pflag.SliceValue).Replace([]string{})
+	f.Changed = false
+}
+
+func TestCloneTenant(t *testing.T) {
+	defer gock.Off()
+	defer resetTenantsCloneIncludeFlag()
+
+	const from = "7b1a6c3e-0f0a-4e6b-9d1f-5a2f3c4d5e6f"
+	const to = "0c9d8e7f-6a5b-4c3d-8e1f-0a1b2c3d4e5f"
+
+	gock.New(baseURIinTests()).
+		Get("/tenants").
+		Times(2).
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{{"id": from, "name": "Template"}, {"id": to, "name": "Customer A"}})
+	gock.New(baseURIinTests()).
+		Get("/tenants/"+from+"/groups").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{{"id": 9, "name": "Engineering"}, {"id": 10, "name": "Sales"}})
+	// Engineering does not exist in the target tenant
+	gock.New(baseURIinTests()).
+		Get("/tenants/"+to+"/groups").
+		MatchParam("q", "Engineering").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Post("/tenants/" + to + "/groups").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 20, "name": "Engineering"})
+	// Sales does
+	gock.New(baseURIinTests()).
+		Get("/tenants/"+to+"/groups").
+		MatchParam("q", "Sales").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 21, "name": "sales"}})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"tenants",
+		"clone",
+		"-o=json",
+		"--continue-on-error=false",
+		"--from", "template",
+		"--to", to,
+		"--include", "groups",
+	})
+	err := cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	results := []multiOpJSONResult{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &results), nil)
+	st.Assert(t, len(results), 2)
+	st.Expect(t, results[0].ID, float64(20))
+	st.Expect(t, results[0].Result, "group Engineering: "+importResultCreated)
+	st.Expect(t, results[1].ID, float64(21))
+	st.Expect(t, results[1].Result, "group Sales: skipped (exists, id=21)")
+}
+
+func TestCloneTenantPolicyExistingResource(t *testing.T) {
+	defer gock.Off()
+	defer resetTenantsCloneIncludeFlag()
+
+	const from = "7b1a6c3e-0f0a-4e6b-9d1f-5a2f3c4d5e6f"
+	const to = "0c9d8e7f-6a5b-4c3d-8e1f-0a1b2c3d4e5f"
+	const oldResource = "5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"
+	const newResource = "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d"
+
+	gock.New(baseURIinTests()).
+		Get("/tenants").
+		Times(2).
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{{"id": from, "name": "Template"}, {"id": to, "name": "Customer A"}})
+	for _, path := range []string{"/tenants/" + to + "/users", "/tenants/" + from + "/users",
+		"/tenants/" + from + "/groups", "/tenants/" + from + "/access_proxies"} {
+		gock.New(baseURIinTests()).
+			Get(path).
+			Reply(200).
+			SetHeader("total", "0").
+			JSON([]map[string]interface{}{})
+	}
+	// the policy is new
+	gock.New(baseURIinTests()).
+		Get("/tenants/"+from+"/access_policies").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 3, "name": "Admins"}})
+	gock.New(baseURIinTests()).
+		Get("/tenants/"+to+"/access_policies").
+		MatchParam("q", "Admins").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Post("/tenants/" + to + "/access_policies").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 30, "name": "Admins"})
+	// the resource it is linked to already exists
+	gock.New(baseURIinTests()).
+		Get("/tenants/"+from+"/access_resources").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": oldResource, "name": "Wiki", "access_policies": []interface{}{
+			map[string]interface{}{"id": 3, "name": "Admins"},
+		}}})
+	gock.New(baseURIinTests()).
+		Get("/tenants/"+to+"/access_resources").
+		MatchParam("q", "Wiki").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": newResource, "name": "wiki"}})
+	gock.New(baseURIinTests()).
+		Patch("/tenants/" + to + "/access_policies/30").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			return strings.Contains(string(body), `"access_resource_ids":["`+newResource+`"]`), err
+		}).
+		Reply(200).
+		JSON(map[string]interface{}{"id": 30, "name": "Admins"})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"tenants",
+		"clone",
+		"-o=json",
+		"--continue-on-error=false",
+		"--from", from,
+		"--to", to,
+		"--include", "policies",
+	})
+	err := cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	results := []multiOpJSONResult{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &results), nil)
+	st.Assert(t, len(results), 2)
+	st.Expect(t, results[0].Result, "policy Admins: "+importResultCreated)
+	st.Expect(t, results[1].Result, "resource Wiki: skipped (exists, id="+newResource+")")
+}
+
+func TestCloneTenantSettingsFetchError(t *testing.T) {
+	defer gock.Off()
+	defer resetTenantsCloneIncludeFlag()
+
+	const from = "7b1a6c3e-0f0a-4e6b-9d1f-5a2f3c4d5e6f"
+	const to = "0c9d8e7f-6a5b-4c3d-8e1f-0a1b2c3d4e5f"
+
+	gock.New(baseURIinTests()).
+		Get("/tenants").
+		Times(2).
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{{"id": from, "name": "Template"}, {"id": to, "name": "Customer A"}})
+	gock.New(baseURIinTests()).
+		Get("/tenants/" + from + "/app_configuration").
+		Reply(500).
+		JSON(map[string]interface{}{"error": "internal error"})
+	// no setting must be sent
+	gock.New(baseURIinTests()).
+		Put("/tenants/" + to + "/").
+		Reply(200).
+		JSON(map[string]interface{}{})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"tenants",
+		"clone",
+		"-o=json",
+		"--continue-on-error=true",
+		"--from", from,
+		"--to", to,
+		"--include", "settings",
+	})
+	err := cmd.Execute()
+	// with --continue-on-error, failures are only reported
+	st.Expect(t, err, nil)
+	st.Expect(t, len(gock.Pending()), 1)
+
+	results := []multiOpJSONResult{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &results), nil)
+	st.Assert(t, len(results), 3)
+	for _, r := range results {
+		st.Expect(t, r.OK, false)
+	}
+}
@@ -0,0 +1,259 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// inputColumnMap rewrites the columns of CSV input files produced by other
+// tools (such as directory exports) into the columns access-cli expects
+type inputColumnMap struct {
+	// Columns maps each output column to how its value is obtained
+	Columns map[string]inputColumnRule `yaml:"columns"`
+	// Drop lists source columns that are not passed through
+	Drop []string `yaml:"drop"`
+	// DropUnmapped causes source columns not used by any rule to be dropped,
+	// instead of being passed through unchanged
+	DropUnmapped bool `yaml:"drop_unmapped"`
+}
+
+type inputColumnRule struct {
+	// From is the source column with the value
+	From string `yaml:"from"`
+	// Combine lists source columns whose non-empty values are joined with Separator
+	Combine   []string `yaml:"combine"`
+	Separator *string  `yaml:"separator"`
+	// Coalesce lists source columns, the first non-empty one provides the value
+	Coalesce []string `yaml:"coalesce"`
+	// Default is used when the value would otherwise be empty
+	Default string `yaml:"default"`
+	// Split separates the value into a list, which is joined with commas
+	Split string `yaml:"split"`
+	// Transform is one of lower, upper or trim
+	Transform string `yaml:"transform"`
+}
+
+// inputColumnMapPresets are the column maps that can be referenced by name
+// in --column-map, for the exports of common directories
+var inputColumnMapPresets = map[string]*inputColumnMap{
+	"entra-id": {
+		Columns: map[string]inputColumnRule{
+			"name":         {Coalesce: []string{"Display name"}},
+			"email":        {Coalesce: []string{"Mail", "User principal name"}, Transform: "lower"},
+			"phone_number": {Coalesce: []string{"Mobile phone", "Telephone number"}},
+		},
+		DropUnmapped: true,
+	},
+	"google-workspace": {
+		Columns: map[string]inputColumnRule{
+			"name":         {Combine: []string{"First Name", "Last Name"}},
+			"email":        {Coalesce: []string{"Email Address", "Primary Email"}, Transform: "lower"},
+			"phone_number": {Coalesce: []string{"Mobile Phone", "Work Phone"}},
+		},
+		DropUnmapped: true,
+	},
+	"bamboohr": {
+		Columns: map[string]inputColumnRule{
+			"name":         {Combine: []string{"First Name", "Last Name"}},
+			"email":        {From: "Work Email", Transform: "lower"},
+			"phone_number": {Coalesce: []string{"Mobile Phone", "Work Phone"}},
+		},
+		DropUnmapped: true,
+	},
+}
+
+// loadInputColumnMap returns the preset named value, or otherwise reads the
+// column map from the YAML file at path value
+func loadInputColumnMap(value string) (*inputColumnMap, error) {
+	if m, ok := inputColumnMapPresets[value]; ok {
+		return m, nil
+	}
+	data, err := ioutil.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("column map %s is neither a preset (%s) nor a readable file: %w",
+			value, strings.Join(inputColumnMapPresetNames(), ", "), err)
+	}
+	m := &inputColumnMap{}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid column map %s: %w", value, err)
+	}
+	for column, rule := range m.Columns {
+		sources := 0
+		if rule.From != "" {
+			sources++
+		}
+		if len(rule.Combine) > 0 {
+			sources++
+		}
+		if len(rule.Coalesce) > 0 {
+			sources++
+		}
+		if sources > 1 {
+			return nil, fmt.Errorf("invalid column map %s: column %s can only have one of from, combine or coalesce", value, column)
+		}
+		switch rule.Transform {
+		case "", "lower", "upper", "trim":
+		default:
+			return nil, fmt.Errorf("invalid column map %s: column %s has unknown transform %s", value, column, rule.Transform)
+		}
+	}
+	return m, nil
+}
+
+func inputColumnMapPresetNames() []string {
+	names := []string{}
+	for name := range inputColumnMapPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var inputColumnSuffixRegexp = regexp.MustCompile(`\s*[\[(][^\])]*[\])]\s*$`)
+
+// inputColumnKey normalizes a column name for matching, so that case, spaces,
+// underscores and suffixes such as " [Required]" do not matter
+func inputColumnKey(name string) string {
+	name = inputColumnSuffixRegexp.ReplaceAllString(strings.TrimSpace(name), "")
+	name = strings.ToLower(name)
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(name)
+}
+
+// apply returns the header and record resulting from mapping the columns of
+// record, with the given header. Mapped columns come first, sorted by name,
+// followed by the source columns that are passed through
+func (m *inputColumnMap) apply(header, record []string) ([]string, []string) {
+	index := make(map[string]int)
+	for i, name := range header {
+		key := inputColumnKey(name)
+		if _, dup := index[key]; !dup {
+			index[key] = i
+		}
+	}
+	value := func(name string) string {
+		i, ok := index[inputColumnKey(name)]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	columns := make([]string, 0, len(m.Columns))
+	// source columns read by any rule are not passed through, even when
+	// empty, so that every record has the same columns
+	skip := make(map[string]bool)
+	for column, rule := range m.Columns {
+		columns = append(columns, column)
+		skip[inputColumnKey(column)] = true
+		for _, source := range append(append([]string{rule.From}, rule.Combine...), rule.Coalesce...) {
+			skip[inputColumnKey(source)] = true
+		}
+	}
+	sort.Strings(columns)
+
+	outHeader := []string{}
+	outRecord := []string{}
+	for _, column := range columns {
+		rule := m.Columns[column]
+		var v string
+		switch {
+		case rule.From != "":
+			v = value(rule.From)
+		case len(rule.Combine) > 0:
+			separator := " "
+			if rule.Separator != nil {
+				separator = *rule.Separator
+			}
+			parts := []string{}
+			for _, source := range rule.Combine {
+				if part := value(source); part != "" {
+					parts = append(parts, part)
+				}
+			}
+			v = strings.Join(parts, separator)
+		case len(rule.Coalesce) > 0:
+			for _, source := range rule.Coalesce {
+				if v = value(source); v != "" {
+					break
+				}
+			}
+		default:
+			// with no source, the column with the same name is used
+			v = value(column)
+		}
+		if v == "" {
+			v = rule.Default
+		}
+		switch rule.Transform {
+		case "lower":
+			v = strings.ToLower(v)
+		case "upper":
+			v = strings.ToUpper(v)
+		case "trim":
+			v = strings.TrimSpace(v)
+		}
+		if rule.Split != "" && v != "" {
+			items := []string{}
+			for _, item := range strings.Split(v, rule.Split) {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			v = strings.Join(items, ",")
+		}
+		outHeader = append(outHeader, column)
+		outRecord = append(outRecord, v)
+	}
+
+	if m.DropUnmapped {
+		return outHeader, outRecord
+	}
+	for _, name := range m.Drop {
+		skip[inputColumnKey(name)] = true
+	}
+	for i, name := range header {
+		if skip[inputColumnKey(name)] {
+			continue
+		}
+		v := ""
+		if i < len(record) {
+			v = record[i]
+		}
+		outHeader = append(outHeader, name)
+		outRecord = append(outRecord, v)
+	}
+	return outHeader, outRecord
+}
+
+// inputColumnMapFromFlag returns the column map passed in --column-map, or
+// nil if the flag was not set
+func inputColumnMapFromFlag(cmd *cobra.Command) (*inputColumnMap, error) {
+	value, err := cmd.Flags().GetString("column-map")
+	if err != nil || value == "" {
+		return nil, err
+	}
+	return loadInputColumnMap(value)
+}
@@ -0,0 +1,181 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestColumnMapCSVInputSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	mapFile := filepath.Join(dir, "map.yaml")
+	st.Assert(t, ioutil.WriteFile(mapFile, []byte(`
+columns:
+  name:
+    combine: [First, Last]
+  email:
+    coalesce: [Work Email, Personal Email]
+    transform: lower
+  group_ids:
+    from: Teams
+    split: ";"
+  enabled:
+    default: "true"
+drop: [Notes]
+`), 0600), nil)
+	columnMap, err := loadInputColumnMap(mapFile)
+	st.Assert(t, err, nil)
+
+	input := `First,Last,Work Email [Required],Personal Email,Teams,Notes,Extra
+Alice,Smith,Alice@Example.com,,Eng; Ops,a note,x
+Bob,,,bob@example.com,,,y
+`
+	source, err := newCSVInputSource(strings.NewReader(input), columnMap)
+	st.Assert(t, err, nil)
+	records := []map[string]interface{}{}
+	for {
+		entry, err := source.next()
+		if err == io.EOF {
+			break
+		}
+		st.Assert(t, err, nil)
+		st.Expect(t, entry.Type, wholeCSVObject)
+		records = append(records, entry.CSVdata.(map[string]interface{}))
+	}
+	st.Assert(t, len(records), 2)
+	st.Expect(t, records[0], map[string]interface{}{
+		"email":     "alice@example.com",
+		"enabled":   "true",
+		"group_ids": "Eng,Ops",
+		"name":      "Alice Smith",
+		"Extra":     "x",
+	})
+	st.Expect(t, records[1]["name"], "Bob")
+	st.Expect(t, records[1]["email"], "bob@example.com")
+	st.Expect(t, records[1]["group_ids"], "")
+
+	_, err = loadInputColumnMap("no-such-preset")
+	st.Reject(t, err, nil)
+	preset, err := loadInputColumnMap("entra-id")
+	st.Assert(t, err, nil)
+	header, record := preset.apply(
+		[]string{"User principal name", "Display name", "Mail", "Department"},
+		[]string{"alice@corp.onmicrosoft.com", "Alice Smith", "", "Eng"})
+	st.Expect(t, header, []string{"email", "name", "phone_number"})
+	st.Expect(t, record, []string{"alice@corp.onmicrosoft.com", "Alice Smith", ""})
+}
+
+func TestAddUsersColumnMapCSV(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer func() {
+		usersAddCmd.Flags().Set("from-file", "")
+		usersAddCmd.Flags().Set("file-format", "json")
+		usersAddCmd.Flags().Set("column-map", "")
+	}()
+
+	mapFile := filepath.Join(dir, "map.yaml")
+	st.Assert(t, ioutil.WriteFile(mapFile, []byte(`
+columns:
+  name:
+    combine: [First, Last]
+  email:
+    from: Mail
+    transform: lower
+  group_ids:
+    from: Teams
+    split: ";"
+drop_unmapped: true
+`), 0600), nil)
+	inputFile := filepath.Join(dir, "users.csv")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(`First,Last,Mail,Teams,Notes
+Alice,Smith,Alice@Example.com,Eng; 5,a note
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "Eng").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 7, "name": "Eng"}})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			return strings.Contains(string(body), `"email":"alice@example.com"`) &&
+				strings.Contains(string(body), `"group_ids":[7,5]`) &&
+				strings.Contains(string(body), `"name":"Alice Smith"`), err
+		}).
+		Reply(201).
+		JSON(map[string]interface{}{"id": 101, "name": "Alice Smith", "email": "alice@example.com"})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"users",
+		"add",
+		"-o=csv",
+		"--continue-on-error=false",
+		"--from-file=" + inputFile,
+		"--file-format=csv",
+		"--column-map=" + mapFile,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+}
+
+func TestAddUsersColumnMapRequiresCSV(t *testing.T) {
+	defer func() {
+		usersAddCmd.Flags().Set("from-file", "")
+		usersAddCmd.Flags().Set("column-map", "")
+	}()
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"users",
+		"add",
+		"--from-file=users.json",
+		"--file-format=json",
+		"--column-map=bamboohr",
+	})
+	err := cmd.Execute()
+	st.Reject(t, err, nil)
+	st.Expect(t, strings.Contains(err.Error(), "--column-map can only be used with --file-format=csv"), true)
+}
@@ -0,0 +1,536 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+)
+
+// diffKind is a kind of object compared by the diff command
+type diffKind struct {
+	name string // also the name of the export kind
+	key  string // field identifying objects across tenants
+}
+
+// diffKinds lists the kinds of objects compared by the diff command.
+// Devices, assets and asset sources are inventory rather than configuration
+var diffKinds = []diffKind{
+	{"users", "email"},
+	{"admins", "email"},
+	{"groups", "name"},
+	{"proxies", "name"},
+	{"resources", "name"},
+	{"policies", "name"},
+	{"webpolicies", "label"},
+	{"settings", ""},
+}
+
+// diffIgnoredFields are the fields removed before comparing objects: their
+// own ID, the IDs of the tenant and of the records holding them, and
+// timestamps
+var diffIgnoredFields = []string{"id", "created_at", "updated_at", "tenant_id", "account_id", "ruleset_id", "rule_id", "jumprule_id"}
+
+// diffReferenceFields maps the fields holding the IDs of other objects to
+// the kind of these objects
+var diffReferenceFields = map[string]string{
+	"group_ids":           "groups",
+	"user_ids":            "users",
+	"access_proxy_id":     "proxies",
+	"access_resource_ids": "resources",
+	"access_policy_ids":   "policies",
+}
+
+// diff actions
+const (
+	diffAdded   = "added"
+	diffRemoved = "removed"
+	diffChanged = "changed"
+)
+
+// diffField is a field that differs between two versions of an object. Old
+// or New is nil when the field is missing from that version
+type diffField struct {
+	Field string  `json:"field"`
+	Old   *string `json:"old,omitempty"`
+	New   *string `json:"new,omitempty"`
+}
+
+// diffEntry is an object that differs between two sets of objects
+type diffEntry struct {
+	Kind   string      `json:"kind"`
+	Key    string      `json:"key"`
+	Action string      `json:"action"`
+	Fields []diffField `json:"fields"`
+}
+
+// diffSource provides the objects of each kind, as plain values
+type diffSource struct {
+	name string
+	// objects returns the objects of kind, with ok false if they are not
+	// available from the source
+	objects func(kind string) (v interface{}, ok bool, err error)
+}
+
+// diffTenantSource returns a source with the objects of a tenant
+func diffTenantSource(cmd *cobra.Command, tenant string) diffSource {
+	return diffSource{
+		name: "tenant " + tenant,
+		objects: func(kind string) (interface{}, bool, error) {
+			for _, k := range exportKinds {
+				if k.name != kind {
+					continue
+				}
+				var objects interface{}
+				err := withTenant(cmd, tenant, func() (err error) {
+					objects, err = k.fetch(cmd)
+					return err
+				})
+				if err != nil {
+					return nil, false, processErrorResponse(err)
+				}
+				plain, err := exportPlainValue(objects)
+				return plain, true, err
+			}
+			panic("diffTenantSource called for unknown kind " + kind + ". This is a bug!")
+		},
+	}
+}
+
+// diffDirSource returns a source with the objects saved to a directory, by
+// the export command or list commands with -o json
+func diffDirSource(dir string) diffSource {
+	return diffSource{
+		name: dir,
+		objects: func(kind string) (interface{}, bool, error) {
+			data, err := readExportFile(dir, kind)
+			if err != nil || data == nil {
+				return nil, false, err
+			}
+			var plain interface{}
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			if err := dec.Decode(&plain); err != nil {
+				return nil, false, fmt.Errorf("%s: %v", kind, err)
+			}
+			return exportConvertNumbers(plain), true, nil
+		},
+	}
+}
+
+// diffLoadedSource holds the objects fetched from a source, so that the
+// objects of each kind are only fetched once, whether they are compared or
+// referenced by other objects
+type diffLoadedSource struct {
+	source  diffSource
+	objects map[string]interface{}
+	missing map[string]bool
+	names   map[string]map[string]string // by kind, then ID
+	err     error                        // first error fetching referenced objects
+}
+
+func newDiffLoadedSource(source diffSource) *diffLoadedSource {
+	return &diffLoadedSource{
+		source:  source,
+		objects: make(map[string]interface{}),
+		missing: make(map[string]bool),
+		names:   make(map[string]map[string]string),
+	}
+}
+
+// get returns the objects of kind, with ok false if they are not available
+// from the source
+func (s *diffLoadedSource) get(kind string) (interface{}, bool, error) {
+	if v, ok := s.objects[kind]; ok {
+		return v, true, nil
+	}
+	if s.missing[kind] {
+		return nil, false, nil
+	}
+	v, ok, err := s.source.objects(kind)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		s.missing[kind] = true
+		return nil, false, nil
+	}
+	s.objects[kind] = v
+	return v, true, nil
+}
+
+// name returns the email, name or label of the object of kind with the
+// given ID, or the ID itself if the object is unknown
+func (s *diffLoadedSource) name(kind string, id interface{}) interface{} {
+	names, ok := s.names[kind]
+	if !ok {
+		names = make(map[string]string)
+		s.names[kind] = names
+		v, _, err := s.get(kind)
+		if err != nil && s.err == nil {
+			s.err = err
+		}
+		key := ""
+		for _, k := range diffKinds {
+			if k.name == kind {
+				key = k.key
+			}
+		}
+		list, _ := v.([]interface{})
+		for _, item := range list {
+			if m, ok := item.(map[string]interface{}); ok && m["id"] != nil {
+				names[fmt.Sprint(m["id"])] = fmt.Sprint(m[key])
+			}
+		}
+	}
+	if name, ok := names[fmt.Sprint(id)]; ok {
+		return name
+	}
+	return id
+}
+
+// diffSources compares the objects of the given kinds (or all kinds, if
+// none are given) from the old and new sources. Kinds that are not
+// available from either source are not compared
+func diffSources(old, new diffSource, only []string) ([]diffEntry, error) {
+	oldLoaded := newDiffLoadedSource(old)
+	newLoaded := newDiffLoadedSource(new)
+	entries := []diffEntry{}
+	for _, kind := range diffKinds {
+		if len(only) > 0 && !funk.Contains(only, kind.name) {
+			continue
+		}
+		oldValue, ok, err := oldLoaded.get(kind.name)
+		if err != nil || !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		newValue, ok, err := newLoaded.get(kind.name)
+		if err != nil || !ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		oldObjects := diffKeyedObjects(kind, oldValue, oldLoaded)
+		newObjects := diffKeyedObjects(kind, newValue, newLoaded)
+		for _, loaded := range []*diffLoadedSource{oldLoaded, newLoaded} {
+			if loaded.err != nil {
+				return nil, loaded.err
+			}
+		}
+		entries = append(entries, diffObjects(kind, oldObjects, newObjects)...)
+	}
+	return entries, nil
+}
+
+// diffKeyedObjects returns the objects of kind in v, keyed by their
+// natural key, with the fields that differ between tenants removed
+func diffKeyedObjects(kind diffKind, v interface{}, refs *diffLoadedSource) map[string]map[string]interface{} {
+	objects := make(map[string]map[string]interface{})
+	add := func(key string, object interface{}) {
+		m, ok := diffNormalize(object, refs).(map[string]interface{})
+		if !ok {
+			return
+		}
+		// objects with the same key are told apart by their order
+		k := key
+		for i := 2; objects[k] != nil; i++ {
+			k = fmt.Sprintf("%s (%d)", key, i)
+		}
+		objects[k] = m
+	}
+
+	switch kind.name {
+	case "settings":
+		m, _ := v.(map[string]interface{})
+		for name, settings := range m {
+			add(name, settings)
+		}
+		return objects
+	case "webpolicies":
+		// each web policy is the single rule of the ruleset of a jump rule of
+		// the main ruleset, and the users and groups are those of the jump rule
+		m, _ := v.(map[string]interface{})
+		rules, _ := m["rules"].([]interface{})
+		list := []interface{}{}
+		for _, r := range rules {
+			jump, _ := r.(map[string]interface{})
+			ruleset, _ := jump["ruleset_jump"].(map[string]interface{})
+			inner, _ := ruleset["rules"].([]interface{})
+			if jump["type"] != "jump" || len(inner) < 1 {
+				continue
+			}
+			rule, ok := inner[0].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			rule["groups"] = jump["groups"]
+			rule["users"] = jump["users"]
+			list = append(list, rule)
+		}
+		v = list
+	}
+
+	list, _ := v.([]interface{})
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		add(strings.ToLower(fmt.Sprint(m[kind.key])), m)
+	}
+	return objects
+}
+
+// diffNormalize removes IDs and timestamps from v, and replaces references
+// to other objects, found in refs, with their email, name or label, so that
+// the objects of different tenants can be compared
+func diffNormalize(v interface{}, refs *diffLoadedSource) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{})
+		for k, item := range value {
+			if funk.ContainsString(diffIgnoredFields, k) {
+				continue
+			}
+			if kind, ok := diffReferenceFields[k]; ok {
+				item = diffReferenceNames(kind, item, refs)
+			}
+			m[k] = diffNormalize(item, refs)
+		}
+		return m
+	case []interface{}:
+		list := []interface{}{}
+		for _, item := range value {
+			if ref := diffReference(item); ref != "" {
+				list = append(list, ref)
+			} else {
+				list = append(list, diffNormalize(item, refs))
+			}
+		}
+		// the order of lists is not significant
+		sort.SliceStable(list, func(i, j int) bool {
+			return diffDisplayValue(list[i]) < diffDisplayValue(list[j])
+		})
+		return list
+	}
+	return v
+}
+
+// diffReferenceNames replaces the ID, or list of IDs, of objects of kind in
+// v with their email, name or label
+func diffReferenceNames(kind string, v interface{}, refs *diffLoadedSource) interface{} {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		list := []interface{}{}
+		for _, id := range value {
+			list = append(list, refs.name(kind, id))
+		}
+		return list
+	}
+	return refs.name(kind, v)
+}
+
+// diffReference returns the email, name or label of v, if it is a
+// reference to another object
+func diffReference(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok || m["id"] == nil {
+		return ""
+	}
+	for _, key := range []string{"email", "name", "label"} {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func diffDisplayValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// diffFlatten adds the values of object to fields, keyed by their path
+func diffFlatten(prefix string, v interface{}, fields map[string]string) {
+	if m, ok := v.(map[string]interface{}); ok {
+		if len(m) == 0 && prefix != "" {
+			fields[prefix] = "{}"
+		}
+		for k, item := range m {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			diffFlatten(path, item, fields)
+		}
+		return
+	}
+	if v == nil {
+		return
+	}
+	fields[prefix] = diffDisplayValue(v)
+}
+
+// diffObjects compares the objects of kind, keyed by their natural key
+func diffObjects(kind diffKind, old, new map[string]map[string]interface{}) []diffEntry {
+	keys := []string{}
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if old[key] == nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := []diffEntry{}
+	for _, key := range keys {
+		oldFields := make(map[string]string)
+		newFields := make(map[string]string)
+		entry := diffEntry{Kind: kind.name, Key: key, Action: diffChanged}
+		switch {
+		case old[key] == nil:
+			entry.Action = diffAdded
+		case new[key] == nil:
+			entry.Action = diffRemoved
+		}
+		diffFlatten("", old[key], oldFields)
+		diffFlatten("", new[key], newFields)
+		entry.Fields = diffFields(oldFields, newFields)
+		if len(entry.Fields) > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// diffFields returns the fields that differ between two flattened versions
+// of an object, sorted by name. A field that is empty in one version and
+// missing from the other differs
+func diffFields(old, new map[string]string) []diffField {
+	names := []string{}
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range new {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var fields []diffField
+	for _, name := range names {
+		oldValue, inOld := old[name]
+		newValue, inNew := new[name]
+		if inOld == inNew && oldValue == newValue {
+			continue
+		}
+		field := diffField{Field: name}
+		if inOld {
+			field.Old = &oldValue
+		}
+		if inNew {
+			field.New = &newValue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// diffFieldDisplay returns a field value for display. Empty values are
+// shown as "", so that they can be told apart from missing ones
+func diffFieldDisplay(value *string) string {
+	switch {
+	case value == nil:
+		return ""
+	case *value == "":
+		return `""`
+	}
+	return *value
+}
+
+// renderDiffUnified renders entries as a unified diff, with old lines
+// prefixed by - and new lines by +
+func renderDiffUnified(entries []diffEntry, oldName, newName string, color bool) string {
+	paint := func(colors text.Colors, s string) string {
+		if color {
+			return colors.Sprint(s)
+		}
+		return s
+	}
+	var b strings.Builder
+	b.WriteString(paint(text.Colors{text.Bold}, "--- "+oldName) + "\n")
+	b.WriteString(paint(text.Colors{text.Bold}, "+++ "+newName) + "\n")
+	for _, entry := range entries {
+		b.WriteString(paint(text.Colors{text.FgCyan}, fmt.Sprintf("@@ %s %q (%s) @@", entry.Kind, entry.Key, entry.Action)) + "\n")
+		for _, field := range entry.Fields {
+			if field.Old != nil {
+				b.WriteString(paint(text.Colors{text.FgRed}, fmt.Sprintf("-  %s: %s", field.Field, diffFieldDisplay(field.Old))) + "\n")
+			}
+			if field.New != nil {
+				b.WriteString(paint(text.Colors{text.FgGreen}, fmt.Sprintf("+  %s: %s", field.Field, diffFieldDisplay(field.New))) + "\n")
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// renderDiffMarkdown renders entries as a Markdown table per kind
+func renderDiffMarkdown(entries []diffEntry, oldName, newName string) string {
+	escape := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Differences between %s and %s\n", escape(oldName), escape(newName))
+	if len(entries) == 0 {
+		b.WriteString("\nNo differences.\n")
+	}
+	kind := ""
+	for _, entry := range entries {
+		if entry.Kind != kind {
+			kind = entry.Kind
+			fmt.Fprintf(&b, "\n## %s\n\n", kind)
+			fmt.Fprintf(&b, "| Object | Change | Field | %s | %s |\n", escape(oldName), escape(newName))
+			b.WriteString("| --- | --- | --- | --- | --- |\n")
+		}
+		for _, field := range entry.Fields {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", escape(entry.Key), entry.Action, escape(field.Field), escape(diffFieldDisplay(field.Old)), escape(diffFieldDisplay(field.New)))
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,163 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+	"github.com/spf13/pflag"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func resetDiffOnlyFlag() {
+	// slice flags append to their value once set
+	f := diffCmd.Flags().Lookup("only")
+	f.Value.(pflag.SliceValue).Replace([]string{})
+	f.Changed = false
+}
+
+func TestDiffAgainstDir(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer resetDiffOnlyFlag()
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "groups.json"), []byte(
+		`[{"id": 9, "name": "Engineering", "color": "#000000"}, {"id": 10, "name": "Sales", "color": "#ffffff"}]`), 0600), nil)
+
+	// IDs differ, Engineering was renamed in case only, Sales was removed and
+	// Support added
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 20, "name": "engineering", "color": "#000000"},
+			{"id": 21, "name": "Support", "color": "#ff0000"},
+		})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"diff",
+		"-o=json",
+		"--against-dir", dir,
+		"--only", "groups",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	entries := []diffEntry{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &entries), nil)
+	st.Assert(t, len(entries), 3)
+	st.Expect(t, entries[0], diffEntry{Kind: "groups", Key: "engineering", Action: diffChanged, Fields: []diffField{
+		{Field: "name", Old: diffValue("Engineering"), New: diffValue("engineering")},
+	}})
+	st.Expect(t, entries[1].Key, "sales")
+	st.Expect(t, entries[1].Action, diffRemoved)
+	st.Expect(t, entries[2].Key, "support")
+	st.Expect(t, entries[2].Action, diffAdded)
+	st.Expect(t, entries[2].Fields, []diffField{
+		{Field: "color", New: diffValue("#ff0000")},
+		{Field: "name", New: diffValue("Support")},
+	})
+}
+
+func TestDiffPolicyGroupReferences(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer resetDiffOnlyFlag()
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "groups.json"), []byte(
+		`[{"id": 9, "name": "Engineering"}, {"id": 10, "name": "Sales"}]`), 0600), nil)
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "policies.json"), []byte(`[
+		{"id": 1, "name": "VPN", "conditions": {"rbac": {"enabled": true, "group_ids": [9], "user_ids": []}}},
+		{"id": 2, "name": "Wiki", "conditions": {"rbac": {"enabled": true, "group_ids": [9], "user_ids": []}}}
+	]`), 0600), nil)
+
+	// the groups have other IDs in the tenant; VPN references the same
+	// group, and Wiki another one
+	gock.New(baseURIinTests()).
+		Get("/access_policies").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 5, "name": "VPN", "conditions": map[string]interface{}{"rbac": map[string]interface{}{"enabled": true, "group_ids": []int{20}, "user_ids": []int{}}}},
+			{"id": 6, "name": "Wiki", "conditions": map[string]interface{}{"rbac": map[string]interface{}{"enabled": true, "group_ids": []int{21}, "user_ids": []int{}}}},
+		})
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 20, "name": "Engineering"},
+			{"id": 21, "name": "Sales"},
+		})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"diff",
+		"-o=json",
+		"--against-dir", dir,
+		"--only", "policies",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	entries := []diffEntry{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &entries), nil)
+	st.Assert(t, len(entries), 1)
+	st.Expect(t, entries[0], diffEntry{Kind: "policies", Key: "wiki", Action: diffChanged, Fields: []diffField{
+		{Field: "conditions.rbac.group_ids", Old: diffValue(`["Engineering"]`), New: diffValue(`["Sales"]`)},
+	}})
+}
+
+func diffValue(s string) *string {
+	return &s
+}
+
+func TestDiffFieldsEmptyValues(t *testing.T) {
+	fields := diffFields(
+		map[string]string{"description": "", "color": "", "name": "Sales"},
+		map[string]string{"color": "", "name": ""},
+	)
+	st.Expect(t, fields, []diffField{
+		{Field: "description", Old: diffValue("")},
+		{Field: "name", Old: diffValue("Sales"), New: diffValue("")},
+	})
+	st.Expect(t, renderDiffUnified([]diffEntry{{Kind: "groups", Key: "sales", Action: diffChanged, Fields: fields}}, "a", "b", false),
+		"--- a\n+++ b\n@@ groups \"sales\" (changed) @@\n-  description: \"\"\n-  name: Sales\n+  name: \"\"")
+}
@@ -0,0 +1,259 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+)
+
+// driftVolatileFields lists fields that change without configuration
+// changes, and are never compared when checking for drift
+var driftVolatileFields = []string{"created_at", "updated_at", "last_access_at", "access_count"}
+
+// drift statuses
+const (
+	driftInSync    = "in_sync"
+	driftDrifted   = "drifted"
+	driftMissing   = "missing"
+	driftUnmanaged = "unmanaged"
+)
+
+// driftResult is the result of checking an object for drift
+type driftResult struct {
+	Kind        string                `json:"kind"`
+	Name        string                `json:"name"`
+	ID          interface{}           `json:"id,omitempty"`
+	Source      string                `json:"source,omitempty"`
+	Status      string                `json:"status"`
+	DesiredHash string                `json:"desired_hash,omitempty"`
+	LiveHash    string                `json:"live_hash,omitempty"`
+	Fields      []manifestFieldChange `json:"fields,omitempty"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// driftError is returned when some objects drifted from the manifests
+type driftError struct {
+	drifted, total int
+}
+
+func (e *driftError) Error() string {
+	return fmt.Sprintf("drift detected: %d of %d objects do not match the manifests", e.drifted, e.total)
+}
+
+// ExitCode tells apart drift from failures to check for it
+func (e *driftError) ExitCode() int {
+	return 2
+}
+
+// driftHash returns a hash of the normalized JSON of the fields of values
+// managed by the manifests, with volatile fields left out
+func driftHash(kind *manifestKind, values map[string]interface{}) string {
+	normalized := make(map[string]string, len(values))
+	for k, v := range values {
+		if funk.Contains(driftVolatileFields, k) {
+			continue
+		}
+		field, _ := kind.field(k)
+		if field.typ == manifestJSON {
+			v = driftRemoveVolatile(v)
+		}
+		normalized[k] = manifestCompareValue(field, v)
+	}
+	// maps are encoded with sorted keys
+	b, _ := json.Marshal(normalized)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// driftRemoveVolatile returns v without volatile fields, at any depth
+func driftRemoveVolatile(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			if !funk.Contains(driftVolatileFields, k) {
+				m[k] = driftRemoveVolatile(item)
+			}
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			list[i] = driftRemoveVolatile(item)
+		}
+		return list
+	}
+	return v
+}
+
+// checkDrift compares the objects in the manifests with the live objects
+// of the tenant. With prune, live objects of the kinds present in the
+// manifests that are not in them are reported as unmanaged
+func checkDrift(cmd *cobra.Command, set *manifestSet, prune bool) ([]*driftResult, error) {
+	s, err := fetchManifestState(cmd, set)
+	if err != nil {
+		return nil, err
+	}
+	results := []*driftResult{}
+	for _, kind := range manifestKinds {
+		for _, desired := range set.objects[kind.name] {
+			result := &driftResult{
+				Kind:   kind.name,
+				Name:   desired.name,
+				Source: desired.source,
+				Status: driftMissing,
+			}
+			results = append(results, result)
+			values, err := s.resolveValues(kind, desired)
+			if err != nil {
+				// references to missing objects are drift, not failures
+				result.Status = driftDrifted
+				result.Error = err.Error()
+				continue
+			}
+			result.DesiredHash = driftHash(kind, values)
+			live := s.find(kind.name, desired.name)
+			if live == nil {
+				continue
+			}
+
+			result.ID = live.id
+			current := make(map[string]interface{}, len(values))
+			fields := []string{}
+			for k := range values {
+				fields = append(fields, k)
+				current[k] = live.values[k]
+				field, _ := kind.field(k)
+				if field.typ == manifestJSON {
+					current[k] = projectManifestJSON(live.values[k], values[k])
+				}
+			}
+			sort.Strings(fields)
+			result.LiveHash = driftHash(kind, current)
+			result.Status = driftInSync
+			if result.LiveHash == result.DesiredHash {
+				continue
+			}
+			result.Status = driftDrifted
+			for _, k := range fields {
+				field, _ := kind.field(k)
+				if funk.Contains(driftVolatileFields, k) {
+					continue
+				}
+				currentValue, desiredValue := current[k], values[k]
+				if field.typ == manifestJSON {
+					currentValue = driftRemoveVolatile(currentValue)
+					desiredValue = driftRemoveVolatile(desiredValue)
+				}
+				if manifestCompareValue(field, currentValue) != manifestCompareValue(field, desiredValue) {
+					result.Fields = append(result.Fields, manifestFieldChange{
+						Field:   k,
+						Current: s.displayValue(field, currentValue),
+						Desired: s.displayValue(field, desiredValue),
+					})
+				}
+			}
+		}
+
+		if !prune || !set.present[kind.name] || kind.singleton {
+			continue
+		}
+		for _, live := range s.objects[kind.name] {
+			found := false
+			for _, desired := range set.objects[kind.name] {
+				found = found || strings.EqualFold(desired.name, live.name)
+			}
+			if !found {
+				results = append(results, &driftResult{
+					Kind:     kind.name,
+					Name:     live.name,
+					ID:       live.id,
+					Status:   driftUnmanaged,
+					LiveHash: driftHash(kind, live.values),
+				})
+			}
+		}
+	}
+	return results, nil
+}
+
+// driftResultsError returns a driftError if any of results is not in sync
+func driftResultsError(results []*driftResult) error {
+	drifted := 0
+	for _, result := range results {
+		if result.Status != driftInSync {
+			drifted++
+		}
+	}
+	if drifted == 0 {
+		return nil
+	}
+	return &driftError{drifted: drifted, total: len(results)}
+}
+
+func driftBuildTableWriter() table.Writer {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Status",
+		"Kind",
+		"Name",
+		"ID",
+		"Field",
+		"Live",
+		"Desired",
+	})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 10},
+		{Number: 2, WidthMax: 12},
+		{Number: 3, WidthMax: 30},
+		{Number: 4, WidthMax: 36},
+		{Number: 5, WidthMax: 30},
+		{Number: 6, WidthMax: 40},
+		{Number: 7, WidthMax: 40},
+	})
+	return tw
+}
+
+func driftTableWriterAppend(tw table.Writer, result *driftResult) {
+	id := result.ID
+	if id == nil {
+		id = ""
+	}
+	if len(result.Fields) == 0 {
+		tw.AppendRow(table.Row{result.Status, result.Kind, result.Name, id, result.Error, "", ""})
+		return
+	}
+	for i, field := range result.Fields {
+		if i == 0 {
+			tw.AppendRow(table.Row{result.Status, result.Kind, result.Name, id, field.Field, field.Current, field.Desired})
+		} else {
+			tw.AppendRow(table.Row{"", "", "", "", field.Field, field.Current, field.Desired})
+		}
+	}
+}
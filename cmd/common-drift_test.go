@@ -0,0 +1,84 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestDriftCheck(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer driftCheckCmd.Flags().Set("prune", "false")
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "groups.yaml"), []byte(`groups:
+  - name: Engineering
+    description: Engineers
+  - name: Sales
+    color: "#ff0000"
+  - name: Support
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "3").
+		JSON([]map[string]interface{}{
+			{"id": 2, "name": "Engineering", "description": "Changed in the console", "updated_at": "2026-10-01T00:00:00Z"},
+			{"id": 4, "name": "sales", "description": "Sales team", "color": "#FF0000"},
+			{"id": 5, "name": "Marketing"},
+		})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"drift",
+		"check",
+		"-o=json",
+		"-f", dir,
+		"--prune",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, &driftError{drifted: 3, total: 4})
+	st.Expect(t, gock.IsDone(), true)
+
+	results := []driftResult{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &results), nil)
+	st.Assert(t, len(results), 4)
+	st.Expect(t, results[0].Status, driftDrifted)
+	st.Expect(t, results[0].Fields, []manifestFieldChange{{Field: "description", Current: "Changed in the console", Desired: "Engineers"}})
+	st.Expect(t, results[1].Status, driftInSync)
+	st.Expect(t, results[1].LiveHash, results[1].DesiredHash)
+	st.Expect(t, results[2].Name, "Support")
+	st.Expect(t, results[2].Status, driftMissing)
+	st.Expect(t, results[3].Name, "Marketing")
+	st.Expect(t, results[3].Status, driftUnmanaged)
+}
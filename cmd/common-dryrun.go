@@ -0,0 +1,98 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+)
+
+// dryRunRequest is a request that was not sent because of --dry-run
+type dryRunRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// dryRunError is returned by the API client, in place of the server
+// response, for requests that were held back because of --dry-run
+type dryRunError struct {
+	request *dryRunRequest
+}
+
+func (e *dryRunError) Error() string {
+	return fmt.Sprintf("dry run: %s %s not sent", e.request.Method, e.request.Path)
+}
+
+func isDryRunError(err error) bool {
+	var d *dryRunError
+	return errors.As(err, &d)
+}
+
+var dryRunIDListRegexp = regexp.MustCompile(`^[0-9]+(,[0-9]+)*$`)
+
+// dryRunRequestID returns the ID of the record targeted by a request, taken
+// from the last path segment that looks like an ID, other than the tenant ID.
+// Requests without one (such as record creations) are identified by their
+// position
+func dryRunRequestID(r *dryRunRequest, position int) string {
+	path := strings.SplitN(r.Path, "?", 2)[0]
+	segments := strings.Split(path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if i > 0 && segments[i-1] == "tenants" {
+			continue
+		}
+		if dryRunIDListRegexp.MatchString(segments[i]) || strfmt.IsUUID(segments[i]) {
+			return segments[i]
+		}
+	}
+	return fmt.Sprintf("#%d", position)
+}
+
+func dryRunRequestVerb(r *dryRunRequest) string {
+	switch r.Method {
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "send"
+	}
+}
+
+// renderDryRunReport builds the output for --dry-run, in the same format
+// used for the results of multiple operations
+func renderDryRunReport(requests []*dryRunRequest) (table.Writer, []multiOpJSONResult) {
+	tw, j := multiOpBuildTableWriter()
+	for i, r := range requests {
+		result := fmt.Sprintf("would %s: %s %s", dryRunRequestVerb(r), r.Method, r.Path)
+		if r.Body != "" {
+			result += "\n" + r.Body
+		}
+		multiOpTableWriterAppend(tw, &j, dryRunRequestID(r, i+1), result)
+	}
+	return tw, j
+}
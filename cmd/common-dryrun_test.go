@@ -0,0 +1,40 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestDryRunRequestID(t *testing.T) {
+	tenant := "/api/v1/tenants/5d7f4a02-6b2c-4c8e-9f1a-3e0b6c9d2a71"
+	for _, test := range []struct {
+		path string
+		want string
+	}{
+		{tenant + "/users", "#3"},
+		{tenant + "/users/12", "12"},
+		{tenant + "/users/12,13", "12,13"},
+		{tenant + "/access_proxies/8b2e1f40-1c3d-4e5f-8a9b-0c1d2e3f4a5b?x=1", "8b2e1f40-1c3d-4e5f-8a9b-0c1d2e3f4a5b"},
+		{tenant + "/settings/analytics", "#3"},
+	} {
+		st.Expect(t, dryRunRequestID(&dryRunRequest{Method: "POST", Path: test.path}, 3), test.want)
+	}
+}
@@ -0,0 +1,275 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	apiagentconfiguration "github.com/barracuda-cloudgen-access/access-cli/client/settings_agent_configuration"
+	apianalytics "github.com/barracuda-cloudgen-access/access-cli/client/settings_analytics"
+	apienrollment "github.com/barracuda-cloudgen-access/access-cli/client/settings_enrollment"
+	apiwebpolicies "github.com/barracuda-cloudgen-access/access-cli/client/web_policies"
+)
+
+// exportManifestName is the base name of the file describing an export
+const exportManifestName = "manifest"
+
+// exportKind is a kind of object included in tenant exports
+type exportKind struct {
+	name string // also the base name of the file the objects are written to
+	// fetch returns the objects of the kind, or a single object for kinds
+	// that are not lists
+	fetch func(cmd *cobra.Command) (interface{}, error)
+}
+
+// exportKinds lists the kinds of objects in tenant exports
+var exportKinds = []exportKind{
+	{"users", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllUsers(cmd, "")
+	}},
+	{"groups", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllGroups(cmd, "")
+	}},
+	{"devices", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllDevices(cmd)
+	}},
+	{"resources", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllResources(cmd, "")
+	}},
+	{"policies", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllPolicies(cmd, "")
+	}},
+	{"proxies", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllProxies(cmd, "")
+	}},
+	{"admins", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllAdmins(cmd, "")
+	}},
+	{"assets", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllAssets(cmd, "")
+	}},
+	{"sources", func(cmd *cobra.Command) (interface{}, error) {
+		return listAllSources(cmd)
+	}},
+	{"webpolicies", func(cmd *cobra.Command) (interface{}, error) {
+		// the main ruleset, including the rulesets of its jump rules
+		params := apiwebpolicies.NewListWebPoliciesParams()
+		setTenant(cmd, params)
+		resp, err := global.Client.WebPolicies.ListWebPolicies(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Payload, nil
+	}},
+	{"settings", func(cmd *cobra.Command) (interface{}, error) {
+		settings := make(map[string]interface{})
+
+		agentParams := apiagentconfiguration.NewSettingsAgentConfigurationParams()
+		setTenant(cmd, agentParams)
+		agent, err := global.Client.SettingsAgentConfiguration.SettingsAgentConfiguration(agentParams, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		settings["agent_configuration"] = agent.Payload
+
+		analyticsParams := apianalytics.NewSettingsAnalyticsParams()
+		setTenant(cmd, analyticsParams)
+		analytics, err := global.Client.SettingsAnalytics.SettingsAnalytics(analyticsParams, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		settings["analytics"] = analytics.Payload
+
+		enrollmentParams := apienrollment.NewSettingsEnrollmentParams()
+		setTenant(cmd, enrollmentParams)
+		enrollment, err := global.Client.SettingsEnrollment.SettingsEnrollment(enrollmentParams, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		settings["enrollment"] = enrollment.Payload
+		return settings, nil
+	}},
+}
+
+// exportManifest describes an export, and is written along with it
+type exportManifest struct {
+	Endpoint   string               `json:"endpoint" yaml:"endpoint"`
+	Tenant     string               `json:"tenant" yaml:"tenant"`
+	ExportedAt time.Time            `json:"exported_at" yaml:"exported_at"`
+	CLIVersion string               `json:"cli_version" yaml:"cli_version"`
+	Format     string               `json:"format" yaml:"format"`
+	Files      []exportManifestFile `json:"files" yaml:"files"`
+}
+
+type exportManifestFile struct {
+	Kind    string `json:"kind" yaml:"kind"`
+	File    string `json:"file" yaml:"file"`
+	Records int    `json:"records" yaml:"records"`
+}
+
+// exportPlainValue converts an object returned by the API into maps, slices
+// and scalars, with the items of lists sorted by ID so that exports of the
+// same data are identical
+func exportPlainValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var plain interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&plain); err != nil {
+		return nil, err
+	}
+	plain = exportConvertNumbers(plain)
+	if list, ok := plain.([]interface{}); ok {
+		sort.SliceStable(list, func(i, j int) bool {
+			return exportLessID(exportID(list[i]), exportID(list[j]))
+		})
+	}
+	return plain, nil
+}
+
+// exportConvertNumbers replaces json.Number values with integers or
+// floats, as the YAML encoder would otherwise quote them
+func exportConvertNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = exportConvertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = exportConvertNumbers(item)
+		}
+	}
+	return v
+}
+
+func exportID(v interface{}) string {
+	if m, ok := v.(map[string]interface{}); ok && m["id"] != nil {
+		return fmt.Sprint(m["id"])
+	}
+	return ""
+}
+
+// exportLessID compares IDs numerically when both are numbers
+func exportLessID(a, b string) bool {
+	ai, errA := strconv.ParseInt(a, 10, 64)
+	bi, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		return ai < bi
+	}
+	return a < b
+}
+
+// writeExportFile writes v to path, in the given format (yaml or json)
+func writeExportFile(path, format string, v interface{}) error {
+	return writeFileAtomically(path, func(w io.Writer) error {
+		if format == "json" {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(v)
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	})
+}
+
+// exportTenant writes the objects of every kind to dir, one file per kind,
+// and the manifest describing the export
+func exportTenant(cmd *cobra.Command, dir, format string) (*exportManifest, error) {
+	if format != "yaml" && format != "json" {
+		return nil, fmt.Errorf("unsupported export format %s", format)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	manifest := &exportManifest{
+		Endpoint:   authViper.GetString(ckeyAuthEndpoint),
+		Tenant:     tenantID(cmd),
+		ExportedAt: time.Now().UTC().Truncate(time.Second),
+		CLIVersion: version.Version,
+		Format:     format,
+		Files:      []exportManifestFile{},
+	}
+	for _, kind := range exportKinds {
+		objects, err := kind.fetch(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("exporting %s: %v", kind.name, processErrorResponse(err))
+		}
+		plain, err := exportPlainValue(objects)
+		if err != nil {
+			return nil, err
+		}
+		records := 1
+		if list, ok := plain.([]interface{}); ok {
+			records = len(list)
+		}
+		file := kind.name + "." + format
+		if err := writeExportFile(filepath.Join(dir, file), format, plain); err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, exportManifestFile{
+			Kind:    kind.name,
+			File:    file,
+			Records: records,
+		})
+	}
+	// the manifest is written last, so that its presence means the export is complete
+	err := writeExportFile(filepath.Join(dir, exportManifestName+"."+format), format, manifest)
+	return manifest, err
+}
+
+func exportBuildTableWriter() table.Writer {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Kind",
+		"File",
+		"Records",
+	})
+	return tw
+}
+
+func exportTableWriterAppend(tw table.Writer, file exportManifestFile) {
+	tw.AppendRow(table.Row{file.Kind, file.File, file.Records})
+}
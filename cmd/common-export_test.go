@@ -0,0 +1,107 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+	"gopkg.in/yaml.v3"
+)
+
+// gockExportEndpoints mocks the endpoints listing the objects in exports,
+// with groups returned out of order
+func gockExportEndpoints() {
+	for _, path := range []string{"/users", "/devices", "/access_resources", "/access_policies",
+		"/access_proxies", "/admins", "/assets", "/asset_sources"} {
+		gock.New(baseURIinTests()).
+			Get(path).
+			Reply(200).
+			SetHeader("total", "0").
+			JSON([]map[string]interface{}{})
+	}
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 10, "name": "Sales"},
+			{"id": 9, "name": "Engineering"},
+		})
+	gock.New(baseURIinTests()).
+		Get("/dns_security/rulesets").
+		Reply(200).
+		JSON(map[string]interface{}{"id": "7a4bd5a1-c4a2-4ba8-9a2b-6d7f4c1f1a10", "rules": []interface{}{}})
+	gock.New(baseURIinTests()).
+		Get("/app_configuration").
+		Reply(200).
+		JSON(map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Get("/analytics_settings").
+		Reply(200).
+		JSON(map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Get("/enrollment_settings").
+		Reply(200).
+		JSON(map[string]interface{}{"expiration_days": 7})
+}
+
+func TestExport(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	gockExportEndpoints()
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"export",
+		"-o=csv",
+		"--dir", dir,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	st.Expect(t, strings.Contains(out.String(), "groups,groups.yaml,2"), true)
+
+	groups, err := ioutil.ReadFile(filepath.Join(dir, "groups.yaml"))
+	st.Assert(t, err, nil)
+	st.Expect(t, strings.Index(string(groups), "Engineering") < strings.Index(string(groups), "Sales"), true)
+
+	settings, err := ioutil.ReadFile(filepath.Join(dir, "settings.yaml"))
+	st.Assert(t, err, nil)
+	st.Expect(t, strings.Contains(string(settings), "expiration_days: 7"), true)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "manifest.yaml"))
+	st.Assert(t, err, nil)
+	manifest := exportManifest{}
+	st.Assert(t, yaml.Unmarshal(b, &manifest), nil)
+	st.Expect(t, manifest.Tenant, "testTenantID")
+	st.Expect(t, len(manifest.Files), len(exportKinds))
+}
@@ -0,0 +1,223 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// inputErrorField is the field added to the records written with --failed-out.
+// It is ignored when the records are read back
+const inputErrorField = "_error"
+
+// inputRawField holds, in records written with --failed-out, the text of
+// JSON records that could not be parsed
+const inputRawField = "_raw"
+
+// inputFailedRecords collects the input records whose operation failed, so
+// that they can be written with --failed-out, fixed and retried
+type inputFailedRecords struct {
+	path    string
+	format  string
+	header  []string
+	entries []*inputEntry
+	errors  []string
+}
+
+// newInputFailedRecords returns the collector for the --failed-out file,
+// or nil if the flag was not passed
+func newInputFailedRecords(cmd *cobra.Command, source inputSource) (*inputFailedRecords, error) {
+	if _, ok := cmd.Annotations[flagInitInput]; !ok {
+		return nil, nil
+	}
+	path, err := cmd.Flags().GetString("failed-out")
+	if err != nil || path == "" {
+		return nil, err
+	}
+	format, err := cmd.Flags().GetString("file-format")
+	if err != nil {
+		return nil, err
+	}
+	f := &inputFailedRecords{
+		path:   path,
+		format: format,
+	}
+	if s, ok := source.(*csvInputSource); ok {
+		f.header = s.header
+	}
+	return f, nil
+}
+
+func (f *inputFailedRecords) add(entry *inputEntry, err error) {
+	f.entries = append(f.entries, entry)
+	f.errors = append(f.errors, processErrorResponse(err).Error())
+}
+
+// write writes the failed records to the --failed-out file, in the format of
+// the input file. The file is written even if no records failed, so that
+// it never contains records from a previous run
+func (f *inputFailedRecords) write() error {
+	return writeFileAtomically(f.path, func(w io.Writer) error {
+		switch f.format {
+		case "csv":
+			return f.writeCSV(w)
+		case "yaml":
+			return f.writeYAML(w)
+		default:
+			return f.writeJSON(w, f.format == "ndjson")
+		}
+	})
+}
+
+func (f *inputFailedRecords) writeCSV(w io.Writer) error {
+	header := append([]string{}, f.header...)
+	errorColumn := -1
+	for i, h := range header {
+		if h == inputErrorField {
+			errorColumn = i
+		}
+	}
+	if errorColumn < 0 {
+		errorColumn = len(header)
+		header = append(header, inputErrorField)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for i, entry := range f.entries {
+		row := make([]string, len(header))
+		raw, _ := entry.Raw.([]string) // nil for rows that could not be parsed
+		copy(row, raw)
+		row[errorColumn] = f.errors[i]
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (f *inputFailedRecords) writeJSON(w io.Writer, ndjson bool) error {
+	records := make([][]byte, len(f.entries))
+	for i, entry := range f.entries {
+		original := entry.JSON
+		if resource, ok := entry.Raw.(json.RawMessage); ok {
+			// SCIM resources are written as they were, not as converted
+			original = resource
+		}
+		record, err := jsonWithErrorField(original, f.errors[i])
+		if err != nil {
+			return err
+		}
+		records[i] = record
+	}
+
+	if ndjson {
+		for _, record := range records {
+			if _, err := w.Write(append(record, '\n')); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := io.WriteString(w, "[\n"+string(bytes.Join(records, []byte(",\n")))+"\n]\n")
+	return err
+}
+
+// jsonWithErrorField returns the JSON object in record, with its fields in
+// their original order, followed by the error field
+func jsonWithErrorField(record json.RawMessage, message string) ([]byte, error) {
+	if !json.Valid(record) {
+		// a line that could not be read, kept as a string
+		return json.Marshal(map[string]string{
+			inputRawField:   string(record),
+			inputErrorField: message,
+		})
+	}
+	decoder := json.NewDecoder(bytes.NewReader(record))
+	if t, err := decoder.Token(); err != nil || t != json.Delim('{') {
+		// not an object, leave it as it was
+		buf := new(bytes.Buffer)
+		err := json.Compact(buf, record)
+		return buf.Bytes(), err
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("{")
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		key := t.(string)
+		if key == inputErrorField {
+			continue
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteString(":")
+		if err := json.Compact(buf, value); err != nil {
+			return nil, err
+		}
+		buf.WriteString(",")
+	}
+	k, _ := json.Marshal(inputErrorField)
+	m, _ := json.Marshal(message)
+	buf.Write(k)
+	buf.WriteString(":")
+	buf.Write(m)
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func (f *inputFailedRecords) writeYAML(w io.Writer) error {
+	list := &yaml.Node{Kind: yaml.SequenceNode}
+	for i, entry := range f.entries {
+		node, ok := entry.Raw.(*yaml.Node)
+		if !ok || node.Kind != yaml.MappingNode {
+			list.Content = append(list.Content, node)
+			continue
+		}
+		record := *node
+		record.Content = []*yaml.Node{}
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value != inputErrorField {
+				record.Content = append(record.Content, node.Content[j], node.Content[j+1])
+			}
+		}
+		record.Content = append(record.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: inputErrorField},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: f.errors[i]})
+		list.Content = append(list.Content, &record)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(list); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
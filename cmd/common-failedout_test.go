@@ -0,0 +1,136 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestAddUsersFailedOut(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer func() {
+		usersAddCmd.Flags().Set("from-file", "")
+		usersAddCmd.Flags().Set("file-format", "json")
+		usersAddCmd.Flags().Set("failed-out", "")
+	}()
+
+	inputFile := filepath.Join(dir, "users.csv")
+	failedFile := filepath.Join(dir, "failed.csv")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(
+		"email,name\nalice@example.com,Alice\nbob@example.com,\"Bob, Jr.\"\ncarol@example.com,Carol\n"), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 101, "name": "Alice", "email": "alice@example.com"})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(422).
+		JSON(map[string]interface{}{"email": []string{"has already been taken"}})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 103, "name": "Carol", "email": "carol@example.com"})
+
+	cmd := rootCmd
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{
+		"users",
+		"add",
+		"-o=json",
+		"--continue-on-error",
+		"--from-file=" + inputFile,
+		"--file-format=csv",
+		"--failed-out=" + failedFile,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	failed, err := ioutil.ReadFile(failedFile)
+	st.Assert(t, err, nil)
+	st.Expect(t, string(failed), "email,name,_error\nbob@example.com,\"Bob, Jr.\",email: has already been taken\n")
+}
+
+func TestJSONWithErrorField(t *testing.T) {
+	record, err := jsonWithErrorField([]byte(`{"name": "Bob", "group_ids": [1, 2], "_error": "old"}`), "not found")
+	st.Expect(t, err, nil)
+	st.Expect(t, string(record), `{"name":"Bob","group_ids":[1,2],"_error":"not found"}`)
+}
+
+func TestAddUsersUnreadableRecord(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer func() {
+		usersAddCmd.Flags().Set("from-file", "")
+		usersAddCmd.Flags().Set("file-format", "json")
+		usersAddCmd.Flags().Set("failed-out", "")
+	}()
+
+	inputFile := filepath.Join(dir, "users.ndjson")
+	failedFile := filepath.Join(dir, "failed.ndjson")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(
+		"{\"name\": \"Alice\", \"email\": \"alice@example.com\"}\n{\"name\": \"Bob\",\n{\"name\": \"Carol\", \"email\": \"carol@example.com\"}\n"), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 101, "name": "Alice", "email": "alice@example.com"})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 103, "name": "Carol", "email": "carol@example.com"})
+
+	cmd := rootCmd
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{
+		"users",
+		"add",
+		"-o=csv",
+		"--continue-on-error",
+		"--from-file=" + inputFile,
+		"--file-format=ndjson",
+		"--failed-out=" + failedFile,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	st.Expect(t, bytes.Contains(buf.Bytes(), []byte("record 2,line 2 is not valid JSON")), true)
+
+	failed, err := ioutil.ReadFile(failedFile)
+	st.Assert(t, err, nil)
+	st.Expect(t, string(failed), "{\"_error\":\"line 2 is not valid JSON\",\"_raw\":\"{\\\"name\\\": \\\"Bob\\\",\"}\n")
+}
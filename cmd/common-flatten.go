@@ -0,0 +1,315 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// flatRecord holds the flattened representation of a JSON object,
+// with keys in the order they were found
+type flatRecord struct {
+	keys   []string
+	values map[string]string
+}
+
+func newFlatRecord() *flatRecord {
+	return &flatRecord{
+		values: make(map[string]string),
+	}
+}
+
+func (r *flatRecord) set(key, value string) {
+	if _, present := r.values[key]; !present {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
+}
+
+// renderFlattenedCSV renders the JSON representation of data as CSV,
+// with one column per leaf value. Nested objects and arrays of objects
+// get dotted headers (e.g. port_mappings.0.public_ports) and arrays
+// of scalars are joined using commas
+func renderFlattenedCSV(data interface{}) (string, error) {
+	records, err := flattenData(data)
+	if err != nil {
+		return "", err
+	}
+
+	header := []string{}
+	seen := make(map[string]bool)
+	for _, record := range records {
+		for _, key := range record.keys {
+			if !seen[key] {
+				seen[key] = true
+				header = append(header, key)
+			}
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	w := csv.NewWriter(buf)
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	for _, record := range records {
+		row := make([]string, len(header))
+		for i, key := range header {
+			row[i] = record.values[key]
+		}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// flattenData flattens data into one record per array element
+// (or a single record, if data is not an array). null elements,
+// such as those used to represent failed operations, are skipped
+func flattenData(data interface{}) ([]*flatRecord, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	raw = bytes.TrimSpace(raw)
+
+	elements := []json.RawMessage{raw}
+	if len(raw) > 0 && raw[0] == '[' {
+		elements = []json.RawMessage{}
+		if err := json.Unmarshal(raw, &elements); err != nil {
+			return nil, err
+		}
+	}
+
+	records := []*flatRecord{}
+	for _, element := range elements {
+		if string(bytes.TrimSpace(element)) == "null" {
+			continue
+		}
+		record := newFlatRecord()
+		if err := flattenJSON(element, "", record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func flattenJSON(raw json.RawMessage, prefix string, record *flatRecord) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+
+	joinKey := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+
+	switch raw[0] {
+	case '{':
+		// decode token by token, so that the field order is kept
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		empty := true
+		for dec.More() {
+			empty = false
+			t, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, ok := t.(string)
+			if !ok {
+				return fmt.Errorf("unexpected JSON object key %v", t)
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			if err := flattenJSON(value, joinKey(key), record); err != nil {
+				return err
+			}
+		}
+		if empty && prefix != "" {
+			record.set(prefix, "")
+		}
+		return nil
+	case '[':
+		elements := []json.RawMessage{}
+		if err := json.Unmarshal(raw, &elements); err != nil {
+			return err
+		}
+		scalars := []string{}
+		for _, element := range elements {
+			element = bytes.TrimSpace(element)
+			if len(element) > 0 && (element[0] == '{' || element[0] == '[') {
+				scalars = nil
+				break
+			}
+			s, err := flatScalar(element)
+			if err != nil {
+				return err
+			}
+			scalars = append(scalars, s)
+		}
+		if scalars != nil {
+			record.set(prefixOrValue(prefix), strings.Join(scalars, ","))
+			return nil
+		}
+		for i, element := range elements {
+			if err := flattenJSON(element, joinKey(strconv.Itoa(i)), record); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		s, err := flatScalar(raw)
+		if err != nil {
+			return err
+		}
+		record.set(prefixOrValue(prefix), s)
+		return nil
+	}
+}
+
+func prefixOrValue(prefix string) string {
+	if prefix == "" {
+		return "value"
+	}
+	return prefix
+}
+
+func flatScalar(raw json.RawMessage) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// unflattenCSVRecord undoes the transformation made by renderFlattenedCSV,
+// turning dotted keys back into nested maps and slices.
+// Keys without dots are left untouched.
+// Empty values in dotted keys are dropped, as they correspond to array
+// elements or nested fields that were not present in the original record
+func unflattenCSVRecord(m map[string]interface{}) map[string]interface{} {
+	nested := make(map[string]interface{})
+	for key, value := range m {
+		if !strings.Contains(key, ".") {
+			nested[key] = value
+			continue
+		}
+		if s, ok := value.(string); ok && s == "" {
+			continue
+		}
+		parts := strings.Split(key, ".")
+		cur := nested
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := cur[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				cur[part] = next
+			}
+			cur = next
+		}
+		cur[parts[len(parts)-1]] = value
+	}
+	for key, value := range nested {
+		nested[key] = indexedMapsToSlices(value)
+	}
+	return nested
+}
+
+// indexedMapsToSlices recursively converts maps whose keys are all
+// non-negative integers into slices ordered by those integers
+func indexedMapsToSlices(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	indexes := make([]int, 0, len(m))
+	for key, value := range m {
+		m[key] = indexedMapsToSlices(value)
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 {
+			indexes = nil
+		} else if indexes != nil {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return m
+	}
+	sort.Ints(indexes)
+	s := make([]interface{}, len(indexes))
+	for i, index := range indexes {
+		s[i] = m[strconv.Itoa(index)]
+	}
+	return s
+}
+
+// normalizeCSVKeys removes underscores from all map keys, recursively,
+// so that snake_case JSON names (as used in the headers produced by
+// renderFlattenedCSV) match the corresponding struct field names when
+// they are compared case-insensitively by mapstructure
+func normalizeCSVKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		n := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			n[strings.ReplaceAll(key, "_", "")] = normalizeCSVKeys(value)
+		}
+		return n
+	case []interface{}:
+		n := make([]interface{}, len(v))
+		for i, value := range v {
+			n[i] = normalizeCSVKeys(value)
+		}
+		return n
+	default:
+		return v
+	}
+}
@@ -0,0 +1,125 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/gbl08ma/mapstructure"
+	"github.com/nbio/st"
+
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+func TestRenderFlattenedCSV(t *testing.T) {
+	resources := []*models.AccessResource{
+		{
+			ID:           "8c0fbb3c-5c3e-4f5d-8a3a-09c3fd4e0f1a",
+			Name:         "wiki",
+			PublicHost:   "wiki.example.com",
+			InternalHost: "10.0.0.5",
+			PortMappings: []*models.AccessResourcePortMapping{
+				{PublicPorts: []string{"80", "443"}, InternalPorts: []string{"8080", "8443"}, Protocol: "tcp"},
+				{PublicPorts: []string{"53"}, InternalPorts: []string{"53"}, Protocol: "udp"},
+			},
+		},
+		nil, // failed operations are skipped
+		{
+			ID:         "0d4b5c6e-1f6f-4c2a-9b8d-7e4c3a2b1c0d",
+			Name:       "git",
+			PublicHost: "git.example.com",
+			PortMappings: []*models.AccessResourcePortMapping{
+				{PublicPorts: []string{"22"}, InternalPorts: []string{"22"}, Protocol: "tcp"},
+			},
+		},
+	}
+
+	out, err := renderFlattenedCSV(resources)
+	st.Expect(t, err, nil)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	st.Expect(t, err, nil)
+	st.Expect(t, len(records), 3)
+
+	header := records[0]
+	column := func(name string) int {
+		for i, h := range header {
+			if h == name {
+				return i
+			}
+		}
+		t.Fatalf("column %s not present in header %v", name, header)
+		return -1
+	}
+
+	st.Expect(t, records[1][column("name")], "wiki")
+	st.Expect(t, records[1][column("port_mappings.0.public_ports")], "80,443")
+	st.Expect(t, records[1][column("port_mappings.1.protocol")], "udp")
+	st.Expect(t, records[2][column("name")], "git")
+	st.Expect(t, records[2][column("port_mappings.0.internal_ports")], "22")
+	st.Expect(t, records[2][column("port_mappings.1.protocol")], "")
+}
+
+func TestFlattenedCSVRoundTrip(t *testing.T) {
+	resource := &models.AccessResource{
+		Name:       "wiki",
+		PublicHost: "wiki.example.com",
+		PortMappings: []*models.AccessResourcePortMapping{
+			{PublicPorts: []string{"80", "443"}, InternalPorts: []string{"8080", "8443"}, Protocol: "tcp"},
+		},
+	}
+	other := &models.AccessResource{
+		Name: "git",
+		PortMappings: []*models.AccessResourcePortMapping{
+			{PublicPorts: []string{"22"}, InternalPorts: []string{"22"}, Protocol: "tcp"},
+			{PublicPorts: []string{"9418"}, InternalPorts: []string{"9418"}, Protocol: "tcp"},
+		},
+	}
+
+	out, err := renderFlattenedCSV([]*models.AccessResource{resource, other})
+	st.Expect(t, err, nil)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	st.Expect(t, err, nil)
+
+	m := make(map[string]interface{})
+	for i, h := range records[0] {
+		m[h] = records[1][i]
+	}
+
+	decoded := &models.AccessResource{}
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       csvMapstructureDecodeHook,
+		WeaklyTypedInput: true,
+		Squash:           true,
+		Result:           decoded,
+	})
+	st.Expect(t, err, nil)
+	err = decoder.Decode(normalizeCSVKeys(unflattenCSVRecord(m)))
+	st.Expect(t, err, nil)
+
+	st.Expect(t, decoded.Name, resource.Name)
+	st.Expect(t, decoded.PublicHost, resource.PublicHost)
+	// the second port mapping column only has values for the other resource
+	st.Expect(t, len(decoded.PortMappings), 1)
+	st.Expect(t, decoded.PortMappings[0].PublicPorts, resource.PortMappings[0].PublicPorts)
+	st.Expect(t, decoded.PortMappings[0].InternalPorts, resource.PortMappings[0].InternalPorts)
+	st.Expect(t, decoded.PortMappings[0].Protocol, "tcp")
+}
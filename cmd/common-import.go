@@ -0,0 +1,691 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+	"gopkg.in/yaml.v3"
+
+	apipolicies "github.com/barracuda-cloudgen-access/access-cli/client/access_policies"
+	apiproxies "github.com/barracuda-cloudgen-access/access-cli/client/access_proxies"
+	apiresources "github.com/barracuda-cloudgen-access/access-cli/client/access_resources"
+	apigroups "github.com/barracuda-cloudgen-access/access-cli/client/groups"
+	apiusers "github.com/barracuda-cloudgen-access/access-cli/client/users"
+	apiwebpolicies "github.com/barracuda-cloudgen-access/access-cli/client/web_policies"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// importRecord is a record read from an export, to be created in the
+// target tenant
+type importRecord struct {
+	id     string // ID in the exported tenant
+	name   string
+	create func(s *importState) (interface{}, error) // returns the ID in the target tenant
+	// policies lists, for resources, the IDs in the exported tenant of the
+	// policies the resource is linked to
+	policies []string
+}
+
+// importKind is a kind of object that can be imported from an export
+type importKind struct {
+	name    string // also the base name of the file the objects are read from
+	records func(data []byte) ([]importRecord, error)
+}
+
+// importKinds lists the kinds of objects that can be imported, in the order
+// in which they are imported, so that objects are created before those
+// referencing them
+var importKinds = []importKind{
+	{"groups", func(data []byte) ([]importRecord, error) {
+		items := []*apigroups.ListGroupsOKBodyItems0{}
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		records := []importRecord{}
+		for _, item := range items {
+			item := item
+			records = append(records, importRecord{
+				id:   fmt.Sprint(item.ID),
+				name: item.Name,
+				create: func(s *importState) (interface{}, error) {
+					params := apigroups.NewCreateGroupParams()
+					setTenant(s.cmd, params)
+					params.SetGroup(apigroups.CreateGroupBody{Group: &apigroups.CreateGroupParamsBodyGroup{
+						Name:        item.Name,
+						Description: item.Description,
+						Color:       item.Color,
+					}})
+					resp, err := global.Client.Groups.CreateGroup(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return resp.Payload.ID, nil
+				},
+			})
+		}
+		return records, nil
+	}},
+	{"users", func(data []byte) ([]importRecord, error) {
+		items := []*apiusers.ListUsersOKBodyItems0{}
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		records := []importRecord{}
+		for _, item := range items {
+			item := item
+			records = append(records, importRecord{
+				id:   fmt.Sprint(item.ID),
+				name: item.Name,
+				create: func(s *importState) (interface{}, error) {
+					groupIDs := []int64{}
+					for _, group := range item.Groups {
+						id, ok, err := s.newInt64("groups", group.ID)
+						if err != nil {
+							return nil, err
+						}
+						if ok {
+							groupIDs = append(groupIDs, id)
+						}
+					}
+					params := apiusers.NewCreateUserParams()
+					setTenant(s.cmd, params)
+					params.SetUser(apiusers.CreateUserBody{User: &apiusers.CreateUserParamsBodyUser{
+						Name:     item.Name,
+						Email:    item.Email,
+						Enabled:  item.Enabled,
+						GroupIds: groupIDs,
+					}})
+					resp, err := global.Client.Users.CreateUser(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return resp.Payload.ID, nil
+				},
+			})
+		}
+		return records, nil
+	}},
+	{"proxies", func(data []byte) ([]importRecord, error) {
+		items := []*apiproxies.ListProxiesOKBodyItems0{}
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		records := []importRecord{}
+		for _, item := range items {
+			item := item
+			records = append(records, importRecord{
+				id:   item.ID.String(),
+				name: item.Name,
+				create: func(s *importState) (interface{}, error) {
+					params := apiproxies.NewCreateProxyParams()
+					setTenant(s.cmd, params)
+					params.SetProxy(apiproxies.CreateProxyBody{
+						Name:     item.Name,
+						Location: item.Location,
+						Host:     item.Host,
+						Port:     item.Port,
+					})
+					resp, err := global.Client.AccessProxies.CreateProxy(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return resp.Payload.ID, nil
+				},
+			})
+		}
+		return records, nil
+	}},
+	{"policies", func(data []byte) ([]importRecord, error) {
+		items := []*apipolicies.ListPoliciesOKBodyItems0{}
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		records := []importRecord{}
+		for _, item := range items {
+			item := item
+			records = append(records, importRecord{
+				id:   fmt.Sprint(item.ID),
+				name: item.Name,
+				create: func(s *importState) (interface{}, error) {
+					// resources are linked to policies when importing resources
+					policy := &apipolicies.CreatePolicyParamsBodyAccessPolicy{
+						Name:              item.Name,
+						AccessResourceIds: []strfmt.UUID{},
+					}
+					if item.Conditions != nil && item.Conditions.Rbac != nil {
+						rbac := item.Conditions.Rbac
+						groupIDs, err := s.newInt64s("groups", rbac.GroupIds)
+						if err != nil {
+							return nil, err
+						}
+						userIDs, err := s.newInt64s("users", rbac.UserIds)
+						if err != nil {
+							return nil, err
+						}
+						policy.Conditions = &apipolicies.CreatePolicyParamsBodyAccessPolicyConditions{
+							Rbac: &apipolicies.CreatePolicyParamsBodyAccessPolicyConditionsRbac{
+								Enabled:  rbac.Enabled,
+								GroupIds: groupIDs,
+								UserIds:  userIDs,
+							},
+						}
+					}
+					params := apipolicies.NewCreatePolicyParams()
+					setTenant(s.cmd, params)
+					params.SetPolicy(apipolicies.CreatePolicyBody{AccessPolicy: policy})
+					resp, err := global.Client.AccessPolicies.CreatePolicy(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return resp.Payload.ID, nil
+				},
+			})
+		}
+		return records, nil
+	}},
+	{"resources", func(data []byte) ([]importRecord, error) {
+		items := []*models.AccessResource{}
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		records := []importRecord{}
+		for _, item := range items {
+			item := item
+			policies := []string{}
+			for _, policy := range item.AccessPolicies {
+				policies = append(policies, fmt.Sprint(policy.ID))
+			}
+			records = append(records, importRecord{
+				id:       item.ID.String(),
+				name:     item.Name,
+				policies: policies,
+				create: func(s *importState) (interface{}, error) {
+					resource := &apiresources.CreateResourceParamsBodyAccessResource{
+						Name:               item.Name,
+						PublicHost:         item.PublicHost,
+						InternalHost:       item.InternalHost,
+						PortMappings:       item.PortMappings,
+						WildcardExceptions: item.WildcardExceptions,
+						Notes:              stringPointerValue(item.Notes),
+						FixedLastOctet:     item.FixedLastOctet,
+						Enabled:            item.Enabled,
+						AccessPolicyIds:    []int64{},
+					}
+					if item.AccessProxy != nil {
+						id, _, err := s.newUUID("proxies", item.AccessProxy.ID)
+						if err != nil {
+							return nil, err
+						}
+						resource.AccessProxyID = id
+					}
+					for _, policy := range item.AccessPolicies {
+						id, ok, err := s.newInt64("policies", policy.ID)
+						if err != nil {
+							return nil, err
+						}
+						if ok {
+							resource.AccessPolicyIds = append(resource.AccessPolicyIds, id)
+						}
+					}
+					params := apiresources.NewCreateResourceParams()
+					setTenant(s.cmd, params)
+					params.SetResource(apiresources.CreateResourceBody{AccessResource: resource})
+					resp, err := global.Client.AccessResources.CreateResource(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return resp.Payload.ID, nil
+				},
+			})
+		}
+		return records, nil
+	}},
+	{"webpolicies", func(data []byte) ([]importRecord, error) {
+		ruleset := &models.WebPolicy{}
+		if err := json.Unmarshal(data, ruleset); err != nil {
+			return nil, err
+		}
+		records := []importRecord{}
+		// each web policy is the single rule of the ruleset of a jump rule of
+		// the main ruleset, and the users and groups are those of the jump rule
+		for _, jump := range ruleset.Rules {
+			if jump.Type != "jump" || jump.RulesetJump == nil || len(jump.RulesetJump.Rules) < 1 {
+				continue
+			}
+			jump := jump
+			rule := jump.RulesetJump.Rules[0]
+			records = append(records, importRecord{
+				id:   rule.ID.String(),
+				name: rule.Label,
+				create: func(s *importState) (interface{}, error) {
+					groupIDs := []int64{}
+					for _, group := range jump.Groups {
+						id, ok, err := s.newInt64("groups", group.ID)
+						if err != nil {
+							return nil, err
+						}
+						if ok {
+							groupIDs = append(groupIDs, id)
+						}
+					}
+					userIDs := []int64{}
+					for _, user := range jump.Users {
+						id, ok, err := s.newInt64("users", user.ID)
+						if err != nil {
+							return nil, err
+						}
+						if ok {
+							userIDs = append(userIDs, id)
+						}
+					}
+					rulesetID, err := s.webPolicyRuleset()
+					if err != nil {
+						return nil, err
+					}
+					params := apiwebpolicies.NewAddWebPolicyParams()
+					setTenant(s.cmd, params)
+					params.SetRulesetID(rulesetID)
+					params.SetWebpolicy(apiwebpolicies.AddWebPolicyBody{Data: &apiwebpolicies.AddWebPolicyParamsBodyData{
+						Label:      &rule.Label,
+						Action:     &rule.Action,
+						Type:       &rule.Type,
+						Index:      &jump.Index,
+						Domains:    rule.Domains,
+						Categories: rule.Categories,
+						Disabled:   &rule.Disabled,
+						Log:        &rule.Log,
+						Notify:     &rule.Notify,
+						Alert:      &rule.Alert,
+						GroupIds:   groupIDs,
+						UserIds:    userIDs,
+					}})
+					resp, err := global.Client.WebPolicies.AddWebPolicy(params, global.AuthWriter)
+					if err != nil {
+						return nil, err
+					}
+					return resp.Payload.RuleID, nil
+				},
+			})
+		}
+		return records, nil
+	}},
+}
+
+// importMapping maps, for each kind, the IDs of the exported objects to
+// the IDs of the objects created from them
+type importMapping map[string]map[string]interface{}
+
+// importDryRunID is the mapped ID of objects that were not created
+// because of --dry-run
+const importDryRunID = "(dry run)"
+
+type importState struct {
+	cmd       *cobra.Command
+	mapping   importMapping
+	rulesetID strfmt.UUID
+	loopErr   error
+	// existing, when set, returns the ID of an existing object to use in
+	// place of the one that would be created from record
+	existing func(kind string, record importRecord) (interface{}, bool, error)
+	// optional lists the kinds whose objects are left out of references
+	// when they are not in the mapping, instead of failing
+	optional map[string]bool
+	// createdPolicies lists the policies created by this import, and
+	// policyLinks the resources to link to them, by their exported ID
+	createdPolicies []importJSONResult
+	policyLinks     map[string]*importPolicyLinks
+}
+
+// importPolicyLinks are the resources linked to a policy in the exported
+// tenant. Resources created by the import are linked as they are created,
+// but those that were already there are only linked by editing the policy
+type importPolicyLinks struct {
+	resources []strfmt.UUID
+	pending   bool // whether some of the resources are not linked yet
+}
+
+// newID returns the ID in the target tenant of the object of kind with the
+// given ID in the export. ok is false for objects not created because of
+// --dry-run, which are left out of references
+func (s *importState) newID(kind string, old interface{}) (interface{}, bool, error) {
+	id, found := s.mapping[kind][fmt.Sprint(old)]
+	if !found && s.optional[kind] {
+		return nil, false, nil
+	}
+	if !found {
+		return nil, false, fmt.Errorf("referenced ID %v of %s was not imported", old, kind)
+	}
+	if id == importDryRunID {
+		return nil, false, nil
+	}
+	return id, true, nil
+}
+
+func (s *importState) newInt64(kind string, old int64) (int64, bool, error) {
+	id, ok, err := s.newID(kind, old)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	switch v := id.(type) {
+	case int64:
+		return v, true, nil
+	case json.Number:
+		i, err := v.Int64()
+		return i, err == nil, err
+	}
+	return 0, false, fmt.Errorf("invalid mapped ID %v for %s %d", id, kind, old)
+}
+
+func (s *importState) newInt64s(kind string, old []int64) ([]int64, error) {
+	ids := []int64{}
+	for _, o := range old {
+		id, ok, err := s.newInt64(kind, o)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *importState) newUUID(kind string, old strfmt.UUID) (strfmt.UUID, bool, error) {
+	id, ok, err := s.newID(kind, old)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	return strfmt.UUID(fmt.Sprint(id)), true, nil
+}
+
+// webPolicyRuleset returns the ID of the main web policy ruleset of the
+// target tenant
+func (s *importState) webPolicyRuleset() (strfmt.UUID, error) {
+	if s.rulesetID == "" {
+		params := apiwebpolicies.NewListWebPoliciesParams()
+		setTenant(s.cmd, params)
+		resp, err := global.Client.WebPolicies.ListWebPolicies(params, global.AuthWriter)
+		if err != nil {
+			return "", err
+		}
+		s.rulesetID = resp.Payload.ID
+	}
+	return s.rulesetID, nil
+}
+
+// readExportFile returns the contents of the file with the objects of the
+// given kind in an export directory, converted to JSON. It returns nil if
+// there is no such file
+func readExportFile(dir, kind string) ([]byte, error) {
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, kind+ext))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil || ext == ".json" {
+			return data, err
+		}
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("%s%s: %v", kind, ext, err)
+		}
+		return json.Marshal(v)
+	}
+	return nil, nil
+}
+
+func readImportMapping(path string) (importMapping, error) {
+	mapping := make(importMapping)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return mapping, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return mapping, nil
+}
+
+func writeImportMapping(path string, mapping importMapping) error {
+	return writeFileAtomically(path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(mapping)
+	})
+}
+
+const importResultCreated = "created"
+
+// importResultMapped is the result for objects that are already in the
+// mapping file, from a previous import
+const importResultMapped = "skipped (already imported)"
+
+type importJSONResult struct {
+	Kind   string      `json:"kind"`
+	Name   string      `json:"name"`
+	OldID  string      `json:"old_id"`
+	NewID  interface{} `json:"new_id"`
+	OK     bool        `json:"ok"`
+	Result string      `json:"result"`
+}
+
+// importTenant creates the objects of the given kinds read from the export
+// in dir, recording their IDs in mapping. Objects that are already in the
+// mapping are not created again
+func importTenant(cmd *cobra.Command, dir string, only []string, mapping importMapping, report func(importJSONResult)) error {
+	s := &importState{
+		cmd:     cmd,
+		mapping: mapping,
+	}
+	for _, kind := range importKinds {
+		if len(only) > 0 && !funk.Contains(only, kind.name) {
+			continue
+		}
+		data, err := readExportFile(dir, kind.name)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			if len(only) > 0 {
+				return fmt.Errorf("no %s file in %s", kind.name, dir)
+			}
+			continue
+		}
+		records, err := kind.records(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", kind.name, err)
+		}
+		if !s.importRecords(kind.name, records, report) {
+			return s.loopErr
+		}
+	}
+	if !s.linkPolicyResources(report) {
+		return s.loopErr
+	}
+	return s.result()
+}
+
+// importRecords creates the records of kind that are not in the mapping
+// yet, and returns false if the operation must stop because of an error
+func (s *importState) importRecords(kind string, records []importRecord, report func(importJSONResult)) bool {
+	if s.mapping[kind] == nil {
+		s.mapping[kind] = make(map[string]interface{})
+	}
+	for _, record := range records {
+		result := importJSONResult{
+			Kind:  kind,
+			Name:  record.name,
+			OldID: record.id,
+		}
+		if id, found := s.mapping[kind][record.id]; found && id != importDryRunID {
+			s.addPolicyLinks(record, id, true)
+			result.NewID = id
+			result.OK = true
+			result.Result = importResultMapped
+			report(result)
+			continue
+		}
+		var id interface{}
+		found := false
+		var err error
+		if s.existing != nil {
+			id, found, err = s.existing(kind, record)
+		}
+		if err == nil && !found {
+			id, err = record.create(s)
+		}
+		if isDryRunError(err) {
+			id, err = importDryRunID, nil
+		}
+		if err != nil {
+			result.Result = processErrorResponse(err).Error()
+			report(result)
+			if s.loopErr == nil {
+				s.loopErr = err
+			}
+			if !loopControlContinueOnError(s.cmd) {
+				return false
+			}
+			continue
+		}
+		s.mapping[kind][record.id] = id
+		result.NewID = id
+		result.OK = true
+		result.Result = importResultCreated
+		if found {
+			result.Result = (&inputExistsError{id: id}).Error()
+		}
+		if id != importDryRunID {
+			s.addPolicyLinks(record, id, found)
+			if kind == "policies" && !found {
+				s.createdPolicies = append(s.createdPolicies, result)
+			}
+		}
+		report(result)
+	}
+	return true
+}
+
+// addPolicyLinks records that the resource of record, with the given ID in
+// the target tenant, must be linked to its policies. pending tells whether
+// the resource was not created by this import
+func (s *importState) addPolicyLinks(record importRecord, id interface{}, pending bool) {
+	if s.policyLinks == nil {
+		s.policyLinks = make(map[string]*importPolicyLinks)
+	}
+	for _, policy := range record.policies {
+		links := s.policyLinks[policy]
+		if links == nil {
+			links = &importPolicyLinks{}
+			s.policyLinks[policy] = links
+		}
+		links.resources = append(links.resources, strfmt.UUID(fmt.Sprint(id)))
+		links.pending = links.pending || pending
+	}
+}
+
+// linkPolicyResources links the policies created by this import to the
+// resources that were not, which could not be linked as they were created.
+// It returns false if the operation must stop because of an error
+func (s *importState) linkPolicyResources(report func(importJSONResult)) bool {
+	for _, result := range s.createdPolicies {
+		links := s.policyLinks[result.OldID]
+		if links == nil || !links.pending {
+			continue
+		}
+		params := apipolicies.NewEditPolicyParams()
+		setTenant(s.cmd, params)
+		params.SetID(result.NewID.(int64))
+		params.SetPolicy(apipolicies.EditPolicyBody{AccessPolicy: &apipolicies.EditPolicyParamsBodyAccessPolicy{
+			AccessResourceIds: links.resources,
+		}})
+		_, err := global.Client.AccessPolicies.EditPolicy(params, global.AuthWriter)
+		if err != nil && !isDryRunError(err) {
+			result.OK = false
+			result.Result = fmt.Sprintf("linking resources: %v", processErrorResponse(err))
+			report(result)
+			if s.loopErr == nil {
+				s.loopErr = err
+			}
+			if !loopControlContinueOnError(s.cmd) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// result returns the error the operation ends with
+func (s *importState) result() error {
+	if loopControlContinueOnError(s.cmd) {
+		return nil
+	}
+	return s.loopErr
+}
+
+func importBuildTableWriter() (table.Writer, []importJSONResult) {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Kind",
+		"Name",
+		"Old ID",
+		"New ID",
+		"Result",
+	})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 12},
+		{Number: 2, WidthMax: 30},
+		{Number: 3, WidthMax: 36, Align: text.AlignRight},
+		{Number: 4, WidthMax: 36, Align: text.AlignRight},
+		{Number: 5, WidthMax: 60, Align: text.AlignLeft},
+	})
+	return tw, make([]importJSONResult, 0)
+}
+
+func importTableWriterAppend(tw table.Writer, j *[]importJSONResult, result importJSONResult) {
+	newID := result.NewID
+	if newID == nil {
+		newID = ""
+	}
+	tw.AppendRow(table.Row{
+		result.Kind,
+		result.Name,
+		result.OldID,
+		newID,
+		result.Result,
+	})
+	*j = append(*j, result)
+}
@@ -0,0 +1,89 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestImport(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer importCmd.Flags().Set("only", "")
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "groups.json"), []byte(
+		`[{"id": 9, "name": "Engineering"}, {"id": 10, "name": "Sales"}]`), 0600), nil)
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "users.yaml"), []byte(`- id: 3
+  name: Alice
+  email: alice@example.com
+  groups:
+    - id: 10
+      name: Sales
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Post("/groups").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 20, "name": "Engineering"})
+	gock.New(baseURIinTests()).
+		Post("/groups").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 21, "name": "Sales"})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		BodyString(`"group_ids":\[21\]`).
+		Reply(201).
+		JSON(map[string]interface{}{"id": 30, "name": "Alice"})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"import",
+		"-o=json",
+		"--continue-on-error=false",
+		"--dir", dir,
+		"--only", "groups,users",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	results := []importJSONResult{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &results), nil)
+	st.Assert(t, len(results), 3)
+	st.Expect(t, results[2].Kind, "users")
+	st.Expect(t, results[2].Result, importResultCreated)
+
+	mapping, err := readImportMapping(filepath.Join(dir, "import-mapping-testTenantID.json"))
+	st.Assert(t, err, nil)
+	st.Expect(t, mapping["groups"]["10"], json.Number("21"))
+	st.Expect(t, mapping["users"]["3"], json.Number("30"))
+}
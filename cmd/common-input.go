@@ -19,10 +19,13 @@ limitations under the License.
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"strconv"
@@ -33,10 +36,16 @@ import (
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 	"github.com/thoas/go-funk"
+	"gopkg.in/yaml.v3"
 )
 
 type inputData struct {
-	fields []inputField
+	fields     []inputField
+	validation *inputValidationReport
+	journal    *inputJournalSummary
+	// for --skip-existing: the kind of record and the fields identifying existing records
+	existingKind string
+	existingKeys []string
 }
 
 type inputField struct {
@@ -48,7 +57,10 @@ type inputField struct {
 	VarType         string
 	Mandatory       bool
 	MainField       bool
-	SchemaName      string // only used if MainField is true, so that error handling functions can get an identifier for the failing record
+	SchemaName      string   // name of the field in input files. If MainField is true, error handling functions use it to get an identifier for the failing record
+	SchemaAliases   []string // alternative names accepted for the field in input files
+	SchemaVarType   string   // type of the field in input files, when different from VarType
+	Reference       string   // kind of record (user, group, policy, proxy or resource) whose IDs the field holds; names are also accepted unless --strict-ids is passed
 	DefaultValue    interface{}
 }
 
@@ -57,6 +69,11 @@ type inputEntry struct {
 	CSVdata interface{}
 	JSON    json.RawMessage
 	Values  []interface{}
+	Record  int // 1-based position of the record in the input file
+	Line    int // line of the input file where the record starts, 0 if unknown
+	// record as read from the input file, for --failed-out:
+	// []string for CSV files, *yaml.Node for YAML files
+	Raw interface{}
 }
 
 type wholeObjectType int
@@ -79,9 +96,21 @@ func initInputFlags(cmd *cobra.Command, typeName string, fields ...inputField) {
 	cmd.Annotations[flagInitInput] = "yes"
 	typeName = pluralize(typeName)
 
-	cmd.Flags().StringP("from-file", "f", "", "file from where to import "+typeName)
-	cmd.Flags().StringP("file-format", "i", "json", "format for the file from where to import "+typeName+" (csv or json)")
+	cmd.Flags().StringP("from-file", "f", "", "file from where to import "+typeName+" (- to read from stdin)")
+	cmd.Flags().StringP("file-format", "i", "json", "format for the file from where to import "+typeName+" (csv, json, yaml or ndjson)")
+	cmd.Flags().String("column-map", "", "YAML file, or preset ("+strings.Join(inputColumnMapPresetNames(), ", ")+"), mapping the columns of a CSV input file to the expected ones")
 	cmd.Flags().Bool("errors-only", false, "only include failed operations in output")
+	cmd.Flags().Bool("validate-only", false, "check the input file for problems and report them, without performing any operations")
+	cmd.Flags().StringArray("var", []string{}, "set a variable (key=value) for ${key} references in the input file")
+	cmd.Flags().String("var-file", "", "file with variables (one key=value per line) for ${key} references in the input file")
+	cmd.Flags().String("print-template", "", "print a template for the input file in the given format (csv, json or yaml), without performing any operations")
+	cmd.Flags().String("failed-out", "", "file where to write the records of the input file that failed, in the same format, with an added "+inputErrorField+" field")
+	cmd.Flags().String("journal", "", "file where to record the outcome of each record of the input file, for use with --resume")
+	cmd.Flags().Bool("resume", false, "skip the records that the --journal file lists as applied by a previous run")
+
+	if inputHasReferences(fields) {
+		cmd.Flags().Bool("strict-ids", false, "only accept IDs, and not names, when referencing other records")
+	}
 
 	for _, field := range fields {
 		switch field.VarType {
@@ -124,10 +153,38 @@ func preRunFlagCheckInput(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	if !funk.Contains([]string{"json", "csv"}, input) {
+	formats := []string{"json", "csv", "yaml", "ndjson"}
+	if _, ok := cmd.Annotations[flagInitSCIMInput]; ok {
+		formats = append(formats, "scim")
+	}
+	if !funk.Contains(formats, input) {
 		return fmt.Errorf("invalid input file format %s", input)
 	}
 
+	columnMap, err := cmd.Flags().GetString("column-map")
+	if err != nil {
+		return err
+	}
+	if columnMap != "" && input != "csv" {
+		return fmt.Errorf("--column-map can only be used with --file-format=csv")
+	}
+
+	template, err := cmd.Flags().GetString("print-template")
+	if err != nil {
+		return err
+	}
+	if template != "" && !funk.Contains([]string{"json", "csv", "yaml"}, template) {
+		return fmt.Errorf("invalid template format %s", template)
+	}
+
+	resume, err := cmd.Flags().GetBool("resume")
+	if err != nil {
+		return err
+	}
+	if resume && inputJournalPath(cmd) == "" {
+		return fmt.Errorf("--resume requires a journal to be specified with --journal")
+	}
+
 	for _, field := range data.fields {
 		if field.Validator == nil {
 			continue
@@ -199,15 +256,50 @@ func forAllInput(cmd *cobra.Command,
 		panic("forAllInput called for command where input flags were not initialized. This is a bug!")
 	}
 	data := global.InputData[cmd]
+	data.validation = nil
+	data.journal = nil
 
 	if errorsOnly, err := cmd.Flags().GetBool("errors-only"); err == nil && errorsOnly {
 		printSuccess = nil
 	}
 
+	if inputHasReferences(data.fields) {
+		// replace references by name with IDs before each operation
+		resolver := newReferenceResolver(cmd)
+		doWithIDs := do
+		do = func(entry *inputEntry) (interface{}, error) {
+			resolved, err := resolver.resolveEntry(data.fields, entry)
+			if err != nil {
+				return nil, err
+			}
+			return doWithIDs(resolved)
+		}
+	}
+
+	if inputSkipExisting(cmd) {
+		do = skipExistingDo(cmd, do)
+	}
+
 	fromFile, err := cmd.Flags().GetString("from-file")
 	if err != nil {
 		return err
 	}
+	if inputPrintTemplate(cmd) != "" {
+		// the template is printed by printListOutputAndError
+		return nil
+	}
+	if inputValidateOnly(cmd) {
+		if fromFile == "" {
+			return fmt.Errorf("--validate-only requires an input file to be specified with --from-file")
+		}
+		return validateInputFile(cmd)
+	}
+	if fromFile == "" && inputJournalPath(cmd) != "" {
+		return fmt.Errorf("--journal requires an input file to be specified with --from-file")
+	}
+	if failedOut, _ := cmd.Flags().GetString("failed-out"); fromFile == "" && failedOut != "" {
+		return fmt.Errorf("--failed-out requires an input file to be specified with --from-file")
+	}
 	if fromFile != "" {
 		return forAllInputFromFile(cmd, do, printSuccess, doOnError)
 	}
@@ -235,7 +327,11 @@ func forAllInput(cmd *cobra.Command,
 			// if the value for the entry is not nil. so, we just leave it nil, to indicate this value is not provided
 			continue
 		} else {
-			d, err = getFlagValue(cmd, field.VarType, field.FlagName)
+			if field.Reference != "" {
+				d, err = getReferenceFlagValue(cmd, field)
+			} else {
+				d, err = getFlagValue(cmd, field.VarType, field.FlagName)
+			}
 			if err != nil {
 				if field.Mandatory {
 					return err
@@ -252,7 +348,7 @@ func forAllInput(cmd *cobra.Command,
 	} else if printSuccess != nil {
 		printSuccess(r)
 	}
-	if !loopControlContinueOnError(cmd) {
+	if !loopControlContinueOnError(cmd) && !isInputExistsError(err) {
 		return err
 	}
 	return nil
@@ -352,125 +448,433 @@ func forAllInputFromFile(cmd *cobra.Command,
 	do func(entry *inputEntry) (interface{}, error),
 	printSuccess func(interface{}),
 	doOnError func(error, interface{})) error {
-	inputFormat, err := cmd.Flags().GetString("file-format")
+	err := checkInputFileVariables(cmd)
 	if err != nil {
 		return err
 	}
-
-	inputFile, err := cmd.Flags().GetString("from-file")
+	interpolator, err := newInputInterpolator(cmd, true)
 	if err != nil {
 		return err
 	}
-
-	reader, err := os.Open(inputFile)
+	source, closer, err := openInputSource(cmd, interpolator)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	journal, err := openInputJournal(cmd)
 	if err != nil {
 		return err
 	}
-	defer reader.Close()
+	if journal != nil {
+		defer journal.Close()
+		global.InputData[cmd].journal = journal.summary
+	}
+	failed, err := newInputFailedRecords(cmd, source)
+	if err != nil {
+		return err
+	}
+	err = forAllInputEntries(cmd, source, journal, failed, do, printSuccess, doOnError)
+	if failed != nil && !global.DryRun {
+		if werr := failed.write(); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// inputSource produces the records read from an input file, one at a time.
+// next returns io.EOF when there are no more records
+type inputSource interface {
+	next() (*inputEntry, error)
+}
+
+// inputRecordError is returned by inputSource.next when a record could not be
+// read, but the source is able to continue with the following records
+type inputRecordError struct {
+	err   error
+	entry *inputEntry // what could be read of the record, for --failed-out
+}
+
+func (e *inputRecordError) Error() string {
+	return e.err.Error()
+}
+
+func (e *inputRecordError) Unwrap() error {
+	return e.err
+}
+
+// inputRecordErrorEntry returns what could be read of the record that failed
+// with e
+func inputRecordErrorEntry(e *inputRecordError) *inputEntry {
+	if e.entry == nil {
+		return &inputEntry{Type: wholeJSONObject}
+	}
+	return e.entry
+}
+
+func openInputSource(cmd *cobra.Command, interpolator *inputInterpolator) (inputSource, io.Closer, error) {
+	inputFormat, err := cmd.Flags().GetString("file-format")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inputFile, err := cmd.Flags().GetString("from-file")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reader io.ReadCloser
+	if inputFile == "-" {
+		reader = ioutil.NopCloser(os.Stdin)
+	} else {
+		reader, err = os.Open(inputFile)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
+	interpolated := interpolator.reader(reader)
+
+	var source inputSource
 	switch inputFormat {
 	case "json":
-		return forAllInputFromJSON(cmd, do, printSuccess, doOnError, reader)
+		source, err = newJSONInputSource(interpolated)
 	case "csv":
-		return forAllInputFromCSV(cmd, do, printSuccess, doOnError, reader)
+		var columnMap *inputColumnMap
+		columnMap, err = inputColumnMapFromFlag(cmd)
+		if err == nil {
+			source, err = newCSVInputSource(interpolated, columnMap)
+		}
+	case "yaml":
+		source = newYAMLInputSource(interpolated)
+	case "ndjson":
+		source = newNDJSONInputSource(interpolated)
+	case "scim":
+		schema, ok := cmd.Annotations[flagInitSCIMInput]
+		if !ok {
+			err = fmt.Errorf("invalid input file format %s", inputFormat)
+			break
+		}
+		source, err = newSCIMInputSource(interpolated, schema)
+	default:
+		err = fmt.Errorf("invalid input file format %s", inputFormat)
 	}
-	return nil
+	if err != nil {
+		reader.Close()
+		return nil, nil, err
+	}
+	return source, reader, nil
 }
 
-func forAllInputFromJSON(cmd *cobra.Command,
+func forAllInputEntries(cmd *cobra.Command,
+	source inputSource,
+	journal *inputJournal,
+	failed *inputFailedRecords,
 	do func(entry *inputEntry) (interface{}, error),
 	printSuccess func(interface{}),
-	doOnError func(error, interface{}),
-	reader io.Reader) error {
-	records := make([]json.RawMessage, 0)
+	doOnError func(error, interface{})) error {
+	var loopErr error
+	err := loopControlForEach(cmd,
+		func() (interface{}, error) {
+			for {
+				entry, err := source.next()
+				var recordErr *inputRecordError
+				if errors.As(err, &recordErr) {
+					// reported like a failed operation, so that the
+					// following records are still processed
+					return recordErr, nil
+				}
+				if err != nil || journal == nil || !journal.skip(entry) {
+					return entry, err
+				}
+			}
+		},
+		func(item interface{}) (interface{}, error) {
+			if recordErr, ok := item.(*inputRecordError); ok {
+				return nil, recordErr
+			}
+			return do(item.(*inputEntry))
+		},
+		func(item interface{}, res interface{}, err error) bool {
+			if isDryRunError(err) {
+				return true
+			}
+			entry, isEntry := item.(*inputEntry)
+			var id interface{}
+			if isEntry {
+				id = getIDinputValue(cmd, entry)
+			} else {
+				// the record could not be read, so it is not journaled
+				entry = inputRecordErrorEntry(item.(*inputRecordError))
+				id = fmt.Sprintf("record %d", entry.Record)
+			}
+			if journal != nil && isEntry {
+				if jerr := journal.record(entry, res, err); jerr != nil {
+					if loopErr == nil {
+						loopErr = jerr
+					}
+					return false
+				}
+			}
+			if isInputExistsError(err) {
+				// skipped with --skip-existing, which is not a failure
+				if doOnError != nil {
+					doOnError(err, id)
+				}
+				return true
+			}
+			if err != nil {
+				if failed != nil {
+					failed.add(entry, err)
+				}
+				if doOnError != nil {
+					doOnError(err, id)
+				}
+				if !loopControlContinueOnError(cmd) {
+					if loopErr == nil {
+						loopErr = err
+					}
+					return false
+				}
+			} else if printSuccess != nil {
+				printSuccess(res)
+			}
+			return true
+		})
+	if loopErr != nil {
+		return loopErr
+	}
+	return err
+}
 
-	err := json.NewDecoder(reader).Decode(&records)
+// jsonInputSource reads a JSON array of records, decoding one element at a time
+type jsonInputSource struct {
+	decoder *json.Decoder
+	record  int
+}
+
+func newJSONInputSource(reader io.Reader) (*jsonInputSource, error) {
+	decoder := json.NewDecoder(reader)
+	t, err := decoder.Token()
 	if err != nil {
-		return err
+		return nil, err
 	}
+	if delim, ok := t.(json.Delim); !ok || delim != '[' {
+		return nil, fmt.Errorf("expected a JSON array of records")
+	}
+	return &jsonInputSource{decoder: decoder}, nil
+}
 
-	for _, record := range records {
-		entry := &inputEntry{
-			Type: wholeJSONObject,
-			JSON: record,
+func (s *jsonInputSource) next() (*inputEntry, error) {
+	if !s.decoder.More() {
+		return nil, io.EOF
+	}
+	var record json.RawMessage
+	if err := s.decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+	s.record++
+	return &inputEntry{
+		Type:   wholeJSONObject,
+		JSON:   record,
+		Record: s.record,
+	}, nil
+}
+
+// ndjsonInputSource reads newline-delimited JSON, one record per line
+type ndjsonInputSource struct {
+	reader     *bufio.Reader
+	lineNumber int
+	record     int
+}
+
+func newNDJSONInputSource(reader io.Reader) *ndjsonInputSource {
+	return &ndjsonInputSource{reader: bufio.NewReader(reader)}
+}
+
+func (s *ndjsonInputSource) next() (*inputEntry, error) {
+	for {
+		line, err := s.reader.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
 		}
-		r, err := do(entry)
-		if err != nil {
-			if !loopControlContinueOnError(cmd) {
-				return err
+		s.lineNumber++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			// skip blank lines
+			continue
+		}
+		s.record++
+		if !json.Valid(line) {
+			return nil, &inputRecordError{
+				err: fmt.Errorf("line %d is not valid JSON", s.lineNumber),
+				entry: &inputEntry{
+					Type:   wholeJSONObject,
+					JSON:   json.RawMessage(line),
+					Record: s.record,
+					Line:   s.lineNumber,
+				},
 			}
-			if doOnError != nil {
-				doOnError(err, getIDinputValue(cmd, entry))
+		}
+		return &inputEntry{
+			Type:   wholeJSONObject,
+			JSON:   json.RawMessage(line),
+			Record: s.record,
+			Line:   s.lineNumber,
+		}, nil
+	}
+}
+
+// yamlInputSource reads YAML documents, which may contain either a list of
+// records or a single record each (as in multi-document streams)
+type yamlInputSource struct {
+	decoder *yaml.Decoder
+	pending []*yaml.Node
+	record  int
+}
+
+func newYAMLInputSource(reader io.Reader) *yamlInputSource {
+	return &yamlInputSource{decoder: yaml.NewDecoder(reader)}
+}
+
+func (s *yamlInputSource) next() (*inputEntry, error) {
+	for len(s.pending) == 0 {
+		var doc yaml.Node
+		err := s.decoder.Decode(&doc)
+		if err != nil {
+			return nil, err
+		}
+		if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+			continue
+		}
+		node := doc.Content[0]
+		switch node.Kind {
+		case yaml.SequenceNode:
+			s.pending = node.Content
+		case yaml.MappingNode:
+			s.pending = []*yaml.Node{node}
+		default:
+			if node.Tag == "!!null" {
+				// empty document
+				continue
 			}
-		} else if printSuccess != nil {
-			printSuccess(r)
+			return nil, fmt.Errorf("line %d: expected a record or a list of records", node.Line)
 		}
 	}
-	return nil
+
+	node := s.pending[0]
+	s.pending = s.pending[1:]
+	s.record++
+
+	failed := &inputEntry{
+		Type:   wholeJSONObject,
+		Record: s.record,
+		Line:   node.Line,
+		Raw:    node,
+	}
+	var record interface{}
+	if err := node.Decode(&record); err != nil {
+		return nil, &inputRecordError{err: err, entry: failed}
+	}
+	j, err := json.Marshal(record)
+	if err != nil {
+		return nil, &inputRecordError{err: fmt.Errorf("line %d: %w", node.Line, err), entry: failed}
+	}
+	return &inputEntry{
+		Type:   wholeJSONObject,
+		JSON:   j,
+		Record: s.record,
+		Line:   node.Line,
+		Raw:    node,
+	}, nil
 }
 
-func forAllInputFromCSV(cmd *cobra.Command,
-	do func(entry *inputEntry) (interface{}, error),
-	printSuccess func(interface{}),
-	doOnError func(error, interface{}),
-	reader io.Reader) error {
+type csvInputSource struct {
+	reader    *csv.Reader
+	header    []string
+	columnMap *inputColumnMap // nil if columns are not mapped
+	record    int
+}
+
+func newCSVInputSource(reader io.Reader, columnMap *inputColumnMap) (*csvInputSource, error) {
 	r := csv.NewReader(reader)
+	if columnMap != nil {
+		// foreign exports often have rows with missing trailing columns
+		r.FieldsPerRecord = -1
+	}
 
 	header, err := r.Read()
 	if err == io.EOF {
-		return fmt.Errorf("CSV file is missing header")
+		return nil, fmt.Errorf("CSV file is missing header")
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for lineNumber := 1; ; lineNumber++ {
-		record, err := r.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-		if len(record) != len(header) {
-			return fmt.Errorf("record %d is malformed", lineNumber)
+	for i := range header {
+		// special behavior for access resource port mappings, to keep compatibility with previous versions
+		if strings.ToLower(header[i]) == "ports" {
+			header[i] = "PortMappings"
 		}
+	}
 
-		m := make(map[string]interface{})
-		for i := range record {
-			// special behavior for access resource port mappings, to keep compatibility with previous versions
-			if strings.ToLower(header[i]) == "ports" {
-				header[i] = "PortMappings"
-			}
-			if strings.ToLower(header[i]) == "port_mappings" ||
-				strings.ToLower(header[i]) == "portmappings" {
-				mappings := strings.Split(strings.TrimRight(strings.TrimLeft(record[i], "["), "]"), ";")
+	return &csvInputSource{
+		reader:    r,
+		header:    header,
+		columnMap: columnMap,
+	}, nil
+}
 
-				m[header[i]] = funk.Map(mappings, func(row string) *models.AccessResourcePortMapping {
-					return colonMappingToPortMapping(row)
-				})
-			} else {
-				m[header[i]] = record[i]
+func (s *csvInputSource) next() (*inputEntry, error) {
+	record, err := s.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	s.record++
+	if err != nil {
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			return nil, &inputRecordError{
+				err: fmt.Errorf("record %d is malformed: %w", s.record, err),
+				entry: &inputEntry{
+					Type:    wholeCSVObject,
+					CSVdata: map[string]interface{}{},
+					Record:  s.record,
+					Line:    parseErr.StartLine,
+					Raw:     record,
+				},
 			}
 		}
+		return nil, err
+	}
+	line, _ := s.reader.FieldPos(0)
 
-		entry := &inputEntry{
-			Type:    wholeCSVObject,
-			CSVdata: m,
-		}
-		res, err := do(entry)
-		if err != nil {
-			if doOnError != nil {
-				doOnError(err, getIDinputValue(cmd, entry))
-			}
-			if !loopControlContinueOnError(cmd) {
-				return err
-			}
-		} else if printSuccess != nil {
-			printSuccess(res)
+	// Raw keeps the original record, which matches s.header for --failed-out
+	header, values := s.header, record
+	if s.columnMap != nil {
+		header, values = s.columnMap.apply(s.header, record)
+	}
+
+	m := make(map[string]interface{})
+	for i := range values {
+		if strings.ToLower(header[i]) == "port_mappings" ||
+			strings.ToLower(header[i]) == "portmappings" {
+			mappings := strings.Split(strings.TrimRight(strings.TrimLeft(values[i], "["), "]"), ";")
+
+			m[header[i]] = funk.Map(mappings, func(row string) *models.AccessResourcePortMapping {
+				return colonMappingToPortMapping(row)
+			})
+		} else {
+			m[header[i]] = values[i]
 		}
 	}
-	return nil
+
+	return &inputEntry{
+		Type:    wholeCSVObject,
+		CSVdata: unflattenCSVRecord(m),
+		Record:  s.record,
+		Line:    line,
+		Raw:     record,
+	}, nil
 }
 
 func placeInputValues(cmd *cobra.Command,
@@ -506,7 +910,7 @@ func placeInputValues(cmd *cobra.Command,
 		if err != nil {
 			return err
 		}
-		return decoder.Decode(entry.CSVdata)
+		return decoder.Decode(normalizeCSVKeys(entry.CSVdata))
 	}
 	return fmt.Errorf("unknown input entry type")
 }
@@ -516,6 +920,10 @@ func csvMapstructureDecodeHook(from reflect.Type, to reflect.Type, data interfac
 		return data, nil
 	}
 	s := data.(string)
+	if strings.Trim(s, "[] ") == "" {
+		// empty lists, or null values in flattened CSV output
+		return []string{}, nil
+	}
 	return commaSeparatedListToStringSlice(s), nil
 }
 
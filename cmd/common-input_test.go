@@ -0,0 +1,177 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func readAllInputSource(t *testing.T, source inputSource) []map[string]interface{} {
+	records := []map[string]interface{}{}
+	for {
+		entry, err := source.next()
+		if err == io.EOF {
+			return records
+		}
+		st.Assert(t, err, nil)
+		st.Expect(t, entry.Type, wholeJSONObject)
+		var m map[string]interface{}
+		st.Assert(t, json.Unmarshal(entry.JSON, &m), nil)
+		records = append(records, m)
+	}
+}
+
+func TestYAMLInputSource(t *testing.T) {
+	list := `
+- name: Alice
+  email: alice@example.com
+  groups:
+    - id: 1
+- name: Bob
+  email: bob@example.com
+`
+	records := readAllInputSource(t, newYAMLInputSource(strings.NewReader(list)))
+	st.Expect(t, len(records), 2)
+	st.Expect(t, records[0]["name"], "Alice")
+	st.Expect(t, records[1]["email"], "bob@example.com")
+
+	stream := `name: Alice
+---
+name: Bob
+---
+- name: Carol
+- name: Dave
+`
+	records = readAllInputSource(t, newYAMLInputSource(strings.NewReader(stream)))
+	st.Expect(t, len(records), 4)
+	st.Expect(t, records[3]["name"], "Dave")
+}
+
+func TestNDJSONInputSource(t *testing.T) {
+	input := `{"name": "Alice", "group_ids": [1, 2]}
+
+{"name": "Bob"}
+`
+	records := readAllInputSource(t, newNDJSONInputSource(strings.NewReader(input)))
+	st.Expect(t, len(records), 2)
+	st.Expect(t, records[0]["name"], "Alice")
+	st.Expect(t, records[1]["name"], "Bob")
+
+	_, err := newNDJSONInputSource(strings.NewReader("{\"name\": \"Alice\"\n")).next()
+	st.Reject(t, err, nil)
+}
+
+func TestValidateInputEntry(t *testing.T) {
+	fields := []inputField{
+		{Name: "Name", VarType: "string", Mandatory: true, SchemaName: "name"},
+		{Name: "Group IDs", VarType: "[]int", SchemaName: "group_ids", SchemaAliases: []string{"groups"}},
+		{Name: "Enabled", VarType: "bool", SchemaName: "enabled"},
+		{Name: "Port mappings", VarType: "[]string.skipcomma", SchemaName: "port_mappings",
+			SchemaVarType: "[]portmapping", Validator: validatePortMappings},
+	}
+
+	input := `{"name": "wiki", "groups": [{"id": 1}], "enabled": true, "port_mappings": ["80:8080:tcp"]}
+{"name": "", "group_ids": ["a"], "enabled": "yes", "extra": 1}
+{"name": "git", "port_mappings": ["70000:22", "22:22:icmp"]}
+`
+	source := newNDJSONInputSource(strings.NewReader(input))
+	report := &inputValidationReport{}
+	for {
+		entry, err := source.next()
+		if err == io.EOF {
+			break
+		}
+		st.Assert(t, err, nil)
+		validateInputEntry(fields, entry, true, report)
+	}
+
+	byRecord := make(map[int][]inputValidationProblem)
+	for _, p := range report.problems {
+		byRecord[p.record] = append(byRecord[p.record], p)
+	}
+	st.Expect(t, len(byRecord[1]), 0)
+	// missing name, wrong group ids, wrong enabled, unknown field
+	st.Expect(t, len(byRecord[2]), 4)
+	st.Expect(t, byRecord[2][0].line, 2)
+	st.Expect(t, len(byRecord[3]), 1)
+	st.Expect(t, report.errorCount(), 4)
+}
+
+func TestCheckPortMappings(t *testing.T) {
+	st.Expect(t, checkPortMappings([]string{"80:8080", "1000-1010:2000-2010:both"}), nil)
+	st.Reject(t, checkPortMappings([]string{"80:8080:icmp"}), nil)
+	st.Reject(t, checkPortMappings([]string{"0:80"}), nil)
+	st.Reject(t, checkPortMappings([]string{"100-90:80"}), nil)
+	st.Reject(t, checkPortMappings([]string{"80,443:8080"}), nil)
+	st.Expect(t, checkPortMappings([]interface{}{
+		map[string]interface{}{"public_ports": []interface{}{"443"}, "internal_ports": []interface{}{"8443"}, "protocol": "tcp"},
+	}), nil)
+}
+
+func TestInputInterpolator(t *testing.T) {
+	os.Setenv("ACCESS_CLI_TEST_PROXY", "proxy-id")
+	defer os.Unsetenv("ACCESS_CLI_TEST_PROXY")
+
+	interpolator := &inputInterpolator{vars: map[string]string{"POLICY": "5"}}
+	input := `[{"access_policy_ids": [${POLICY}], "access_proxy_id": "${env:ACCESS_CLI_TEST_PROXY}",
+"notes": "$${POLICY} ${UNKNOWN}"}]`
+	out, err := ioutil.ReadAll(interpolator.reader(strings.NewReader(input)))
+	st.Assert(t, err, nil)
+	st.Expect(t, string(out), `[{"access_policy_ids": [5], "access_proxy_id": "proxy-id",
+"notes": "${POLICY} ${UNKNOWN}"}]`)
+	st.Expect(t, interpolator.unresolved, []unresolvedInputVariable{{name: "UNKNOWN", line: 2}})
+
+	interpolator = &inputInterpolator{strict: true}
+	_, err = ioutil.ReadAll(interpolator.reader(strings.NewReader(input)))
+	st.Reject(t, err, nil)
+
+	// without --var or --var-file, a literal ${ is not an error
+	interpolator, err = newInputInterpolator(usersAddCmd, true)
+	st.Assert(t, err, nil)
+	out, err = ioutil.ReadAll(interpolator.reader(strings.NewReader(`[{"name": "${not a variable} $${kept}"}]`)))
+	st.Assert(t, err, nil)
+	st.Expect(t, string(out), `[{"name": "${not a variable} $${kept}"}]`)
+	st.Expect(t, len(interpolator.unresolved), 0)
+
+	// environment variables are always required
+	interpolator, err = newInputInterpolator(usersAddCmd, true)
+	st.Assert(t, err, nil)
+	_, err = ioutil.ReadAll(interpolator.reader(strings.NewReader(`[{"name": "${env:ACCESS_CLI_TEST_UNSET}"}]`)))
+	st.Reject(t, err, nil)
+}
+
+func TestInputInterpolatorEscape(t *testing.T) {
+	vars := map[string]string{"NAME": `Smith, "Al"`, "IDS": "1,2"}
+
+	interpolator := &inputInterpolator{vars: vars, format: "json"}
+	out, err := ioutil.ReadAll(interpolator.reader(strings.NewReader(`[{"name": "${NAME}", "ids": [${IDS}]}]`)))
+	st.Assert(t, err, nil)
+	st.Expect(t, string(out), `[{"name": "Smith, \"Al\"", "ids": [1,2]}]`)
+
+	interpolator = &inputInterpolator{vars: vars, format: "csv"}
+	out, err = ioutil.ReadAll(interpolator.reader(strings.NewReader("name,ids\n${NAME},\"${IDS}\"\n")))
+	st.Assert(t, err, nil)
+	st.Expect(t, string(out), "name,ids\n\"Smith, \"\"Al\"\"\",\"1,2\"\n")
+}
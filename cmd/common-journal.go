@@ -0,0 +1,250 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// inputJournalLine is a line of a journal file. The first line of every
+// journal identifies the command that wrote it; the following lines record
+// the outcome of each processed record
+type inputJournalLine struct {
+	Command string      `json:"command,omitempty"`
+	Record  int         `json:"record,omitempty"`
+	Hash    string      `json:"hash,omitempty"`
+	ID      interface{} `json:"id,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+type inputJournalSummary struct {
+	applied int
+	skipped int
+	failed  int
+}
+
+func (s *inputJournalSummary) String() string {
+	return fmt.Sprintf("%d applied, %d skipped, %d failed", s.applied, s.skipped, s.failed)
+}
+
+// inputJournal records the outcome of each record of an input file, so that
+// an interrupted run can be resumed without applying records twice
+type inputJournal struct {
+	file    *os.File
+	done    map[string]int // number of applied records with each hash
+	summary *inputJournalSummary
+}
+
+func inputJournalPath(cmd *cobra.Command) string {
+	if _, ok := cmd.Annotations[flagInitInput]; !ok {
+		return ""
+	}
+	path, err := cmd.Flags().GetString("journal")
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// openInputJournal opens the journal passed with --journal, if any.
+// With --resume, the records already applied are read from the existing
+// journal and new outcomes are appended to it; otherwise, the journal is
+// started anew. With --dry-run, the journal is only read
+func openInputJournal(cmd *cobra.Command) (*inputJournal, error) {
+	path := inputJournalPath(cmd)
+	if path == "" {
+		return nil, nil
+	}
+	resume, err := cmd.Flags().GetBool("resume")
+	if err != nil {
+		return nil, err
+	}
+
+	j := &inputJournal{
+		done:    make(map[string]int),
+		summary: &inputJournalSummary{},
+	}
+	exists := false
+	if resume {
+		exists, err = j.load(cmd, path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if global.DryRun {
+		return j, nil
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !resume {
+		flags |= os.O_TRUNC
+	}
+	j.file, err = os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = j.write(&inputJournalLine{Command: cmd.CommandPath()})
+		if err != nil {
+			j.file.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+func (j *inputJournal) load(cmd *cobra.Command, path string) (bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		var line inputJournalLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			if lineNumber > 1 {
+				// the last line may be incomplete if the previous run was
+				// interrupted while writing it
+				continue
+			}
+			return false, fmt.Errorf("%s is not a valid journal: %v", path, err)
+		}
+		if lineNumber == 1 {
+			if line.Command != cmd.CommandPath() {
+				return false, fmt.Errorf("journal %s was written by `%s`, not `%s`", path, line.Command, cmd.CommandPath())
+			}
+			continue
+		}
+		if line.Error == "" && line.Hash != "" {
+			j.done[line.Hash]++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+	return lineNumber > 0, nil
+}
+
+func (j *inputJournal) write(line *inputJournalLine) error {
+	if j.file == nil {
+		return nil
+	}
+	b, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	_, err = j.file.Write(append(b, '\n'))
+	return err
+}
+
+// skip returns whether entry was applied in a previous run.
+// Identical records are skipped as many times as they were applied
+func (j *inputJournal) skip(entry *inputEntry) bool {
+	hash := inputEntryHash(entry)
+	if j.done[hash] == 0 {
+		return false
+	}
+	j.done[hash]--
+	j.summary.skipped++
+	return true
+}
+
+// record writes the outcome of processing entry, which resulted in res and err
+func (j *inputJournal) record(entry *inputEntry, res interface{}, err error) error {
+	line := &inputJournalLine{
+		Record: entry.Record,
+		Hash:   inputEntryHash(entry),
+	}
+	if exists, ok := err.(*inputExistsError); ok {
+		// skipped with --skip-existing, the record was applied before
+		j.summary.applied++
+		line.ID = exists.id
+	} else if err != nil {
+		j.summary.failed++
+		line.Error = processErrorResponse(err).Error()
+	} else {
+		j.summary.applied++
+		line.ID = inputJournalResultID(res)
+	}
+	return j.write(line)
+}
+
+func (j *inputJournal) Close() error {
+	if j.file == nil {
+		return nil
+	}
+	return j.file.Close()
+}
+
+// inputEntryHash identifies the contents of a record, independently of the
+// formatting of the input file
+func inputEntryHash(entry *inputEntry) string {
+	var normalized interface{}
+	switch entry.Type {
+	case wholeJSONObject:
+		var object interface{}
+		if err := json.Unmarshal(entry.JSON, &object); err != nil {
+			normalized = string(entry.JSON)
+		} else {
+			normalized = object
+		}
+	case wholeCSVObject:
+		normalized = entry.CSVdata
+	default:
+		normalized = entry.Values
+	}
+	// encoding/json sorts map keys, so equal records always hash the same
+	b, err := json.Marshal(normalized)
+	if err != nil {
+		panic("inputEntryHash could not encode record. This is a bug!")
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// inputJournalResultID returns the ID of the record created or edited by an
+// operation, as found in its result
+func inputJournalResultID(res interface{}) interface{} {
+	b, err := json.Marshal(res)
+	if err != nil {
+		return nil
+	}
+	var object map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&object); err != nil {
+		return nil
+	}
+	return object["id"]
+}
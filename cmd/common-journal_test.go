@@ -0,0 +1,104 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestAddUsersJournalResume(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer func() {
+		usersAddCmd.Flags().Set("from-file", "")
+		usersAddCmd.Flags().Set("journal", "")
+		usersAddCmd.Flags().Set("resume", "false")
+	}()
+
+	inputFile := filepath.Join(dir, "users.ndjson")
+	journalFile := filepath.Join(dir, "users.journal")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(
+		`{"name": "Alice", "email": "alice@example.com"}
+{"name": "Bob", "email": "bob@example.com"}
+{"name": "Carol", "email": "carol@example.com"}
+`), 0600), nil)
+
+	run := func(resume bool) (string, error) {
+		cmd := rootCmd
+		out := new(bytes.Buffer)
+		errOut := new(bytes.Buffer)
+		cmd.SetOut(out)
+		cmd.SetErr(errOut)
+		cmd.SetArgs([]string{
+			"users",
+			"add",
+			"-o=json",
+			"--continue-on-error=false",
+			"--from-file=" + inputFile,
+			"--file-format=ndjson",
+			"--journal=" + journalFile,
+			"--resume=" + strconv.FormatBool(resume),
+		})
+		err := cmd.Execute()
+		return errOut.String(), err
+	}
+
+	// the first run stops when creating Bob fails
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 101, "name": "Alice", "email": "alice@example.com"})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(500)
+
+	stderr, err := run(false)
+	st.Expect(t, err != nil, true)
+	st.Expect(t, strings.Contains(stderr, "Journal: 1 applied, 0 skipped, 1 failed"), true)
+
+	journal, err := ioutil.ReadFile(journalFile)
+	st.Assert(t, err, nil)
+	lines := strings.Split(strings.TrimSpace(string(journal)), "\n")
+	st.Expect(t, len(lines), 3)
+	st.Expect(t, strings.Contains(lines[1], `"id":101`), true)
+	st.Expect(t, strings.Contains(lines[2], `"error"`), true)
+
+	// resuming skips Alice and creates the remaining users
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Times(2).
+		Reply(201).
+		JSON(map[string]interface{}{"id": 102, "name": "Bob", "email": "bob@example.com"})
+
+	stderr, err = run(true)
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	st.Expect(t, strings.Contains(stderr, "Journal: 2 applied, 1 skipped, 0 failed"), true)
+}
@@ -0,0 +1,213 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"github.com/spf13/cobra"
+
+	apipolicies "github.com/barracuda-cloudgen-access/access-cli/client/access_policies"
+	apiproxies "github.com/barracuda-cloudgen-access/access-cli/client/access_proxies"
+	apiresources "github.com/barracuda-cloudgen-access/access-cli/client/access_resources"
+	apiadmins "github.com/barracuda-cloudgen-access/access-cli/client/admins"
+	apisources "github.com/barracuda-cloudgen-access/access-cli/client/asset_sources"
+	apiassets "github.com/barracuda-cloudgen-access/access-cli/client/assets"
+	apidevices "github.com/barracuda-cloudgen-access/access-cli/client/devices"
+	apigroups "github.com/barracuda-cloudgen-access/access-cli/client/groups"
+	apitenants "github.com/barracuda-cloudgen-access/access-cli/client/tenants"
+	apiusers "github.com/barracuda-cloudgen-access/access-cli/client/users"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// The listAll functions fetch every page of a listing, in the tenant of cmd.
+// When query is not empty, only the records matching the search query are
+// returned, which may include records that are not an exact match
+
+func listAllUsers(cmd *cobra.Command, query string) ([]*apiusers.ListUsersOKBodyItems0, error) {
+	params := apiusers.NewListUsersParams()
+	setTenant(cmd, params)
+	if query != "" {
+		params.SetQ(&query)
+	}
+	items := []*apiusers.ListUsersOKBodyItems0{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.Users.ListUsers(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+func listAllAdmins(cmd *cobra.Command, query string) ([]*models.Admin, error) {
+	params := apiadmins.NewListAdminsParams()
+	setTenant(cmd, params)
+	if query != "" {
+		params.SetQ(&query)
+	}
+	items := []*models.Admin{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.Admins.ListAdmins(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+func listAllGroups(cmd *cobra.Command, query string) ([]*apigroups.ListGroupsOKBodyItems0, error) {
+	params := apigroups.NewListGroupsParams()
+	setTenant(cmd, params)
+	if query != "" {
+		params.SetQ(&query)
+	}
+	items := []*apigroups.ListGroupsOKBodyItems0{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.Groups.ListGroups(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+func listAllPolicies(cmd *cobra.Command, query string) ([]*apipolicies.ListPoliciesOKBodyItems0, error) {
+	params := apipolicies.NewListPoliciesParams()
+	setTenant(cmd, params)
+	if query != "" {
+		params.SetQ(&query)
+	}
+	items := []*apipolicies.ListPoliciesOKBodyItems0{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.AccessPolicies.ListPolicies(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+func listAllProxies(cmd *cobra.Command, query string) ([]*apiproxies.ListProxiesOKBodyItems0, error) {
+	params := apiproxies.NewListProxiesParams()
+	setTenant(cmd, params)
+	if query != "" {
+		params.SetQ(&query)
+	}
+	items := []*apiproxies.ListProxiesOKBodyItems0{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.AccessProxies.ListProxies(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+func listAllResources(cmd *cobra.Command, query string) ([]*models.AccessResource, error) {
+	params := apiresources.NewListResourcesParams()
+	setTenant(cmd, params)
+	if query != "" {
+		params.SetQ(&query)
+	}
+	items := []*models.AccessResource{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.AccessResources.ListResources(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+// listAllAssets lists the assets of the given category, or all assets if
+// category is empty. Assets can not be searched
+func listAllAssets(cmd *cobra.Command, category string) ([]*models.Asset, error) {
+	params := apiassets.NewListAssetsParams()
+	setTenant(cmd, params)
+	if category != "" {
+		params.SetCategory(&category)
+	}
+	items := []*models.Asset{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.Assets.ListAssets(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+// listAllDevices lists all devices. Devices can not be searched
+func listAllDevices(cmd *cobra.Command) ([]*apidevices.ListDevicesOKBodyItems0, error) {
+	params := apidevices.NewListDevicesParams()
+	setTenant(cmd, params)
+	items := []*apidevices.ListDevicesOKBodyItems0{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.Devices.ListDevices(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+// listAllSources lists all asset sources. Asset sources can not be searched
+func listAllSources(cmd *cobra.Command) ([]*models.AssetSource, error) {
+	params := apisources.NewListAssetSourcesParams()
+	setTenant(cmd, params)
+	items := []*models.AssetSource{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.AssetSources.ListAssetSources(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
+
+// listAllTenants lists the tenants the current credentials have access to
+func listAllTenants(cmd *cobra.Command) ([]*apitenants.ListTenantsOKBodyItems0, error) {
+	params := apitenants.NewListTenantsParams()
+	items := []*apitenants.ListTenantsOKBodyItems0{}
+	err := listAllPages(params, func() (int, int64, error) {
+		resp, err := global.Client.Tenants.ListTenants(params, global.AuthWriter)
+		if err != nil {
+			return 0, 0, err
+		}
+		items = append(items, resp.Payload...)
+		return len(resp.Payload), resp.Total, nil
+	})
+	return items, err
+}
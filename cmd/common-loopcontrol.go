@@ -17,7 +17,12 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
 
 func initLoopControlFlags(cmd *cobra.Command) {
 	cmd.Flags().SortFlags = false
@@ -28,7 +33,27 @@ func initLoopControlFlags(cmd *cobra.Command) {
 	cmd.Flags().Bool("continue-on-error", false, "whether to continue if the operation fails for one of the arguments")
 }
 
+// initParallelFlags adds the --parallel flag to commands whose operations
+// can run concurrently. It must be called after initLoopControlFlags
+func initParallelFlags(cmd *cobra.Command) {
+	if _, ok := cmd.Annotations[flagInitLoopControl]; !ok {
+		panic("initParallelFlags called for command where looping flag was not initialized. This is a bug!")
+	}
+	cmd.Annotations[flagInitParallel] = "yes"
+	cmd.Flags().Int("parallel", 1, "number of operations to perform concurrently")
+}
+
 func preRunFlagCheckLoopControl(cmd *cobra.Command, args []string) error {
+	if _, ok := cmd.Annotations[flagInitParallel]; !ok {
+		return nil
+	}
+	parallel, err := cmd.Flags().GetInt("parallel")
+	if err != nil {
+		return err
+	}
+	if parallel < 1 {
+		return fmt.Errorf("invalid value for --parallel, must be at least 1")
+	}
 	return nil
 }
 
@@ -42,3 +67,124 @@ func loopControlContinueOnError(cmd *cobra.Command) bool {
 	}
 	return false
 }
+
+// loopControlParallelism returns the number of operations that may run concurrently
+func loopControlParallelism(cmd *cobra.Command) int {
+	if _, ok := cmd.Annotations[flagInitParallel]; !ok || global.DryRun {
+		// with --dry-run, requests are listed in the order they are made,
+		// which must match the input order
+		return 1
+	}
+	parallel, err := cmd.Flags().GetInt("parallel")
+	if err != nil || parallel < 1 {
+		return 1
+	}
+	return parallel
+}
+
+// loopControlForEach calls do for every item produced by next, which returns
+// io.EOF when there are no more items. With --parallel, up to that many calls
+// to do run concurrently. done is called with the result for each item, in
+// the order the items were produced, regardless of the order in which they
+// complete. Once done returns false, no further items are started, but those
+// already started are still passed to done.
+// next and done are always called from a single goroutine each, but do must
+// be safe for concurrent use
+func loopControlForEach(cmd *cobra.Command,
+	next func() (interface{}, error),
+	do func(item interface{}) (interface{}, error),
+	done func(item interface{}, result interface{}, err error) bool) error {
+	workers := loopControlParallelism(cmd)
+	if workers == 1 {
+		for {
+			item, err := next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			result, err := do(item)
+			if !done(item, result, err) {
+				return nil
+			}
+		}
+	}
+
+	type job struct {
+		item     interface{}
+		result   interface{}
+		err      error
+		finished chan struct{}
+	}
+	jobs := make(chan *job, workers)
+	slots := make(chan struct{}, workers)
+	stop := make(chan struct{})
+	var nextErr error
+
+	go func() {
+		defer close(jobs)
+		for {
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			item, err := next()
+			if err != nil {
+				if err != io.EOF {
+					nextErr = err
+				}
+				return
+			}
+			j := &job{
+				item:     item,
+				finished: make(chan struct{}),
+			}
+			go func() {
+				j.result, j.err = do(j.item)
+				<-slots
+				close(j.finished)
+			}()
+			jobs <- j
+		}
+	}()
+
+	stopped := false
+	for j := range jobs {
+		<-j.finished
+		if !done(j.item, j.result, j.err) && !stopped {
+			stopped = true
+			close(stop)
+		}
+	}
+	return nextErr
+}
+
+// loopControlForEachArg is like loopControlForEach, for the n arguments of a
+// multiple-operation command, which are identified by their index
+func loopControlForEachArg(cmd *cobra.Command, n int,
+	do func(i int) error,
+	done func(i int, err error) bool) {
+	i := 0
+	loopControlForEach(cmd,
+		func() (interface{}, error) {
+			if i >= n {
+				return nil, io.EOF
+			}
+			i++
+			return i - 1, nil
+		},
+		func(item interface{}) (interface{}, error) {
+			return nil, do(item.(int))
+		},
+		func(item interface{}, _ interface{}, err error) bool {
+			return done(item.(int), err)
+		})
+}
@@ -0,0 +1,62 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+	"github.com/spf13/cobra"
+)
+
+func TestLoopControlForEachArgParallel(t *testing.T) {
+	cmd := &cobra.Command{}
+	initLoopControlFlags(cmd)
+	initParallelFlags(cmd)
+	st.Assert(t, cmd.Flags().Set("parallel", "4"), nil)
+
+	// later arguments finish first, results must still come out in order
+	n := 8
+	results := []int{}
+	loopControlForEachArg(cmd, n, func(i int) error {
+		time.Sleep(time.Duration(n-i) * time.Millisecond)
+		return nil
+	}, func(i int, err error) bool {
+		st.Expect(t, err, nil)
+		results = append(results, i)
+		return true
+	})
+	st.Expect(t, results, []int{0, 1, 2, 3, 4, 5, 6, 7})
+
+	// after a failure, no new arguments are started, but those already
+	// running are still reported, in order
+	results = []int{}
+	loopControlForEachArg(cmd, 100, func(i int) error {
+		if i == 2 {
+			return fmt.Errorf("failed")
+		}
+		return nil
+	}, func(i int, err error) bool {
+		results = append(results, i)
+		return err == nil
+	})
+	st.Expect(t, results[:3], []int{0, 1, 2})
+	st.Assert(t, len(results) < 100, true)
+}
@@ -0,0 +1,799 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/go-openapi/strfmt"
+
+	apipolicies "github.com/barracuda-cloudgen-access/access-cli/client/access_policies"
+	apiproxies "github.com/barracuda-cloudgen-access/access-cli/client/access_proxies"
+	apiresources "github.com/barracuda-cloudgen-access/access-cli/client/access_resources"
+	apiassets "github.com/barracuda-cloudgen-access/access-cli/client/assets"
+	apigroups "github.com/barracuda-cloudgen-access/access-cli/client/groups"
+	apiagentconfiguration "github.com/barracuda-cloudgen-access/access-cli/client/settings_agent_configuration"
+	apianalytics "github.com/barracuda-cloudgen-access/access-cli/client/settings_analytics"
+	apienrollment "github.com/barracuda-cloudgen-access/access-cli/client/settings_enrollment"
+	apiwebpolicies "github.com/barracuda-cloudgen-access/access-cli/client/web_policies"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+	"github.com/barracuda-cloudgen-access/access-cli/serial"
+)
+
+func init() {
+	manifestKinds = []*manifestKind{
+		manifestSettingsKind,
+		manifestProxyKind,
+		manifestGroupKind,
+		manifestResourceKind,
+		manifestPolicyKind,
+		manifestWebPolicyKind,
+		manifestDomainKind,
+	}
+}
+
+// accessors for the values of manifest objects, which are only missing
+// when a field is not managed by the manifests
+
+func manifestValueString(values map[string]interface{}, key string) string {
+	s, _ := values[key].(string)
+	return s
+}
+
+func manifestValueBool(values map[string]interface{}, key string) bool {
+	b, _ := values[key].(bool)
+	return b
+}
+
+func manifestValueStrings(values map[string]interface{}, key string) []string {
+	s, _ := values[key].([]string)
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func manifestValueInt64s(values map[string]interface{}, key string) []int64 {
+	ints, _ := values[key].([]int64)
+	if ints == nil {
+		return []int64{}
+	}
+	return ints
+}
+
+// manifestIDsInt64 returns the integer IDs of a reference set. IDs of
+// objects that were not created (with --dry-run) are left out
+func manifestIDsInt64(values map[string]interface{}, key string) []int64 {
+	ids := []int64{}
+	list, _ := values[key].([]interface{})
+	for _, id := range list {
+		if i, ok := id.(int64); ok {
+			ids = append(ids, i)
+		}
+	}
+	return ids
+}
+
+// manifestIDUUID returns the UUID of a reference. Objects that were not
+// created (with --dry-run) keep their placeholder, so that it shows in the
+// requests that would be sent
+func manifestIDUUID(id interface{}) (strfmt.UUID, bool) {
+	switch u := id.(type) {
+	case strfmt.UUID:
+		return u, true
+	case manifestPlaceholderID:
+		return strfmt.UUID(u), true
+	}
+	return "", false
+}
+
+func manifestValueUUID(values map[string]interface{}, key string) strfmt.UUID {
+	u, _ := manifestIDUUID(values[key])
+	return u
+}
+
+// manifestIDsUUID returns the UUIDs of a reference set
+func manifestIDsUUID(values map[string]interface{}, key string) []strfmt.UUID {
+	ids := []strfmt.UUID{}
+	list, _ := values[key].([]interface{})
+	for _, id := range list {
+		if u, ok := manifestIDUUID(id); ok {
+			ids = append(ids, u)
+		}
+	}
+	return ids
+}
+
+func manifestNullableInt(values map[string]interface{}, key string) *serial.NullableOptionalInt {
+	n := &serial.NullableOptionalInt{}
+	if i, ok := values[key].(int64); ok {
+		n.Value = &i
+	}
+	return n
+}
+
+var manifestGroupKind = &manifestKind{
+	name:      "group",
+	key:       "groups",
+	nameField: "name",
+	fields: []manifestField{
+		{name: "description", typ: manifestString},
+		{name: "color", typ: manifestStringFold},
+	},
+	fetch: func(s *manifestState) ([]*manifestObject, error) {
+		items, err := listAllGroups(s.cmd, "")
+		if err != nil {
+			return nil, err
+		}
+		objects := []*manifestObject{}
+		for _, item := range items {
+			objects = append(objects, &manifestObject{
+				name: item.Name,
+				id:   item.ID,
+				values: map[string]interface{}{
+					"description": item.Description,
+					"color":       item.Color,
+				},
+			})
+		}
+		return objects, nil
+	},
+	create: func(s *manifestState, name string, values map[string]interface{}) (interface{}, error) {
+		params := apigroups.NewCreateGroupParams()
+		setTenant(s.cmd, params)
+		params.SetGroup(apigroups.CreateGroupBody{Group: &apigroups.CreateGroupParamsBodyGroup{
+			Name:        name,
+			Description: manifestValueString(values, "description"),
+			Color:       manifestValueString(values, "color"),
+		}})
+		resp, err := global.Client.Groups.CreateGroup(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Payload.ID, nil
+	},
+	update: func(s *manifestState, live *manifestObject, values map[string]interface{}) error {
+		params := apigroups.NewEditGroupParams()
+		setTenant(s.cmd, params)
+		params.SetID(live.id.(int64))
+		params.SetGroup(apigroups.EditGroupBody{Group: &models.Group{
+			Name:        live.name,
+			Description: manifestValueString(values, "description"),
+			Color:       manifestValueString(values, "color"),
+		}})
+		_, err := global.Client.Groups.EditGroup(params, global.AuthWriter)
+		return err
+	},
+	delete: func(s *manifestState, live *manifestObject) error {
+		params := apigroups.NewDeleteGroupParams()
+		setTenant(s.cmd, params)
+		params.SetID([]int64{live.id.(int64)})
+		_, err := global.Client.Groups.DeleteGroup(params, global.AuthWriter)
+		return err
+	},
+}
+
+var manifestProxyKind = &manifestKind{
+	name:      "proxy",
+	key:       "proxies",
+	nameField: "name",
+	fields: []manifestField{
+		{name: "location", typ: manifestString},
+		{name: "host", typ: manifestStringFold},
+		{name: "port", typ: manifestInt},
+	},
+	fetch: func(s *manifestState) ([]*manifestObject, error) {
+		items, err := listAllProxies(s.cmd, "")
+		if err != nil {
+			return nil, err
+		}
+		objects := []*manifestObject{}
+		for _, item := range items {
+			objects = append(objects, &manifestObject{
+				name: item.Name,
+				id:   item.ID,
+				values: map[string]interface{}{
+					"location": item.Location,
+					"host":     item.Host,
+					"port":     item.Port,
+				},
+			})
+		}
+		return objects, nil
+	},
+	create: func(s *manifestState, name string, values map[string]interface{}) (interface{}, error) {
+		params := apiproxies.NewCreateProxyParams()
+		setTenant(s.cmd, params)
+		port, _ := values["port"].(int64)
+		params.SetProxy(apiproxies.CreateProxyBody{
+			Name:     name,
+			Location: manifestValueString(values, "location"),
+			Host:     manifestValueString(values, "host"),
+			Port:     port,
+		})
+		resp, err := global.Client.AccessProxies.CreateProxy(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Payload.ID, nil
+	},
+	update: func(s *manifestState, live *manifestObject, values map[string]interface{}) error {
+		params := apiproxies.NewEditProxyParams()
+		setTenant(s.cmd, params)
+		params.SetID(live.id.(strfmt.UUID))
+		port, _ := values["port"].(int64)
+		body := apiproxies.EditProxyBody{}
+		body.AccessProxy.AccessProxy = models.AccessProxy{
+			Name:     live.name,
+			Location: manifestValueString(values, "location"),
+			Host:     manifestValueString(values, "host"),
+			Port:     port,
+		}
+		params.SetProxy(body)
+		_, err := global.Client.AccessProxies.EditProxy(params, global.AuthWriter)
+		return err
+	},
+	delete: func(s *manifestState, live *manifestObject) error {
+		params := apiproxies.NewDeleteProxyParams()
+		setTenant(s.cmd, params)
+		params.SetID([]strfmt.UUID{live.id.(strfmt.UUID)})
+		_, err := global.Client.AccessProxies.DeleteProxy(params, global.AuthWriter)
+		return err
+	},
+}
+
+// manifestResourceExtra is the live data of resources that is not managed
+// by the manifests, but must be sent when editing them
+type manifestResourceExtra struct {
+	policyIDs []int64
+	enabled   bool
+}
+
+var manifestResourceKind = &manifestKind{
+	name:      "resource",
+	key:       "resources",
+	nameField: "name",
+	fields: []manifestField{
+		{name: "public_host", typ: manifestStringFold},
+		{name: "internal_host", typ: manifestStringFold},
+		{name: "ports", typ: manifestPorts},
+		{name: "proxy", typ: manifestRef, ref: "proxy"},
+		{name: "wildcard_exceptions", typ: manifestStrings},
+		{name: "notes", typ: manifestString},
+		{name: "fixed_last_octet", typ: manifestInt},
+	},
+	depends: []string{"proxy"},
+	fetch: func(s *manifestState) ([]*manifestObject, error) {
+		items, err := listAllResources(s.cmd, "")
+		if err != nil {
+			return nil, err
+		}
+		objects := []*manifestObject{}
+		s.resourcePolicies = make(map[string][]interface{})
+		for _, item := range items {
+			extra := &manifestResourceExtra{
+				policyIDs: []int64{},
+				enabled:   item.Enabled,
+			}
+			for _, policy := range item.AccessPolicies {
+				extra.policyIDs = append(extra.policyIDs, policy.ID)
+				key := strconv.FormatInt(policy.ID, 10)
+				s.resourcePolicies[key] = append(s.resourcePolicies[key], item.ID)
+			}
+			var proxy interface{}
+			if item.AccessProxy != nil {
+				proxy = item.AccessProxy.ID
+			}
+			var fixedLastOctet interface{}
+			if item.FixedLastOctet != nil && item.FixedLastOctet.Value != nil {
+				fixedLastOctet = *item.FixedLastOctet.Value
+			}
+			wildcardExceptions := item.WildcardExceptions
+			if wildcardExceptions == nil {
+				wildcardExceptions = []string{}
+			}
+			objects = append(objects, &manifestObject{
+				name:  item.Name,
+				id:    item.ID,
+				extra: extra,
+				values: map[string]interface{}{
+					"public_host":         item.PublicHost,
+					"internal_host":       item.InternalHost,
+					"ports":               portMappingStrings(item.PortMappings),
+					"proxy":               proxy,
+					"wildcard_exceptions": wildcardExceptions,
+					"notes":               stringPointerValue(item.Notes),
+					"fixed_last_octet":    fixedLastOctet,
+				},
+			})
+		}
+		return objects, nil
+	},
+	create: func(s *manifestState, name string, values map[string]interface{}) (interface{}, error) {
+		missing := []string{}
+		for _, field := range []string{"public_host", "internal_host", "ports", "proxy"} {
+			if v, ok := values[field]; !ok || v == nil || v == "" {
+				missing = append(missing, field)
+			}
+		}
+		if err := applyMissingFields("resource", missing); err != nil {
+			return nil, err
+		}
+		params := apiresources.NewCreateResourceParams()
+		setTenant(s.cmd, params)
+		params.SetResource(apiresources.CreateResourceBody{AccessResource: &apiresources.CreateResourceParamsBodyAccessResource{
+			Name:               name,
+			PublicHost:         manifestValueString(values, "public_host"),
+			InternalHost:       manifestValueString(values, "internal_host"),
+			PortMappings:       manifestPortMappings(values),
+			AccessProxyID:      manifestValueUUID(values, "proxy"),
+			WildcardExceptions: manifestValueStrings(values, "wildcard_exceptions"),
+			Notes:              manifestValueString(values, "notes"),
+			FixedLastOctet:     manifestNullableInt(values, "fixed_last_octet"),
+			Enabled:            true,
+		}})
+		resp, err := global.Client.AccessResources.CreateResource(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Payload.ID, nil
+	},
+	update: func(s *manifestState, live *manifestObject, values map[string]interface{}) error {
+		params := apiresources.NewEditResourceParams()
+		setTenant(s.cmd, params)
+		params.SetID(live.id.(strfmt.UUID))
+		body := apiresources.EditResourceBody{}
+		edited := &body.AccessResource
+		extra := live.extra.(*manifestResourceExtra)
+		notes := manifestValueString(values, "notes")
+		edited.Name = live.name
+		edited.PublicHost = manifestValueString(values, "public_host")
+		edited.InternalHost = manifestValueString(values, "internal_host")
+		edited.PortMappings = manifestPortMappings(values)
+		edited.WildcardExceptions = manifestValueStrings(values, "wildcard_exceptions")
+		edited.Notes = &notes
+		edited.FixedLastOctet = manifestNullableInt(values, "fixed_last_octet")
+		edited.Enabled = extra.enabled
+		edited.AccessProxyID = manifestValueUUID(values, "proxy")
+		edited.AccessPolicyIds = extra.policyIDs
+		params.SetResource(body)
+		_, err := global.Client.AccessResources.EditResource(params, global.AuthWriter)
+		return err
+	},
+	delete: func(s *manifestState, live *manifestObject) error {
+		params := apiresources.NewDeleteResourceParams()
+		setTenant(s.cmd, params)
+		params.SetID([]strfmt.UUID{live.id.(strfmt.UUID)})
+		_, err := global.Client.AccessResources.DeleteResource(params, global.AuthWriter)
+		return err
+	},
+}
+
+func manifestPortMappings(values map[string]interface{}) []*models.AccessResourcePortMapping {
+	mappings := []*models.AccessResourcePortMapping{}
+	for _, mapping := range manifestValueStrings(values, "ports") {
+		mappings = append(mappings, colonMappingToPortMapping(mapping))
+	}
+	return mappings
+}
+
+var manifestPolicyKind = &manifestKind{
+	name:      "policy",
+	key:       "policies",
+	nameField: "name",
+	fields: []manifestField{
+		{name: "resources", typ: manifestRefs, ref: "resource"},
+		{name: "groups", typ: manifestRefs, ref: "group"},
+		{name: "users", typ: manifestRefs, ref: "user"},
+		{name: "rbac", typ: manifestBool},
+	},
+	depends: []string{"resource", "group"},
+	fetch: func(s *manifestState) ([]*manifestObject, error) {
+		items, err := listAllPolicies(s.cmd, "")
+		if err != nil {
+			return nil, err
+		}
+		objects := []*manifestObject{}
+		for _, item := range items {
+			rbac := false
+			groups := []interface{}{}
+			users := []interface{}{}
+			if item.Conditions != nil && item.Conditions.Rbac != nil {
+				r := item.Conditions.Rbac
+				rbac = r.Enabled != nil && *r.Enabled
+				for _, id := range r.GroupIds {
+					groups = append(groups, id)
+				}
+				for _, id := range r.UserIds {
+					users = append(users, id)
+				}
+			}
+			// the resources of policies are only listed in the resources
+			resources := s.resourcePolicies[strconv.FormatInt(item.ID, 10)]
+			if resources == nil {
+				resources = []interface{}{}
+			}
+			objects = append(objects, &manifestObject{
+				name: item.Name,
+				id:   item.ID,
+				values: map[string]interface{}{
+					"resources": resources,
+					"groups":    groups,
+					"users":     users,
+					"rbac":      rbac,
+				},
+			})
+		}
+		return objects, nil
+	},
+	create: func(s *manifestState, name string, values map[string]interface{}) (interface{}, error) {
+		params := apipolicies.NewCreatePolicyParams()
+		setTenant(s.cmd, params)
+		rbac := manifestPolicyRBAC(values)
+		params.SetPolicy(apipolicies.CreatePolicyBody{AccessPolicy: &apipolicies.CreatePolicyParamsBodyAccessPolicy{
+			Name:              name,
+			AccessResourceIds: manifestIDsUUID(values, "resources"),
+			Conditions: &apipolicies.CreatePolicyParamsBodyAccessPolicyConditions{
+				Rbac: &apipolicies.CreatePolicyParamsBodyAccessPolicyConditionsRbac{
+					Enabled:  &rbac,
+					GroupIds: manifestIDsInt64(values, "groups"),
+					UserIds:  manifestIDsInt64(values, "users"),
+				},
+			},
+		}})
+		resp, err := global.Client.AccessPolicies.CreatePolicy(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Payload.ID, nil
+	},
+	update: func(s *manifestState, live *manifestObject, values map[string]interface{}) error {
+		params := apipolicies.NewEditPolicyParams()
+		setTenant(s.cmd, params)
+		params.SetID(live.id.(int64))
+		rbac := manifestPolicyRBAC(values)
+		params.SetPolicy(apipolicies.EditPolicyBody{AccessPolicy: &apipolicies.EditPolicyParamsBodyAccessPolicy{
+			Name:              live.name,
+			AccessResourceIds: manifestIDsUUID(values, "resources"),
+			Conditions: &apipolicies.EditPolicyParamsBodyAccessPolicyConditions{
+				Rbac: &apipolicies.EditPolicyParamsBodyAccessPolicyConditionsRbac{
+					Enabled:  &rbac,
+					GroupIds: manifestIDsInt64(values, "groups"),
+					UserIds:  manifestIDsInt64(values, "users"),
+				},
+			},
+		}})
+		_, err := global.Client.AccessPolicies.EditPolicy(params, global.AuthWriter)
+		return err
+	},
+	delete: func(s *manifestState, live *manifestObject) error {
+		params := apipolicies.NewDeletePolicyParams()
+		setTenant(s.cmd, params)
+		params.SetID([]int64{live.id.(int64)})
+		_, err := global.Client.AccessPolicies.DeletePolicy(params, global.AuthWriter)
+		return err
+	},
+}
+
+// manifestPolicyRBAC returns whether RBAC is enabled for a policy, which
+// is the case when it is not specified but groups or users are
+func manifestPolicyRBAC(values map[string]interface{}) bool {
+	if rbac, ok := values["rbac"].(bool); ok {
+		return rbac
+	}
+	groups, _ := values["groups"].([]interface{})
+	users, _ := values["users"].([]interface{})
+	return len(groups) > 0 || len(users) > 0
+}
+
+var manifestWebPolicyKind = &manifestKind{
+	name:      "webpolicy",
+	key:       "webpolicies",
+	nameField: "label",
+	fields: []manifestField{
+		{name: "action", typ: manifestStringFold},
+		{name: "type", typ: manifestStringFold},
+		{name: "domains", typ: manifestStrings},
+		{name: "categories", typ: manifestInts},
+		{name: "groups", typ: manifestRefs, ref: "group"},
+		{name: "users", typ: manifestRefs, ref: "user"},
+		{name: "disabled", typ: manifestBool},
+		{name: "log", typ: manifestBool},
+		{name: "notify", typ: manifestBool},
+		{name: "alert", typ: manifestBool},
+	},
+	depends: []string{"group"},
+	fetch: func(s *manifestState) ([]*manifestObject, error) {
+		params := apiwebpolicies.NewListWebPoliciesParams()
+		setTenant(s.cmd, params)
+		resp, err := global.Client.WebPolicies.ListWebPolicies(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		s.webPolicyRulesetID = resp.Payload.ID
+		objects := []*manifestObject{}
+		// each web policy is the single rule of the ruleset of a jump rule of
+		// the main ruleset, and the users and groups are those of the jump rule
+		for _, jump := range resp.Payload.Rules {
+			if jump.Type != "jump" || jump.RulesetJump == nil || len(jump.RulesetJump.Rules) < 1 {
+				continue
+			}
+			rule := jump.RulesetJump.Rules[0]
+			groups := []interface{}{}
+			for _, group := range jump.Groups {
+				groups = append(groups, group.ID)
+			}
+			users := []interface{}{}
+			for _, user := range jump.Users {
+				users = append(users, user.ID)
+			}
+			domains := rule.Domains
+			if domains == nil {
+				domains = []string{}
+			}
+			categories := rule.Categories
+			if categories == nil {
+				categories = []int64{}
+			}
+			objects = append(objects, &manifestObject{
+				name:  rule.Label,
+				id:    rule.ID,
+				extra: jump.ID,
+				values: map[string]interface{}{
+					"action":     rule.Action,
+					"type":       rule.Type,
+					"domains":    domains,
+					"categories": categories,
+					"groups":     groups,
+					"users":      users,
+					"disabled":   rule.Disabled,
+					"log":        rule.Log,
+					"notify":     rule.Notify,
+					"alert":      rule.Alert,
+				},
+			})
+		}
+		return objects, nil
+	},
+	create: func(s *manifestState, name string, values map[string]interface{}) (interface{}, error) {
+		params := apiwebpolicies.NewAddWebPolicyParams()
+		setTenant(s.cmd, params)
+		params.SetRulesetID(s.webPolicyRulesetID)
+		action := manifestValueString(values, "action")
+		typ := manifestValueString(values, "type")
+		// new web policies are added after the existing ones
+		index := int64(len(s.objects["webpolicy"]))
+		disabled := manifestValueBool(values, "disabled")
+		log := manifestValueBool(values, "log")
+		notify := manifestValueBool(values, "notify")
+		alert := manifestValueBool(values, "alert")
+		params.SetWebpolicy(apiwebpolicies.AddWebPolicyBody{Data: &apiwebpolicies.AddWebPolicyParamsBodyData{
+			Label:      &name,
+			Action:     &action,
+			Type:       &typ,
+			Index:      &index,
+			Domains:    manifestValueStrings(values, "domains"),
+			Categories: manifestValueInt64s(values, "categories"),
+			GroupIds:   manifestIDsInt64(values, "groups"),
+			UserIds:    manifestIDsInt64(values, "users"),
+			Disabled:   &disabled,
+			Log:        &log,
+			Notify:     &notify,
+			Alert:      &alert,
+		}})
+		resp, err := global.Client.WebPolicies.AddWebPolicy(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Payload.RuleID, nil
+	},
+	update: func(s *manifestState, live *manifestObject, values map[string]interface{}) error {
+		params := apiwebpolicies.NewEditWebPolicyParams()
+		setTenant(s.cmd, params)
+		params.SetRulesetID(s.webPolicyRulesetID)
+		params.SetRuleID(live.id.(strfmt.UUID))
+		// booleans are always sent, so that they can be turned off
+		disabled := manifestValueBool(values, "disabled")
+		log := manifestValueBool(values, "log")
+		notify := manifestValueBool(values, "notify")
+		alert := manifestValueBool(values, "alert")
+		params.SetWebPolicy(apiwebpolicies.EditWebPolicyBody{Data: &apiwebpolicies.EditWebPolicyParamsBodyData{
+			Label:      live.name,
+			Action:     manifestValueString(values, "action"),
+			Type:       manifestValueString(values, "type"),
+			Domains:    manifestValueStrings(values, "domains"),
+			Categories: manifestValueInt64s(values, "categories"),
+			Disabled:   &disabled,
+			Log:        &log,
+			Notify:     &notify,
+			Alert:      &alert,
+		}})
+		_, err := global.Client.WebPolicies.EditWebPolicy(params, global.AuthWriter)
+		if err != nil {
+			return err
+		}
+		// users and groups are assigned to the jump rule
+		params.SetRuleID(live.extra.(strfmt.UUID))
+		params.SetWebPolicy(apiwebpolicies.EditWebPolicyBody{Data: &apiwebpolicies.EditWebPolicyParamsBodyData{
+			GroupIds: manifestIDsInt64(values, "groups"),
+			UserIds:  manifestIDsInt64(values, "users"),
+		}})
+		_, err = global.Client.WebPolicies.EditWebPolicy(params, global.AuthWriter)
+		return err
+	},
+	delete: func(s *manifestState, live *manifestObject) error {
+		params := apiwebpolicies.NewDeleteWebPolicyParams()
+		setTenant(s.cmd, params)
+		params.SetID(live.extra.(strfmt.UUID))
+		_, err := global.Client.WebPolicies.DeleteWebPolicy(params, global.AuthWriter)
+		return err
+	},
+}
+
+var manifestDomainKind = &manifestKind{
+	name:      "domain",
+	key:       "domains",
+	nameField: "name",
+	fields: []manifestField{
+		{name: "source", typ: manifestStringFold},
+	},
+	fetch: func(s *manifestState) ([]*manifestObject, error) {
+		items, err := listAllAssets(s.cmd, "domain")
+		if err != nil {
+			return nil, err
+		}
+		objects := []*manifestObject{}
+		for _, item := range items {
+			objects = append(objects, &manifestObject{
+				name: item.Name,
+				id:   item.ID,
+				values: map[string]interface{}{
+					"source": string(item.AssetSourceID),
+				},
+			})
+		}
+		return objects, nil
+	},
+	create: manifestCreateDomain,
+	update: func(s *manifestState, live *manifestObject, values map[string]interface{}) error {
+		// domains can not be edited, so they are replaced
+		err := manifestDeleteDomain(s, live)
+		if err != nil && !isDryRunError(err) {
+			return err
+		}
+		id, err := manifestCreateDomain(s, live.name, values)
+		if err != nil {
+			return err
+		}
+		live.id = id
+		return nil
+	},
+	delete: manifestDeleteDomain,
+}
+
+func manifestCreateDomain(s *manifestState, name string, values map[string]interface{}) (interface{}, error) {
+	params := apiassets.NewCreateAssetParams()
+	setTenant(s.cmd, params)
+	params.SetAsset(apiassets.CreateAssetBody{
+		AssetSourceID: strfmt.UUID(manifestValueString(values, "source")),
+		Category:      "domain",
+		Name:          name,
+	})
+	resp, err := global.Client.Assets.CreateAsset(params, global.AuthWriter)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Payload.ID, nil
+}
+
+func manifestDeleteDomain(s *manifestState, live *manifestObject) error {
+	params := apiassets.NewDeleteAssetParams()
+	setTenant(s.cmd, params)
+	params.SetID([]int64{live.id.(int64)})
+	_, err := global.Client.Assets.DeleteAsset(params, global.AuthWriter)
+	return err
+}
+
+// settings are managed as objects named after each kind of settings,
+// whose fields are those of the settings
+var manifestSettingsKind = &manifestKind{
+	name:      "settings",
+	key:       "settings",
+	nameField: "name",
+	singleton: true,
+	fetch: func(s *manifestState) ([]*manifestObject, error) {
+		objects := []*manifestObject{}
+		add := func(name string, payload interface{}) error {
+			values, err := existingRecordObject(payload)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, &manifestObject{name: name, id: name, values: values})
+			return nil
+		}
+
+		agentParams := apiagentconfiguration.NewSettingsAgentConfigurationParams()
+		setTenant(s.cmd, agentParams)
+		agent, err := global.Client.SettingsAgentConfiguration.SettingsAgentConfiguration(agentParams, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		if err := add("agent_configuration", agent.Payload); err != nil {
+			return nil, err
+		}
+
+		analyticsParams := apianalytics.NewSettingsAnalyticsParams()
+		setTenant(s.cmd, analyticsParams)
+		analytics, err := global.Client.SettingsAnalytics.SettingsAnalytics(analyticsParams, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		if err := add("analytics", analytics.Payload); err != nil {
+			return nil, err
+		}
+
+		enrollmentParams := apienrollment.NewSettingsEnrollmentParams()
+		setTenant(s.cmd, enrollmentParams)
+		enrollment, err := global.Client.SettingsEnrollment.SettingsEnrollment(enrollmentParams, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		if err := add("enrollment", enrollment.Payload); err != nil {
+			return nil, err
+		}
+		return objects, nil
+	},
+	update: func(s *manifestState, live *manifestObject, values map[string]interface{}) error {
+		b, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		switch live.name {
+		case "agent_configuration":
+			config := &models.SettingsAgentConfiguration{}
+			if err := json.Unmarshal(b, config); err != nil {
+				return fmt.Errorf("invalid agent configuration settings: %v", err)
+			}
+			params := apiagentconfiguration.NewEditSettingsAgentConfigurationParams()
+			setTenant(s.cmd, params)
+			params.SetAppConfiguration(apiagentconfiguration.EditSettingsAgentConfigurationBody{AppConfiguration: config})
+			_, err = global.Client.SettingsAgentConfiguration.EditSettingsAgentConfiguration(params, global.AuthWriter)
+		case "analytics":
+			config := &models.SettingsAnalytics{}
+			if err := json.Unmarshal(b, config); err != nil {
+				return fmt.Errorf("invalid analytics settings: %v", err)
+			}
+			params := apianalytics.NewEditSettingsAnalyticsParams()
+			setTenant(s.cmd, params)
+			params.SetAnalyticsSettings(apianalytics.EditSettingsAnalyticsBody{AnalyticsSettings: config})
+			_, err = global.Client.SettingsAnalytics.EditSettingsAnalytics(params, global.AuthWriter)
+		case "enrollment":
+			config := &models.SettingsEnrollment{}
+			if err := json.Unmarshal(b, config); err != nil {
+				return fmt.Errorf("invalid enrollment settings: %v", err)
+			}
+			params := apienrollment.NewEditSettingsEnrollmentParams()
+			setTenant(s.cmd, params)
+			params.SetEnrollmentSettings(apienrollment.EditSettingsEnrollmentBody{EnrollmentSettings: config})
+			_, err = global.Client.SettingsEnrollment.EditSettingsEnrollment(params, global.AuthWriter)
+		}
+		return err
+	},
+}
@@ -0,0 +1,942 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// manifestFieldType determines how the values of a manifest field are
+// read, compared and displayed
+type manifestFieldType int
+
+const (
+	manifestString     manifestFieldType = iota
+	manifestStringFold                   // compared ignoring case
+	manifestInt                          // nullable
+	manifestBool
+	manifestStrings // unordered
+	manifestInts    // unordered
+	manifestPorts   // unordered port mappings, in the external:internal:protocol format
+	manifestRef     // reference to an object by name
+	manifestRefs    // unordered references to objects by name
+	manifestJSON    // arbitrary value, of which only the given fields are compared
+)
+
+type manifestField struct {
+	name string
+	typ  manifestFieldType
+	ref  string // for references, the kind of the referenced objects
+}
+
+// manifestObject is an object of a kind, either from the manifests or from
+// the live state. Values of reference fields are names in objects read from
+// manifests, and IDs in live objects and in resolved manifest objects
+type manifestObject struct {
+	name   string
+	id     interface{}
+	extra  interface{} // live data needed to edit the object, specific to each kind
+	values map[string]interface{}
+	source string // manifest file where the object is defined
+}
+
+// manifestKind describes a kind of object that can be managed with manifests
+type manifestKind struct {
+	name      string // singular, as used in messages and output
+	key       string // key of the list of objects in manifests
+	nameField string
+	fields    []manifestField // nil for kinds whose fields are manifestJSON values
+	singleton bool            // objects always exist, and are never created or deleted
+	depends   []string        // kinds whose objects are referenced by this kind
+	fetch     func(s *manifestState) ([]*manifestObject, error)
+	create    func(s *manifestState, name string, values map[string]interface{}) (interface{}, error)
+	// update receives the values of the live object, replaced by those in the manifest
+	update func(s *manifestState, live *manifestObject, values map[string]interface{}) error
+	delete func(s *manifestState, live *manifestObject) error
+}
+
+// manifestKinds lists the kinds of objects in the order in which they are
+// created and updated. Objects are deleted in the reverse order
+var manifestKinds []*manifestKind
+
+func manifestKindByName(name string) *manifestKind {
+	for _, kind := range manifestKinds {
+		if kind.name == name {
+			return kind
+		}
+	}
+	panic("manifestKindByName called for unknown kind " + name + ". This is a bug!")
+}
+
+func (k *manifestKind) field(name string) (manifestField, bool) {
+	if k.fields == nil {
+		return manifestField{name: name, typ: manifestJSON}, true
+	}
+	for _, f := range k.fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return manifestField{}, false
+}
+
+// manifestSet is the desired state, as read from manifests
+type manifestSet struct {
+	objects map[string][]*manifestObject // by kind name
+	present map[string]bool              // kinds whose key is present in some manifest
+}
+
+// readManifests reads the YAML manifests at path, which may be a file or a
+// directory, in which case all .yaml and .yml files in it are read
+func readManifests(path string) (*manifestSet, error) {
+	set := &manifestSet{
+		objects: make(map[string][]*manifestObject),
+		present: make(map[string]bool),
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = []string{}
+		err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(p))
+			if !info.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+	for _, file := range files {
+		if err := set.readFile(file); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+func (set *manifestSet) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	decoder := yaml.NewDecoder(f)
+	for {
+		var doc map[string]interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		for key, value := range doc {
+			if err := set.add(path, key, value); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+	}
+}
+
+func (set *manifestSet) add(path, key string, value interface{}) error {
+	var kind *manifestKind
+	for _, k := range manifestKinds {
+		if k.key == key {
+			kind = k
+		}
+	}
+	if kind == nil {
+		return fmt.Errorf("unknown key %q", key)
+	}
+	set.present[kind.name] = true
+
+	entries := []map[string]interface{}{}
+	if kind.singleton {
+		// singleton objects are given as a map, by name
+		m, ok := value.(map[string]interface{})
+		if value != nil && !ok {
+			return fmt.Errorf("%s must be a map", key)
+		}
+		names := []string{}
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			values, ok := m[name].(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s.%s must be a map", key, name)
+			}
+			entry := map[string]interface{}{kind.nameField: name}
+			for k, v := range values {
+				entry[k] = v
+			}
+			entries = append(entries, entry)
+		}
+	} else {
+		list, ok := value.([]interface{})
+		if value != nil && !ok {
+			return fmt.Errorf("%s must be a list", key)
+		}
+		for i, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s[%d] must be a map", key, i)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	for _, entry := range entries {
+		name, ok := entry[kind.nameField].(string)
+		if !ok || name == "" {
+			return fmt.Errorf("%s must have a %s", kind.name, kind.nameField)
+		}
+		for _, other := range set.objects[kind.name] {
+			if strings.EqualFold(other.name, name) {
+				return fmt.Errorf("%s %q is also defined in %s", kind.name, name, other.source)
+			}
+		}
+		obj := &manifestObject{
+			name:   name,
+			values: make(map[string]interface{}),
+			source: path,
+		}
+		for k, v := range entry {
+			if k == kind.nameField {
+				continue
+			}
+			field, ok := kind.field(k)
+			if !ok {
+				return fmt.Errorf("%s %q: unknown field %q", kind.name, name, k)
+			}
+			converted, err := convertManifestValue(field, v)
+			if err != nil {
+				return fmt.Errorf("%s %q: field %s: %v", kind.name, name, k, err)
+			}
+			obj.values[k] = converted
+		}
+		set.objects[kind.name] = append(set.objects[kind.name], obj)
+	}
+	return nil
+}
+
+// convertManifestValue converts a value read from YAML to the type used
+// for values of the field. References are kept as names
+func convertManifestValue(field manifestField, v interface{}) (interface{}, error) {
+	switch field.typ {
+	case manifestString, manifestStringFold, manifestRef:
+		if v == nil {
+			return "", nil
+		}
+		switch v.(type) {
+		case string, int, float64:
+			return fmt.Sprint(v), nil
+		}
+		return nil, fmt.Errorf("expected a string")
+	case manifestInt:
+		if v == nil {
+			return nil, nil
+		}
+		return manifestToInt64(v)
+	case manifestBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected true or false")
+		}
+		return b, nil
+	case manifestStrings, manifestPorts, manifestRefs:
+		list, ok := v.([]interface{})
+		if v != nil && !ok {
+			return nil, fmt.Errorf("expected a list")
+		}
+		s := []string{}
+		for _, item := range list {
+			str, err := convertManifestValue(manifestField{typ: manifestString}, item)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, str.(string))
+		}
+		if field.typ == manifestPorts {
+			if err := checkPortMappings(s); err != nil {
+				return nil, err
+			}
+			mappings := make([]*models.AccessResourcePortMapping, len(s))
+			for i, mapping := range s {
+				mappings[i] = colonMappingToPortMapping(mapping)
+			}
+			return portMappingStrings(mappings), nil
+		}
+		return s, nil
+	case manifestInts:
+		list, ok := v.([]interface{})
+		if v != nil && !ok {
+			return nil, fmt.Errorf("expected a list")
+		}
+		ints := []int64{}
+		for _, item := range list {
+			i, err := manifestToInt64(item)
+			if err != nil {
+				return nil, err
+			}
+			ints = append(ints, i)
+		}
+		return ints, nil
+	}
+	return v, nil
+}
+
+func manifestToInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), nil
+	case int64:
+		return n, nil
+	case float64:
+		if n == float64(int64(n)) {
+			return int64(n), nil
+		}
+	case json.Number:
+		return n.Int64()
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	}
+	return 0, fmt.Errorf("expected an integer")
+}
+
+// manifestState is the live state of the objects of the kinds managed by
+// the manifests
+type manifestState struct {
+	cmd     *cobra.Command
+	objects map[string][]*manifestObject // by kind name
+	users   *referenceResolver
+	// data shared between kinds
+	webPolicyRulesetID strfmt.UUID
+	resourcePolicies   map[string][]interface{} // resource IDs by policy ID
+	newObjects         int
+}
+
+// fetchManifestState fetches the live objects of the kinds in set, and of
+// the kinds they reference
+func fetchManifestState(cmd *cobra.Command, set *manifestSet) (*manifestState, error) {
+	s := &manifestState{
+		cmd:     cmd,
+		objects: make(map[string][]*manifestObject),
+		users: &referenceResolver{
+			cmd:   cmd,
+			cache: make(map[string]*referenceLookup),
+		},
+	}
+	needed := make(map[string]bool)
+	var need func(name string)
+	need = func(name string) {
+		if name == "user" || needed[name] {
+			return
+		}
+		needed[name] = true
+		for _, dep := range manifestKindByName(name).depends {
+			need(dep)
+		}
+	}
+	for name := range set.present {
+		need(name)
+	}
+	for _, kind := range manifestKinds {
+		if !needed[kind.name] {
+			continue
+		}
+		objects, err := kind.fetch(s)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %v", pluralize(kind.name), processErrorResponse(err))
+		}
+		s.objects[kind.name] = objects
+	}
+	return s, nil
+}
+
+// find returns the live object of the given kind with the given name
+func (s *manifestState) find(kind, name string) *manifestObject {
+	for _, obj := range s.objects[kind] {
+		if strings.EqualFold(obj.name, name) {
+			return obj
+		}
+	}
+	return nil
+}
+
+// resolve returns the ID of the object of the given kind referenced by
+// value, which may be either its name or its ID
+func (s *manifestState) resolve(kind, value string) (interface{}, error) {
+	if kind == "user" {
+		// users are not managed by manifests, so they are looked up as needed
+		return s.users.resolve(kind, value)
+	}
+	ids := []string{}
+	var id interface{}
+	for _, obj := range s.objects[kind] {
+		if strings.EqualFold(obj.name, value) {
+			id = obj.id
+			ids = append(ids, fmt.Sprint(obj.id))
+		}
+	}
+	switch {
+	case len(ids) > 1:
+		return nil, fmt.Errorf("%s name %q is ambiguous, it matches IDs %s", kind, value, strings.Join(ids, ", "))
+	case len(ids) == 1:
+		return id, nil
+	}
+	for _, obj := range s.objects[kind] {
+		if fmt.Sprint(obj.id) == value {
+			return obj.id, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s named %q", kind, value)
+}
+
+// nameOf returns the name of the object of the given kind with the given ID
+func (s *manifestState) nameOf(kind string, id interface{}) string {
+	for _, obj := range s.objects[kind] {
+		if fmt.Sprint(obj.id) == fmt.Sprint(id) {
+			return obj.name
+		}
+	}
+	return fmt.Sprint(id)
+}
+
+// resolveValues returns the values of obj, with references replaced by IDs
+func (s *manifestState) resolveValues(kind *manifestKind, obj *manifestObject) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(obj.values))
+	for k, v := range obj.values {
+		field, _ := kind.field(k)
+		switch field.typ {
+		case manifestRef:
+			if v.(string) == "" {
+				values[k] = nil
+				continue
+			}
+			id, err := s.resolve(field.ref, v.(string))
+			if err != nil {
+				return nil, err
+			}
+			values[k] = id
+		case manifestRefs:
+			ids := []interface{}{}
+			for _, name := range v.([]string) {
+				id, err := s.resolve(field.ref, name)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, id)
+			}
+			values[k] = ids
+		default:
+			values[k] = v
+		}
+	}
+	return values, nil
+}
+
+// manifestPlaceholderID is the ID of an object that would be created
+type manifestPlaceholderID string
+
+// placeholderID returns an ID for an object that would be created, so that
+// other objects can reference it when planning
+func (s *manifestState) placeholderID() interface{} {
+	s.newObjects++
+	return manifestPlaceholderID(fmt.Sprintf("(new %d)", s.newObjects))
+}
+
+// noteCreatedReferences adds to the request held back by --dry-run for
+// change the objects it references that would be created by the requests
+// above it, since their IDs are not known yet
+func (s *manifestState) noteCreatedReferences(change *manifestChange, err error) {
+	var d *dryRunError
+	if !errors.As(err, &d) {
+		return
+	}
+	refs := []string{}
+	note := func(field manifestField, id interface{}) {
+		if _, ok := id.(manifestPlaceholderID); ok {
+			refs = append(refs, fmt.Sprintf("%s %q %s", field.ref, s.nameOf(field.ref, id), id))
+		}
+	}
+	for _, field := range change.kind.fields {
+		switch field.typ {
+		case manifestRef:
+			note(field, change.values[field.name])
+		case manifestRefs:
+			list, _ := change.values[field.name].([]interface{})
+			for _, id := range list {
+				note(field, id)
+			}
+		}
+	}
+	if len(refs) > 0 {
+		d.request.Body += "\nreferences objects created above: " + strings.Join(refs, ", ")
+	}
+}
+
+// manifestCompareValue returns the string used to compare values of field
+func manifestCompareValue(field manifestField, v interface{}) string {
+	switch field.typ {
+	case manifestStringFold:
+		return strings.ToLower(fmt.Sprint(v))
+	case manifestInt:
+		if v == nil {
+			return "null"
+		}
+	case manifestStrings, manifestPorts:
+		s := append([]string{}, v.([]string)...)
+		sort.Strings(s)
+		return strings.Join(s, ", ")
+	case manifestInts:
+		ints := append([]int64{}, v.([]int64)...)
+		sort.Slice(ints, func(i, j int) bool { return ints[i] < ints[j] })
+		return fmt.Sprint(ints)
+	case manifestRefs:
+		s := []string{}
+		for _, id := range v.([]interface{}) {
+			s = append(s, fmt.Sprint(id))
+		}
+		sort.Strings(s)
+		return strings.Join(s, ", ")
+	case manifestJSON:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// displayValue returns how a value of field is shown in plans
+func (s *manifestState) displayValue(field manifestField, v interface{}) string {
+	switch field.typ {
+	case manifestRef:
+		if v == nil {
+			return ""
+		}
+		return s.nameOf(field.ref, v)
+	case manifestRefs:
+		names := []string{}
+		for _, id := range v.([]interface{}) {
+			names = append(names, s.nameOf(field.ref, id))
+		}
+		sort.Strings(names)
+		return strings.Join(names, ", ")
+	case manifestStringFold:
+		return fmt.Sprint(v)
+	}
+	return manifestCompareValue(field, v)
+}
+
+// projectManifestJSON returns the parts of live that are present in desired
+func projectManifestJSON(live, desired interface{}) interface{} {
+	d, ok := desired.(map[string]interface{})
+	if !ok {
+		return live
+	}
+	l, ok := live.(map[string]interface{})
+	if !ok {
+		return live
+	}
+	projected := make(map[string]interface{}, len(d))
+	for k, v := range d {
+		projected[k] = projectManifestJSON(l[k], v)
+	}
+	return projected
+}
+
+// mergeManifestJSON returns live with the fields in desired replaced
+func mergeManifestJSON(live, desired interface{}) interface{} {
+	d, ok := desired.(map[string]interface{})
+	if !ok {
+		return desired
+	}
+	l, ok := live.(map[string]interface{})
+	if !ok {
+		return desired
+	}
+	merged := make(map[string]interface{}, len(l))
+	for k, v := range l {
+		merged[k] = v
+	}
+	for k, v := range d {
+		merged[k] = mergeManifestJSON(l[k], v)
+	}
+	return merged
+}
+
+// manifestFieldChange is a change to one field of an object
+type manifestFieldChange struct {
+	Field   string `json:"field"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+const (
+	manifestActionCreate = "create"
+	manifestActionUpdate = "update"
+	manifestActionDelete = "delete"
+)
+
+// manifestChange is a change needed to bring an object to the state
+// described by the manifests
+type manifestChange struct {
+	Action string                `json:"action"`
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	ID     interface{}           `json:"id,omitempty"`
+	Fields []manifestFieldChange `json:"fields,omitempty"`
+	Result string                `json:"result,omitempty"`
+
+	kind   *manifestKind
+	live   *manifestObject
+	values map[string]interface{} // resolved values to create or update the object with
+}
+
+// diffManifestKind returns the objects of kind that must be created or
+// updated, and those that must be deleted when pruning
+func (s *manifestState) diffManifestKind(kind *manifestKind, set *manifestSet, prune bool) ([]*manifestChange, []*manifestChange, error) {
+	changes := []*manifestChange{}
+	for _, desired := range set.objects[kind.name] {
+		values, err := s.resolveValues(kind, desired)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %q (%s): %v", kind.name, desired.name, desired.source, err)
+		}
+		fields := []string{}
+		for k := range values {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+
+		live := s.find(kind.name, desired.name)
+		if live == nil {
+			if kind.singleton {
+				return nil, nil, fmt.Errorf("%s %q (%s): unknown %s", kind.name, desired.name, desired.source, kind.name)
+			}
+			change := &manifestChange{
+				Action: manifestActionCreate,
+				Kind:   kind.name,
+				Name:   desired.name,
+				kind:   kind,
+				values: values,
+			}
+			for _, k := range fields {
+				field, _ := kind.field(k)
+				change.Fields = append(change.Fields, manifestFieldChange{
+					Field:   k,
+					Desired: s.displayValue(field, values[k]),
+				})
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		change := &manifestChange{
+			Action: manifestActionUpdate,
+			Kind:   kind.name,
+			Name:   live.name,
+			ID:     live.id,
+			kind:   kind,
+			live:   live,
+			values: make(map[string]interface{}, len(live.values)),
+		}
+		if kind.fields != nil {
+			// kinds with manifestJSON fields are only updated with the fields in
+			// the manifest, as their live values can not always be sent back
+			for k, v := range live.values {
+				change.values[k] = v
+			}
+		}
+		for _, k := range fields {
+			field, _ := kind.field(k)
+			current := live.values[k]
+			if field.typ == manifestJSON {
+				current = projectManifestJSON(current, values[k])
+				change.values[k] = mergeManifestJSON(live.values[k], values[k])
+			} else {
+				change.values[k] = values[k]
+			}
+			if manifestCompareValue(field, current) != manifestCompareValue(field, values[k]) {
+				change.Fields = append(change.Fields, manifestFieldChange{
+					Field:   k,
+					Current: s.displayValue(field, current),
+					Desired: s.displayValue(field, values[k]),
+				})
+			}
+		}
+		if len(change.Fields) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	deletes := []*manifestChange{}
+	if prune && set.present[kind.name] && !kind.singleton {
+		for _, live := range s.objects[kind.name] {
+			found := false
+			for _, desired := range set.objects[kind.name] {
+				if strings.EqualFold(desired.name, live.name) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				deletes = append(deletes, &manifestChange{
+					Action: manifestActionDelete,
+					Kind:   kind.name,
+					Name:   live.name,
+					ID:     live.id,
+					kind:   kind,
+					live:   live,
+				})
+			}
+		}
+	}
+	return changes, deletes, nil
+}
+
+// execute performs the change. When execute is false, or with --dry-run,
+// the state is updated as if the change had been performed
+func (s *manifestState) execute(change *manifestChange, execute bool) error {
+	switch change.Action {
+	case manifestActionCreate:
+		var id interface{}
+		var err error
+		if execute {
+			id, err = change.kind.create(s, change.Name, change.values)
+			if err != nil && !isDryRunError(err) {
+				return err
+			}
+			s.noteCreatedReferences(change, err)
+		}
+		if id == nil {
+			id = s.placeholderID()
+		} else {
+			change.ID = id
+		}
+		s.objects[change.kind.name] = append(s.objects[change.kind.name], &manifestObject{
+			name:   change.Name,
+			id:     id,
+			values: change.values,
+		})
+		if execute {
+			change.Result = "created"
+		}
+	case manifestActionUpdate:
+		if execute {
+			err := change.kind.update(s, change.live, change.values)
+			if err != nil && !isDryRunError(err) {
+				return err
+			}
+			s.noteCreatedReferences(change, err)
+		}
+		change.live.values = change.values
+		change.ID = change.live.id
+		if execute {
+			change.Result = "updated"
+		}
+	case manifestActionDelete:
+		if execute {
+			err := change.kind.delete(s, change.live)
+			if err != nil && !isDryRunError(err) {
+				return err
+			}
+		}
+		if execute {
+			change.Result = "deleted"
+		}
+	}
+	return nil
+}
+
+// runManifests compares the manifests in set with the live state and calls
+// report for each change, in the order in which changes are performed.
+// When execute is true, changes are performed before being reported
+func runManifests(cmd *cobra.Command, set *manifestSet, prune, execute bool, report func(*manifestChange, error)) error {
+	s, err := fetchManifestState(cmd, set)
+	if err != nil {
+		return err
+	}
+	// when planning, changes are not performed and can not fail
+	continueOnError := execute && loopControlContinueOnError(cmd)
+	var loopErr error
+	perform := func(change *manifestChange) bool {
+		err := s.execute(change, execute)
+		report(change, err)
+		if err != nil {
+			if loopErr == nil {
+				loopErr = err
+			}
+			return continueOnError
+		}
+		return true
+	}
+
+	deletes := []*manifestChange{}
+	for _, kind := range manifestKinds {
+		changes, kindDeletes, err := s.diffManifestKind(kind, set, prune)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if !perform(change) {
+				return loopErr
+			}
+		}
+		// objects are deleted in the reverse order
+		deletes = append(kindDeletes, deletes...)
+	}
+	for _, change := range deletes {
+		if !perform(change) {
+			return loopErr
+		}
+	}
+	if continueOnError {
+		return nil
+	}
+	return loopErr
+}
+
+func initManifestFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("file", "f", "", "manifest file, or directory with manifest files (.yaml or .yml)")
+	cmd.Flags().Bool("prune", false, "delete objects that are not in the manifests, for the kinds present in the manifests")
+	cmd.MarkFlagRequired("file")
+}
+
+func readManifestsFromFlags(cmd *cobra.Command) (*manifestSet, bool, error) {
+	path, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return nil, false, err
+	}
+	prune, err := cmd.Flags().GetBool("prune")
+	if err != nil {
+		return nil, false, err
+	}
+	set, err := readManifests(path)
+	return set, prune, err
+}
+
+func manifestPlanBuildTableWriter() table.Writer {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Action",
+		"Kind",
+		"Name",
+		"Field",
+		"Current",
+		"Desired",
+	})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 8},
+		{Number: 2, WidthMax: 12},
+		{Number: 3, WidthMax: 30},
+		{Number: 4, WidthMax: 30},
+		{Number: 5, WidthMax: 40},
+		{Number: 6, WidthMax: 40},
+	})
+	return tw
+}
+
+func manifestPlanTableWriterAppend(tw table.Writer, change *manifestChange) {
+	if len(change.Fields) == 0 {
+		tw.AppendRow(table.Row{change.Action, change.Kind, change.Name, "", "", ""})
+		return
+	}
+	for i, field := range change.Fields {
+		if i == 0 {
+			tw.AppendRow(table.Row{change.Action, change.Kind, change.Name, field.Field, field.Current, field.Desired})
+		} else {
+			tw.AppendRow(table.Row{"", "", "", field.Field, field.Current, field.Desired})
+		}
+	}
+}
+
+func manifestApplyBuildTableWriter() table.Writer {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Action",
+		"Kind",
+		"Name",
+		"ID",
+		"Result",
+	})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 1, WidthMax: 8},
+		{Number: 2, WidthMax: 12},
+		{Number: 3, WidthMax: 30},
+		{Number: 4, WidthMax: 36},
+		{Number: 5, WidthMax: 60},
+	})
+	return tw
+}
+
+func manifestApplyTableWriterAppend(tw table.Writer, change *manifestChange, err error) {
+	result := change.Result
+	if err != nil {
+		result = processErrorResponse(err).Error()
+		change.Result = result
+	}
+	id := change.ID
+	if id == nil {
+		id = ""
+	}
+	tw.AppendRow(table.Row{change.Action, change.Kind, change.Name, id, result})
+}
+
+// manifestPlanSummary returns a summary of the number of changes of each action
+func manifestPlanSummary(changes []*manifestChange) string {
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Action]++
+	}
+	if len(changes) == 0 {
+		return "No changes"
+	}
+	return fmt.Sprintf("%d to create, %d to update, %d to delete",
+		counts[manifestActionCreate], counts[manifestActionUpdate], counts[manifestActionDelete])
+}
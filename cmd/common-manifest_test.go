@@ -0,0 +1,239 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestApplyManifests(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer applyCmd.Flags().Set("prune", "false")
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "groups.yaml"), []byte(`groups:
+  - name: Engineering
+    description: Engineers
+  - name: ops
+    description: Operations
+---
+groups:
+  - name: Sales
+    color: "#ff0000"
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "4").
+		JSON([]map[string]interface{}{
+			{"id": 2, "name": "Ops", "description": "Old description"},
+			{"id": 3, "name": "DevOps", "description": "Other group"},
+			{"id": 4, "name": "Sales", "description": "Sales team", "color": "#FF0000"},
+			{"id": 5, "name": "Marketing"},
+		})
+	gock.New(baseURIinTests()).
+		Post("/groups").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 10, "name": "Engineering", "description": "Engineers"})
+	gock.New(baseURIinTests()).
+		Patch("/groups/2").
+		Reply(200).
+		JSON(map[string]interface{}{"id": 2, "name": "Ops", "description": "Operations"})
+	gock.New(baseURIinTests()).
+		Delete("/groups/3").
+		Reply(204)
+	gock.New(baseURIinTests()).
+		Delete("/groups/5").
+		Reply(204)
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"apply",
+		"-o=json",
+		"--continue-on-error=false",
+		"-f", dir,
+		"--prune",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	changes := []manifestChange{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &changes), nil)
+	st.Assert(t, len(changes), 4)
+	st.Expect(t, changes[0].Action, manifestActionCreate)
+	st.Expect(t, changes[0].Name, "Engineering")
+	st.Expect(t, changes[1].Action, manifestActionUpdate)
+	st.Expect(t, changes[1].Name, "Ops")
+	st.Expect(t, changes[1].Fields, []manifestFieldChange{{Field: "description", Current: "Old description", Desired: "Operations"}})
+	st.Expect(t, changes[2].Action, manifestActionDelete)
+	st.Expect(t, changes[2].Name, "DevOps")
+	st.Expect(t, changes[3].Action, manifestActionDelete)
+	st.Expect(t, changes[3].Name, "Marketing")
+}
+
+func TestApplyManifestsDryRunCreatedReferences(t *testing.T) {
+	defer gock.Off()
+	defer func() { global.DryRun = false }()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "tenant.yaml"), []byte(`proxies:
+  - name: edge
+    host: edge.example.com
+    port: 443
+resources:
+  - name: wiki
+    public_host: wiki.example.com
+    internal_host: 10.0.0.5
+    ports: ["443:443"]
+    proxy: edge
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/access_proxies").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Get("/access_resources").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"apply",
+		"-o=json",
+		"--dry-run",
+		"--continue-on-error=false",
+		"-f", dir,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	r := []multiOpJSONResult{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &r), nil)
+	st.Assert(t, len(r), 2)
+	// the resource references the proxy by its placeholder, and says so
+	st.Expect(t, strings.Contains(r[1].Result, `"access_proxy_id":"(new 1)"`), true)
+	st.Expect(t, strings.HasSuffix(r[1].Result, `references objects created above: proxy "edge" (new 1)`), true)
+}
+
+func TestApplyManifestsWebPolicyTurnOff(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "webpolicies.yaml"), []byte(`webpolicies:
+  - label: Block gambling
+    action: block
+    type: categories
+    domains: []
+    categories: [12]
+    groups: []
+    users: []
+    disabled: false
+    log: true
+    notify: false
+    alert: false
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Get("/dns_security/rulesets").
+		Reply(200).
+		JSON(map[string]interface{}{
+			"id": "7a4bd5a1-c4a2-4ba8-9a2b-6d7f4c1f1a10",
+			"rules": []interface{}{map[string]interface{}{
+				"id":   "3f0c1a52-9b1e-4c7d-8f2a-5e6d7c8b9a01",
+				"type": "jump",
+				"ruleset_jump": map[string]interface{}{
+					"rules": []interface{}{map[string]interface{}{
+						"id":         "b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e",
+						"label":      "Block gambling",
+						"action":     "block",
+						"type":       "categories",
+						"categories": []int{12},
+						"log":        true,
+						"alert":      true,
+					}},
+				},
+			}},
+		})
+	// alert is turned off, which is only sent if false values are kept
+	gock.New(baseURIinTests()).
+		Patch("/rules/b2c3d4e5-f6a7-4b8c-9d0e-1f2a3b4c5d6e").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			return strings.Contains(string(body), `"alert":false`), err
+		}).
+		Reply(200)
+	gock.New(baseURIinTests()).
+		Patch("/rules/3f0c1a52-9b1e-4c7d-8f2a-5e6d7c8b9a01").
+		Reply(200)
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"apply",
+		"-o=json",
+		"--continue-on-error=false",
+		"-f", dir,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	changes := []manifestChange{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &changes), nil)
+	st.Assert(t, len(changes), 1)
+	st.Expect(t, changes[0].Action, manifestActionUpdate)
+	st.Expect(t, changes[0].Fields, []manifestFieldChange{{Field: "alert", Current: "true", Desired: "false"}})
+}
@@ -0,0 +1,196 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+
+	apiusers "github.com/barracuda-cloudgen-access/access-cli/client/users"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// groupMembersAttempts is how many times a membership change is attempted
+// when the groups of the user are changed concurrently
+const groupMembersAttempts = 3
+
+// groupMembersConflictError is returned when the groups of a user kept being
+// changed by someone else while being written
+type groupMembersConflictError struct {
+	attempts int
+}
+
+func (e *groupMembersConflictError) Error() string {
+	return fmt.Sprintf("conflict: the groups of the user were changed concurrently in each of %d attempts, try again", e.attempts)
+}
+
+// groupMembersReadUsers returns the users to add to or remove from a group:
+// the arguments, or else the piped input, which may be either the JSON output
+// of a users command or one user per line. Users are IDs or emails
+func groupMembersReadUsers(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	stdinInfo, err := os.Stdin.Stat()
+	hasPiped := err == nil && (stdinInfo.Mode()&os.ModeCharDevice == 0)
+	if !hasPiped {
+		return nil, fmt.Errorf("missing user arguments")
+	}
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+	return parseGroupMembersInput(data)
+}
+
+func parseGroupMembersInput(data []byte) ([]string, error) {
+	users := []string{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber() // important, otherwise all numbers are decoded as float64
+
+		var jsonArray []map[string]interface{}
+		if err := d.Decode(&jsonArray); err != nil {
+			return nil, fmt.Errorf("decoding JSON from pipe: %w", err)
+		}
+		for i, itemMap := range jsonArray {
+			switch {
+			case itemMap["id"] != nil:
+				users = append(users, fmt.Sprint(itemMap["id"]))
+			case itemMap["email"] != nil:
+				users = append(users, fmt.Sprint(itemMap["email"]))
+			default:
+				return nil, fmt.Errorf("item %d of piped JSON has neither id nor email", i+1)
+			}
+		}
+		return users, nil
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		users = append(users, line)
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("missing user arguments")
+	}
+	return users, nil
+}
+
+// newGroupMembersResolver returns a resolver for groups and users given by
+// ID, name or email, regardless of --strict-ids, which members commands lack
+func newGroupMembersResolver(cmd *cobra.Command) *referenceResolver {
+	r := newReferenceResolver(cmd)
+	r.strict = false
+	return r
+}
+
+func groupMembersUserGroupIDs(user *models.User) []int64 {
+	ids := []int64{}
+	for _, group := range user.Groups {
+		ids = append(ids, group.ID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func groupMembersGetUser(cmd *cobra.Command, userID int64) (*models.User, error) {
+	params := apiusers.NewGetUserParams()
+	setTenant(cmd, params)
+	params.SetID(userID)
+	resp, err := global.Client.Users.GetUser(params, global.AuthWriter)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Payload.User, nil
+}
+
+// changeGroupMembership adds the user to the group, or removes it, without
+// changing its other groups. As users can only be written as a whole, the
+// user is read again once written: if its groups are not the ones written
+// (e.g. someone else edited them at the same time), the change is attempted
+// again from its new groups, so that concurrent changes are not overwritten
+func changeGroupMembership(cmd *cobra.Command, userID, groupID int64, add bool) (string, error) {
+	result := "removed"
+	if add {
+		result = "added"
+	}
+	written := false
+	for attempt := 0; attempt < groupMembersAttempts; attempt++ {
+		user, err := groupMembersGetUser(cmd, userID)
+		if err != nil {
+			return "", err
+		}
+		current := groupMembersUserGroupIDs(user)
+		isMember := funk.ContainsInt64(current, groupID)
+		if add == isMember {
+			switch {
+			case written:
+				// an earlier attempt was applied after all
+				return result, nil
+			case add:
+				return "already a member", nil
+			default:
+				return "not a member", nil
+			}
+		}
+		desired := []int64{}
+		for _, id := range current {
+			if id != groupID {
+				desired = append(desired, id)
+			}
+		}
+		if add {
+			desired = append(desired, groupID)
+		}
+		sort.Slice(desired, func(i, j int) bool { return desired[i] < desired[j] })
+
+		params := apiusers.NewEditUserParams()
+		setTenant(cmd, params)
+		params.SetID(userID)
+		enabled := user.Enabled
+		params.SetUser(apiusers.EditUserBody{
+			User: &apiusers.EditUserParamsBodyUser{
+				Enabled:  &enabled,
+				GroupIds: desired,
+			},
+		})
+		if _, err := global.Client.Users.EditUser(params, global.AuthWriter); err != nil {
+			return "", err
+		}
+		written = true
+
+		updated, err := groupMembersGetUser(cmd, userID)
+		if err != nil {
+			return "", err
+		}
+		if funk.Equal(groupMembersUserGroupIDs(updated), desired) {
+			return result, nil
+		}
+	}
+	return "", &groupMembersConflictError{attempts: groupMembersAttempts}
+}
@@ -0,0 +1,166 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestGroupMembersAdd(t *testing.T) {
+	defer gock.Off()
+
+	user := func(id int, updatedAt string, groups ...int) map[string]interface{} {
+		items := []map[string]interface{}{}
+		for _, g := range groups {
+			items = append(items, map[string]interface{}{"id": g})
+		}
+		return map[string]interface{}{"id": id, "enabled": true, "updated_at": updatedAt, "groups": items}
+	}
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "Eng").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 10, "name": "eng"}})
+	// user 5 is added, keeping its other group
+	gock.New(baseURIinTests()).
+		Get("/users/5").
+		Reply(200).
+		JSON(user(5, "2023-01-01T00:00:00Z", 2))
+	gock.New(baseURIinTests()).
+		Patch("/users/5").
+		BodyString(`"group_ids":\[2,10\]`).
+		Reply(200).
+		JSON(user(5, "2023-01-02T00:00:00Z", 2, 10))
+	gock.New(baseURIinTests()).
+		Get("/users/5").
+		Reply(200).
+		JSON(user(5, "2023-01-02T00:00:00Z", 2, 10))
+	// bob is already a member
+	gock.New(baseURIinTests()).
+		Get("/users").
+		MatchParam("q", "bob@example.com").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 6, "email": "Bob@example.com"}})
+	gock.New(baseURIinTests()).
+		Get("/users/6").
+		Reply(200).
+		JSON(user(6, "2023-01-01T00:00:00Z", 10))
+	// the groups of user 7 are replaced by someone else at the same time, so
+	// the change is made again from its new groups
+	gock.New(baseURIinTests()).
+		Get("/users/7").
+		Reply(200).
+		JSON(user(7, "2023-01-01T00:00:00Z"))
+	gock.New(baseURIinTests()).
+		Patch("/users/7").
+		BodyString(`"group_ids":\[10\]`).
+		Reply(200).
+		JSON(user(7, "2023-01-02T00:00:00Z", 10))
+	gock.New(baseURIinTests()).
+		Get("/users/7").
+		Times(2).
+		Reply(200).
+		JSON(user(7, "2023-01-03T00:00:00Z", 3))
+	gock.New(baseURIinTests()).
+		Patch("/users/7").
+		BodyString(`"group_ids":\[3,10\]`).
+		Reply(200).
+		JSON(user(7, "2023-01-04T00:00:00Z", 3, 10))
+	gock.New(baseURIinTests()).
+		Get("/users/7").
+		Reply(200).
+		JSON(user(7, "2023-01-04T00:00:00Z", 3, 10))
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"groups",
+		"members",
+		"add",
+		"-o=csv",
+		"Eng",
+		"5",
+		"bob@example.com",
+		"7",
+	})
+	err := cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	st.Expect(t, strings.Split(strings.TrimSpace(out.String()), "\n"), []string{
+		"ID,Result",
+		"5,added",
+		"bob@example.com,already a member",
+		"7,added",
+	})
+}
+
+func TestGroupMembersRemoveConflict(t *testing.T) {
+	defer gock.Off()
+
+	user := map[string]interface{}{"id": 8, "enabled": true, "groups": []map[string]interface{}{{"id": 4}, {"id": 10}}}
+	// the user is added back to the group every time it is removed
+	gock.New(baseURIinTests()).
+		Get("/users/8").
+		Times(2 * groupMembersAttempts).
+		Reply(200).
+		JSON(user)
+	gock.New(baseURIinTests()).
+		Patch("/users/8").
+		Times(groupMembersAttempts).
+		BodyString(`"group_ids":\[4\]`).
+		Reply(200).
+		JSON(user)
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"groups",
+		"members",
+		"remove",
+		"-o=csv",
+		"--continue-on-error=false",
+		"10",
+		"8",
+	})
+	err := cmd.Execute()
+	st.Expect(t, err != nil, true)
+	st.Expect(t, gock.IsDone(), true)
+	st.Expect(t, strings.Contains(out.String(), "conflict"), true)
+}
+
+func TestParseGroupMembersInput(t *testing.T) {
+	users, err := parseGroupMembersInput([]byte(`[{"id": 5, "email": "a@example.com"}, {"email": "b@example.com"}]`))
+	st.Assert(t, err, nil)
+	st.Expect(t, users, []string{"5", "b@example.com"})
+
+	users, err = parseGroupMembersInput([]byte("# users\nalice@example.com\n\n12\n"))
+	st.Assert(t, err, nil)
+	st.Expect(t, users, []string{"alice@example.com", "12"})
+}
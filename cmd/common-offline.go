@@ -0,0 +1,299 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/thoas/go-funk"
+)
+
+// offlineEndpoint replaces the endpoint with --offline, as requests never
+// leave the process
+const offlineEndpoint = "offline.invalid"
+
+// offlinePaths maps the API paths of objects (after the tenant) to the
+// files of the dumps they are read from. Settings are read from the keys of
+// the settings file
+var offlinePaths = map[string]string{
+	"users":                 "users",
+	"groups":                "groups",
+	"devices":               "devices",
+	"access_resources":      "resources",
+	"access_policies":       "policies",
+	"access_proxies":        "proxies",
+	"admins":                "admins",
+	"assets":                "assets",
+	"asset_sources":         "sources",
+	"dns_security/rulesets": "webpolicies",
+	"app_configuration":     "settings/agent_configuration",
+	"analytics_settings":    "settings/analytics",
+	"enrollment_settings":   "settings/enrollment",
+}
+
+var offlinePathRegexp = regexp.MustCompile(`^/api/v[0-9]+/tenants/[^/]*/(.+?)/?$`)
+
+// offlineParams are the query parameters of list requests that are not
+// filters
+var offlineParams = []string{"page", "per_page", "q", "sort"}
+
+// offlineTransport serves GET requests from a directory of saved list
+// output (or an export), emulating the pagination, search, sorting and
+// filtering of the API. Other requests fail
+type offlineTransport struct {
+	dir string
+}
+
+func (t *offlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("%s %s is not possible with --offline", req.Method, req.URL.Path)
+	}
+	m := offlinePathRegexp.FindStringSubmatch(req.URL.Path)
+	if m == nil {
+		return nil, fmt.Errorf("GET %s is not available with --offline", req.URL.Path)
+	}
+	path := m[1]
+
+	// objects are requested either as a whole (lists, settings and web
+	// policy rulesets) or by ID
+	id := ""
+	kind, ok := offlinePaths[path]
+	if !ok {
+		if i := strings.LastIndex(path, "/"); i > 0 {
+			kind, ok = offlinePaths[path[:i]]
+			id = path[i+1:]
+		}
+	}
+	if !ok || (id != "" && strings.Contains(kind, "/")) {
+		return nil, fmt.Errorf("GET %s is not available with --offline", req.URL.Path)
+	}
+
+	objects, err := t.read(kind)
+	if err != nil {
+		return nil, err
+	}
+	if objects == nil {
+		return offlineResponse(req, http.StatusNotFound, map[string]interface{}{"error": "no " + kind + " in " + t.dir}, nil)
+	}
+
+	list, isList := objects.([]interface{})
+	switch {
+	case id != "" && isList:
+		for _, item := range list {
+			if m, ok := item.(map[string]interface{}); ok && fmt.Sprint(m["id"]) == id {
+				return offlineResponse(req, http.StatusOK, item, nil)
+			}
+		}
+		return offlineResponse(req, http.StatusNotFound, map[string]interface{}{"error": "not found"}, nil)
+	case id != "":
+		return nil, fmt.Errorf("GET %s is not available with --offline", req.URL.Path)
+	case !isList:
+		return offlineResponse(req, http.StatusOK, objects, nil)
+	}
+
+	query := req.URL.Query()
+	list = offlineFilter(list, query)
+	offlineSort(list, query.Get("sort"))
+	total := len(list)
+	if perPage, err := strconv.Atoi(query.Get("per_page")); err == nil && perPage > 0 {
+		page, err := strconv.Atoi(query.Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		start, end := (page-1)*perPage, page*perPage
+		if start > len(list) {
+			start = len(list)
+		}
+		if end > len(list) {
+			end = len(list)
+		}
+		list = list[start:end]
+	}
+	return offlineResponse(req, http.StatusOK, list, http.Header{"Total": []string{strconv.Itoa(total)}})
+}
+
+// read returns the objects of kind from the dumps, or nil if there are
+// none. Kinds of the form file/key are read from a key of a file
+func (t *offlineTransport) read(kind string) (interface{}, error) {
+	parts := strings.SplitN(kind, "/", 2)
+	data, err := readExportFile(t.dir, parts[0])
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var objects interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("%s: %v", parts[0], err)
+	}
+	if len(parts) > 1 {
+		m, _ := objects.(map[string]interface{})
+		return m[parts[1]], nil
+	}
+	return objects, nil
+}
+
+func offlineResponse(req *http.Request, status int, body interface{}, header http.Header) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+		Request:       req,
+	}, nil
+}
+
+// offlineFilter returns the items of list matching the search query (q)
+// and the filters in query
+func offlineFilter(list []interface{}, query url.Values) []interface{} {
+	filtered := []interface{}{}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		match := true
+		for key, values := range query {
+			if key == "q" {
+				match = match && offlineSearchMatch(m, values[0])
+				continue
+			}
+			if funk.ContainsString(offlineParams, key) {
+				continue
+			}
+			match = match && offlineFilterMatch(m, strings.TrimSuffix(key, "[]"), values)
+		}
+		if match {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// offlineSearchMatch reports whether any of the text fields of m contain
+// q, ignoring case
+func offlineSearchMatch(m map[string]interface{}, q string) bool {
+	q = strings.ToLower(q)
+	for _, v := range m {
+		if s, ok := v.(string); ok && strings.Contains(strings.ToLower(s), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// offlineFilterMatch reports whether the value of the field key of m is
+// one of values. Filters on references, such as group_id, match the IDs or
+// names of the referenced objects, such as those in groups
+func offlineFilterMatch(m map[string]interface{}, key string, values []string) bool {
+	wanted := []string{}
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			wanted = append(wanted, strings.ToLower(s))
+		}
+	}
+	matches := func(v interface{}) bool {
+		return funk.ContainsString(wanted, strings.ToLower(fmt.Sprint(v)))
+	}
+	matchesAny := func(v interface{}, field string) bool {
+		switch value := v.(type) {
+		case []interface{}:
+			for _, item := range value {
+				if ref, ok := item.(map[string]interface{}); ok && field != "" {
+					item = ref[field]
+				}
+				if matches(item) {
+					return true
+				}
+			}
+			return false
+		case map[string]interface{}:
+			return field != "" && matches(value[field])
+		case nil:
+			return false
+		}
+		return field == "" && matches(v)
+	}
+
+	if v, ok := m[key]; ok {
+		return matchesAny(v, "")
+	}
+	for _, suffix := range []string{"_id", "_name"} {
+		if !strings.HasSuffix(key, suffix) {
+			continue
+		}
+		ref := strings.TrimSuffix(key, suffix)
+		for _, name := range []string{ref, pluralize(ref), "access_" + ref, "access_" + pluralize(ref)} {
+			if v, ok := m[name]; ok {
+				return matchesAny(v, suffix[1:])
+			}
+		}
+	}
+	return false
+}
+
+// offlineSort sorts list as the API does for sort values like name_asc or
+// created_desc
+func offlineSort(list []interface{}, sortBy string) {
+	i := strings.LastIndex(sortBy, "_")
+	if i < 0 {
+		return
+	}
+	field, desc := sortBy[:i], sortBy[i+1:] == "desc"
+	switch field {
+	case "created", "updated":
+		field += "_at"
+	}
+	value := func(item interface{}) interface{} {
+		m, _ := item.(map[string]interface{})
+		return m[field]
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := value(list[i]), value(list[j])
+		if desc {
+			a, b = b, a
+		}
+		na, errA := strconv.ParseFloat(fmt.Sprint(a), 64)
+		nb, errB := strconv.ParseFloat(fmt.Sprint(b), 64)
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return strings.ToLower(fmt.Sprint(a)) < strings.ToLower(fmt.Sprint(b))
+	})
+}
@@ -0,0 +1,124 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+	"github.com/spf13/pflag"
+)
+
+func TestOfflineList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer rootCmd.PersistentFlags().Set("offline", "")
+	defer usersListCmd.Flags().Set("search", "")
+	defer usersListCmd.Flags().Set("sort", "")
+	defer func() {
+		// slice flags append when set again, so they are reset explicitly
+		f := usersListCmd.Flags().Lookup("filter-group")
+		f.Value.(pflag.SliceValue).Replace([]string{})
+		f.Changed = false
+	}()
+	defer usersListCmd.Flags().Set("range-start", "1")
+	defer usersListCmd.Flags().Set("range-end", "-1")
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "users.json"), []byte(`[
+		{"id": 1, "name": "Alice", "email": "alice@example.com", "groups": [{"id": 10, "name": "Sales"}]},
+		{"id": 2, "name": "Bob", "email": "bob@example.com", "groups": [{"id": 10, "name": "Sales"}]},
+		{"id": 3, "name": "Carol", "email": "carol@example.org", "groups": [{"id": 10, "name": "Sales"}]},
+		{"id": 4, "name": "Dave", "email": "dave@example.com", "groups": []}
+	]`), 0600), nil)
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"users",
+		"list",
+		"-o=json",
+		"--offline", dir,
+		"--search", "EXAMPLE.COM",
+		"--filter-group", "10",
+		"--sort", "name_desc",
+		"--range-start", "2",
+		"--range-end", "3",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+
+	users := []map[string]interface{}{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &users), nil)
+	st.Assert(t, len(users), 1)
+	st.Expect(t, users[0]["name"], "Alice")
+
+	out.Reset()
+	cmd.SetArgs([]string{
+		"users",
+		"get",
+		"3",
+		"-o=json",
+		"--offline", dir,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+
+	user := map[string]interface{}{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &user), nil)
+	st.Expect(t, user["email"], "carol@example.org")
+}
+
+func TestOfflineListSkipTLSVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer rootCmd.PersistentFlags().Set("offline", "")
+	authViper.Set(ckeyAuthSkipTLSVerify, true)
+	defer authViper.Set(ckeyAuthSkipTLSVerify, false)
+
+	st.Assert(t, ioutil.WriteFile(filepath.Join(dir, "users.json"), []byte(`[
+		{"id": 1, "name": "Alice", "email": "alice@example.com", "groups": []}
+	]`), 0600), nil)
+
+	// offline data is still read from the directory, rather than requested
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"users",
+		"list",
+		"-o=json",
+		"--offline", dir,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+
+	users := []map[string]interface{}{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &users), nil)
+	st.Assert(t, len(users), 1)
+	st.Expect(t, users[0]["name"], "Alice")
+}
@@ -18,9 +18,13 @@ limitations under the License.
 */
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/jedib0t/go-pretty/v6/table"
@@ -39,7 +43,9 @@ func initOutputFlags(cmd *cobra.Command) {
 	if term.IsTerminal(int(os.Stdout.Fd())) {
 		d = "table"
 	}
-	cmd.Flags().StringP("output", "o", d, "output format (table, json, json-pretty or csv) (default \"json\" if pipe)")
+	cmd.Flags().StringP("output", "o", d, "output format (table, json, json-pretty, csv or csv-full) (default \"json\" if pipe)")
+	cmd.Flags().String("output-file", "", "write output to the specified file instead of stdout (gzip-compressed if the file name ends in .gz)")
+	cmd.Flags().Bool("summary", false, "when writing output to a file, also print a summary to stdout")
 	cmd.Flags().SetNormalizeFunc(aliasNormalizeFunc)
 }
 
@@ -62,7 +68,11 @@ func preRunFlagCheckOutput(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !funk.Contains([]string{"table", "json", "json-pretty", "csv"}, output) {
+	formats := []string{"table", "json", "json-pretty", "csv", "csv-full"}
+	if _, ok := cmd.Annotations[flagInitSCIMOutput]; ok {
+		formats = append(formats, "scim")
+	}
+	if !funk.Contains(formats, output) {
 		return fmt.Errorf("invalid output format %s", output)
 	}
 	return nil
@@ -77,47 +87,135 @@ func renderListOutput(cmd *cobra.Command, data interface{}, tableWriter table.Wr
 	if err != nil {
 		return "", err
 	}
+	outputFile, _ := cmd.Flags().GetString("output-file")
 	switch outputFormat {
 	case "table":
-		if term.IsTerminal(int(os.Stdout.Fd())) {
+		if term.IsTerminal(int(os.Stdout.Fd())) && outputFile == "" {
 			width, _, err := term.GetSize(int(os.Stdout.Fd()))
 			if err == nil {
 				tableWriter.SetAllowedRowLength(width)
 			}
 		}
-		totalsMessage := ""
-		plural := "s"
-		if tableWriter.Length() == 1 {
-			plural = ""
-		}
-		if tableWriter.Length() != total {
-			totalsMessage = fmt.Sprintf("\n(%d record%s out of %d)",
-				tableWriter.Length(), plural, total)
-		} else {
-			totalsMessage = fmt.Sprintf("\n(%d record%s)", total, plural)
-		}
-		return tableWriter.Render() + totalsMessage, nil
+		return tableWriter.Render() + "\n" + renderTotalsMessage(tableWriter, total), nil
 	case "csv":
 		return tableWriter.RenderCSV(), nil
+	case "csv-full":
+		return renderFlattenedCSV(data)
 	case "json":
 		return renderJSON(data)
 	case "json-pretty":
 		return renderPrettyJSON(data)
+	case "scim":
+		// commands supporting SCIM output convert their data beforehand
+		return renderPrettyJSON(data)
 	default:
 		return "", fmt.Errorf("unsupported output format %s", outputFormat)
 	}
 }
 
+func renderTotalsMessage(tableWriter table.Writer, total int) string {
+	plural := "s"
+	if tableWriter.Length() == 1 {
+		plural = ""
+	}
+	if tableWriter.Length() != total {
+		return fmt.Sprintf("(%d record%s out of %d)", tableWriter.Length(), plural, total)
+	}
+	return fmt.Sprintf("(%d record%s)", total, plural)
+}
+
 func printListOutputAndError(cmd *cobra.Command, data interface{}, tableWriter table.Writer, total int, loopErr error) error {
 	cmd.SilenceUsage = true
+	if format := inputPrintTemplate(cmd); format != "" && loopErr == nil {
+		template, err := renderInputTemplate(global.InputData[cmd].fields, format)
+		if err != nil {
+			return err
+		}
+		cmd.Println(template)
+		return nil
+	}
+	if input, ok := global.InputData[cmd]; ok && input.validation != nil {
+		// with --validate-only, the validation report replaces the command output
+		var j []multiOpJSONResult
+		tableWriter, j = renderInputValidationReport(input.validation)
+		data, total = j, len(j)
+	} else if global.DryRun && len(global.DryRunRequests) > 0 {
+		// with --dry-run, the requests that were held back replace the command output
+		var j []multiOpJSONResult
+		tableWriter, j = renderDryRunReport(global.DryRunRequests)
+		data, total = j, len(j)
+	}
+	if isDryRunError(loopErr) {
+		loopErr = nil
+	}
 	result, err2 := renderListOutput(cmd, data, tableWriter, total)
-	cmd.Println(result)
+
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	if outputFile == "" || err2 != nil || loopErr != nil {
+		// when the command fails, the (partial) output goes to stdout
+		// and any existing output file is left untouched
+		cmd.Println(result)
+	} else {
+		err2 = writeFileAtomically(outputFile, func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, result)
+			return err
+		})
+		if summary, _ := cmd.Flags().GetBool("summary"); summary && err2 == nil {
+			cmd.Printf("Output written to %s %s\n", outputFile, renderTotalsMessage(tableWriter, total))
+		}
+	}
+
+	if input, ok := global.InputData[cmd]; ok && input.journal != nil {
+		cmd.PrintErrf("Journal: %s\n", input.journal)
+	}
+
 	if loopErr != nil {
 		return processErrorResponse(loopErr)
 	}
 	return err2
 }
 
+// writeFileAtomically calls write to write the contents of the file at path.
+// The contents are written to a temporary file in the same directory, which
+// is only renamed to path if write succeeds, so that existing files are never
+// left half-written. If path ends in .gz, contents are gzip-compressed
+func writeFileAtomically(path string, write func(w io.Writer) error) (err error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz := gzip.NewWriter(tmp)
+		if err = write(gz); err != nil {
+			return err
+		}
+		if err = gz.Close(); err != nil {
+			return err
+		}
+	} else if err = write(tmp); err != nil {
+		return err
+	}
+
+	if err = tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 func renderWatchOutput(cmd *cobra.Command, data interface{}, tableWriter table.Writer) (bool, string, error) {
 	cmd.SilenceUsage = true
 	if _, ok := cmd.Annotations[flagInitOutput]; !ok {
@@ -139,6 +237,9 @@ func renderWatchOutput(cmd *cobra.Command, data interface{}, tableWriter table.W
 		return true, tableWriter.Render(), nil
 	case "csv":
 		return false, tableWriter.RenderCSV(), nil
+	case "csv-full":
+		o, err := renderFlattenedCSV(data)
+		return false, o, err
 	case "json":
 		o, err := renderJSON(data)
 		return false, o, err
@@ -0,0 +1,80 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestWriteFileAtomically(t *testing.T) {
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Expect(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.json")
+	err = writeFileAtomically(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "first")
+		return err
+	})
+	st.Expect(t, err, nil)
+
+	// a failing write must leave the previous contents in place
+	err = writeFileAtomically(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return fmt.Errorf("failed")
+	})
+	st.Reject(t, err, nil)
+
+	contents, err := ioutil.ReadFile(path)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(contents), "first")
+
+	files, err := ioutil.ReadDir(dir)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(files), 1)
+}
+
+func TestWriteFileAtomicallyGzip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Expect(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "out.csv.gz")
+	err = writeFileAtomically(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "id,name\n1,test\n")
+		return err
+	})
+	st.Expect(t, err, nil)
+
+	f, err := os.Open(path)
+	st.Expect(t, err, nil)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	st.Expect(t, err, nil)
+	contents, err := ioutil.ReadAll(gz)
+	st.Expect(t, err, nil)
+	st.Expect(t, string(contents), "id,name\n1,test\n")
+}
@@ -113,31 +113,49 @@ func forAllPages(cmd *cobra.Command, params pageable, do func() (int, int64, err
 	}
 
 	perPage := int64(global.FetchPerPage)
-	total := int64(math.MaxInt64)
 	curPage := rangeStart / perPage
 	sliceStart = rangeStart - curPage*perPage
 	sliceEnd = rangeEnd - curPage*perPage
+	totalAdded, err := fetchPages(params, rangeStart, rangeEnd, do)
+	if err != nil {
+		return 0, 0, err
+	}
+	if sliceStart > int64(totalAdded) {
+		sliceStart = int64(totalAdded)
+	}
+	if sliceEnd > int64(totalAdded) {
+		sliceEnd = int64(totalAdded)
+	}
+	return sliceStart, sliceEnd, nil
+}
+
+// listAllPages fetches every page of a listing.
+// fetch must return the number of items in the page and the total number of items
+func listAllPages(params pageable, fetch func() (int, int64, error)) error {
+	_, err := fetchPages(params, 0, math.MaxInt64, fetch)
+	return err
+}
+
+// fetchPages fetches the pages holding the items from rangeStart up to
+// rangeEnd (0-based), and returns the number of items added by do
+func fetchPages(params pageable, rangeStart, rangeEnd int64, do func() (int, int64, error)) (int, error) {
+	perPage := int64(global.FetchPerPage)
+	total := int64(math.MaxInt64)
 	lastPage := rangeEnd / perPage
 	if rangeEnd%perPage != 0 {
 		lastPage++
 	}
 	totalAdded := 0
-	for ; curPage < lastPage && perPage*curPage < total; curPage++ {
+	for curPage := rangeStart / perPage; curPage < lastPage && perPage*curPage < total; curPage++ {
 		p := curPage + 1
 		params.SetPage(&p)
 		params.SetPerPage(&perPage)
-		added := 0
-		added, total, err = do()
+		added, pageTotal, err := do()
 		if err != nil {
-			return 0, 0, err
+			return 0, err
 		}
+		total = pageTotal
 		totalAdded += added
 	}
-	if sliceStart > int64(totalAdded) {
-		sliceStart = int64(totalAdded)
-	}
-	if sliceEnd > int64(totalAdded) {
-		sliceEnd = int64(totalAdded)
-	}
-	return sliceStart, sliceEnd, nil
+	return totalAdded, nil
 }
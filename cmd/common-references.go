@@ -0,0 +1,462 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/spf13/cobra"
+)
+
+// referenceCandidate is a record that may be the target of a reference by name
+type referenceCandidate struct {
+	id    interface{} // int64, or string for UUIDs
+	names []string    // names by which the record can be referenced
+}
+
+// referenceKind describes a kind of record that input fields can reference
+type referenceKind struct {
+	uuid bool // whether IDs are UUIDs, rather than integers
+	// search returns the records matching query, which may include
+	// records whose names are not an exact match
+	search func(cmd *cobra.Command, query string) ([]referenceCandidate, error)
+}
+
+var referenceKinds = map[string]referenceKind{
+	"user": {
+		search: func(cmd *cobra.Command, query string) ([]referenceCandidate, error) {
+			items, err := listAllUsers(cmd, query)
+			candidates := []referenceCandidate{}
+			for _, item := range items {
+				candidates = append(candidates, referenceCandidate{
+					id:    item.ID,
+					names: []string{item.Name, string(item.Email)},
+				})
+			}
+			return candidates, err
+		},
+	},
+	"group": {
+		search: func(cmd *cobra.Command, query string) ([]referenceCandidate, error) {
+			items, err := listAllGroups(cmd, query)
+			candidates := []referenceCandidate{}
+			for _, item := range items {
+				candidates = append(candidates, referenceCandidate{
+					id:    item.ID,
+					names: []string{item.Name},
+				})
+			}
+			return candidates, err
+		},
+	},
+	"policy": {
+		search: func(cmd *cobra.Command, query string) ([]referenceCandidate, error) {
+			items, err := listAllPolicies(cmd, query)
+			candidates := []referenceCandidate{}
+			for _, item := range items {
+				candidates = append(candidates, referenceCandidate{
+					id:    item.ID,
+					names: []string{item.Name},
+				})
+			}
+			return candidates, err
+		},
+	},
+	"proxy": {
+		uuid: true,
+		search: func(cmd *cobra.Command, query string) ([]referenceCandidate, error) {
+			items, err := listAllProxies(cmd, query)
+			candidates := []referenceCandidate{}
+			for _, item := range items {
+				candidates = append(candidates, referenceCandidate{
+					id:    string(item.ID),
+					names: []string{item.Name},
+				})
+			}
+			return candidates, err
+		},
+	},
+	"resource": {
+		uuid: true,
+		search: func(cmd *cobra.Command, query string) ([]referenceCandidate, error) {
+			items, err := listAllResources(cmd, query)
+			candidates := []referenceCandidate{}
+			for _, item := range items {
+				candidates = append(candidates, referenceCandidate{
+					id:    string(item.ID),
+					names: []string{item.Name},
+				})
+			}
+			return candidates, err
+		},
+	},
+}
+
+func inputHasReferences(fields []inputField) bool {
+	for _, field := range fields {
+		if field.Reference != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func inputStrictIDs(cmd *cobra.Command) bool {
+	if _, ok := cmd.Annotations[flagInitInput]; !ok {
+		return true
+	}
+	strict, err := cmd.Flags().GetBool("strict-ids")
+	if err != nil {
+		// command has no fields referencing other records
+		return true
+	}
+	return strict
+}
+
+// referenceResolver resolves references to other records by name, caching
+// the results so that each name is only looked up once
+type referenceResolver struct {
+	cmd    *cobra.Command
+	strict bool
+	mutex  sync.Mutex
+	cache  map[string]*referenceLookup
+}
+
+// referenceLookup is the lookup of a name, shared by all the goroutines
+// resolving it. done is closed once id and err are set
+type referenceLookup struct {
+	done chan struct{}
+	id   interface{}
+	err  error
+}
+
+func newReferenceResolver(cmd *cobra.Command) *referenceResolver {
+	return &referenceResolver{
+		cmd:    cmd,
+		strict: inputStrictIDs(cmd),
+		cache:  make(map[string]*referenceLookup),
+	}
+}
+
+// resolve returns the ID of the record of the given kind referenced by value,
+// which may be either an ID or a name (or email, for users).
+// IDs are returned as int64, or as strings for UUIDs
+func (r *referenceResolver) resolve(kind, value string) (interface{}, error) {
+	k, ok := referenceKinds[kind]
+	if !ok {
+		panic("resolve called for unknown record kind " + kind + ". This is a bug!")
+	}
+	value = strings.TrimSpace(value)
+	if k.uuid {
+		if strfmt.IsUUID(value) {
+			return value, nil
+		}
+	} else if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return id, nil
+	}
+	if r.strict {
+		return nil, fmt.Errorf("invalid %s ID %q", kind, value)
+	}
+
+	// the lock only guards the cache, so that lookups of different names
+	// are not serialized; lookups of the same name wait for the first one
+	key := kind + "\x00" + strings.ToLower(value)
+	r.mutex.Lock()
+	lookup, ok := r.cache[key]
+	if !ok {
+		lookup = &referenceLookup{done: make(chan struct{})}
+		r.cache[key] = lookup
+	}
+	r.mutex.Unlock()
+	if ok {
+		<-lookup.done
+		return lookup.id, lookup.err
+	}
+
+	lookup.id, lookup.err = r.search(k, kind, value)
+	if lookup.err != nil {
+		// failed lookups are retried by later records
+		r.mutex.Lock()
+		delete(r.cache, key)
+		r.mutex.Unlock()
+	}
+	close(lookup.done)
+	return lookup.id, lookup.err
+}
+
+// search looks up the record of the given kind whose name is value
+func (r *referenceResolver) search(k referenceKind, kind, value string) (interface{}, error) {
+	candidates, err := k.search(r.cmd, value)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s %q: %v", kind, value, processErrorResponse(err))
+	}
+	ids := []string{}
+	var id interface{}
+	for _, c := range candidates {
+		for _, name := range c.names {
+			if strings.EqualFold(name, value) {
+				id = c.id
+				ids = append(ids, fmt.Sprint(c.id))
+				break
+			}
+		}
+	}
+	switch {
+	case len(ids) == 0:
+		return nil, fmt.Errorf("no %s named %q", kind, value)
+	case len(ids) > 1:
+		return nil, fmt.Errorf("%s name %q is ambiguous, it matches IDs %s", kind, value, strings.Join(ids, ", "))
+	}
+	return id, nil
+}
+
+// resolveList resolves each of the values, returning []int for integer IDs
+// and []string for UUIDs, as expected for flag values of those types
+func (r *referenceResolver) resolveList(kind string, values []string) (interface{}, error) {
+	ints := []int{}
+	strs := []string{}
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		id, err := r.resolve(kind, value)
+		if err != nil {
+			return nil, err
+		}
+		switch v := id.(type) {
+		case int64:
+			ints = append(ints, int(v))
+		default:
+			strs = append(strs, fmt.Sprint(v))
+		}
+	}
+	if referenceKinds[kind].uuid {
+		return strs, nil
+	}
+	return ints, nil
+}
+
+// getReferenceFlagValue is like getFlagValue, for fields referencing other
+// records, which may contain names. List values are returned as []string,
+// to be resolved by resolveEntry
+func getReferenceFlagValue(cmd *cobra.Command, field inputField) (interface{}, error) {
+	if !cmd.Flags().Changed(field.FlagName) {
+		return nil, fmt.Errorf("user did not specify %s", field.FlagName)
+	}
+	switch field.VarType {
+	case "[]int", "[]string":
+		values, err := cmd.Flags().GetStringSlice(field.FlagName)
+		if err != nil {
+			return nil, err
+		}
+		return commaSeparatedListToStringSlice(strings.Join(values, ",")), nil
+	default:
+		return getFlagValue(cmd, field.VarType, field.FlagName)
+	}
+}
+
+// resolveEntry returns a copy of entry where the references to other
+// records by name are replaced by the IDs of those records
+func (r *referenceResolver) resolveEntry(fields []inputField, entry *inputEntry) (*inputEntry, error) {
+	resolved := *entry
+	switch entry.Type {
+	case individualValues:
+		resolved.Values = append([]interface{}{}, entry.Values...)
+		for i, field := range fields {
+			if field.Reference == "" || resolved.Values[i] == nil {
+				continue
+			}
+			var err error
+			switch v := resolved.Values[i].(type) {
+			case []string:
+				resolved.Values[i], err = r.resolveList(field.Reference, v)
+			case string:
+				if v != "" {
+					var id interface{}
+					id, err = r.resolve(field.Reference, v)
+					resolved.Values[i] = fmt.Sprint(id)
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", field.FlagName, err)
+			}
+		}
+	case wholeJSONObject:
+		var record map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(entry.JSON))
+		decoder.UseNumber()
+		if err := decoder.Decode(&record); err != nil || record == nil {
+			// let the command report the problem
+			return entry, nil
+		}
+		changed := false
+		for _, field := range fields {
+			if field.Reference == "" {
+				continue
+			}
+			err := rewriteSchemaValue(record, field, func(value interface{}) (interface{}, error) {
+				return r.resolveJSONValue(field.Reference, value, &changed)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", field.SchemaName, err)
+			}
+		}
+		if changed {
+			j, err := json.Marshal(record)
+			if err != nil {
+				return nil, err
+			}
+			resolved.JSON = j
+		}
+	case wholeCSVObject:
+		record, ok := entry.CSVdata.(map[string]interface{})
+		if !ok {
+			return entry, nil
+		}
+		record = copyCSVRecord(record)
+		for _, field := range fields {
+			if field.Reference == "" {
+				continue
+			}
+			err := rewriteSchemaValue(record, field, func(value interface{}) (interface{}, error) {
+				s, ok := value.(string)
+				if !ok || strings.Trim(s, "[] ") == "" {
+					return value, nil
+				}
+				if !strings.HasPrefix(field.VarType, "[]") {
+					id, err := r.resolve(field.Reference, s)
+					return fmt.Sprint(id), err
+				}
+				ids := []string{}
+				for _, name := range commaSeparatedListToStringSlice(s) {
+					if name == "" {
+						continue
+					}
+					id, err := r.resolve(field.Reference, name)
+					if err != nil {
+						return nil, err
+					}
+					ids = append(ids, fmt.Sprint(id))
+				}
+				return strings.Join(ids, ","), nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", field.SchemaName, err)
+			}
+		}
+		resolved.CSVdata = record
+	}
+	return &resolved, nil
+}
+
+// resolveJSONValue resolves the names in a value read from a JSON record,
+// which may be a single reference or a list of references, where each
+// reference is an ID, a name or an object with an id
+func (r *referenceResolver) resolveJSONValue(kind string, value interface{}, changed *bool) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return v, nil
+		}
+		id, err := r.resolve(kind, v)
+		if err != nil {
+			return nil, err
+		}
+		*changed = true
+		return id, nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, e := range v {
+			var err error
+			list[i], err = r.resolveJSONValue(kind, e, changed)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case map[string]interface{}:
+		if id, ok := v["id"].(string); ok {
+			resolved, err := r.resolve(kind, id)
+			if err != nil {
+				return nil, err
+			}
+			*changed = true
+			o := make(map[string]interface{})
+			for key, value := range v {
+				o[key] = value
+			}
+			o["id"] = resolved
+			return o, nil
+		}
+	}
+	return value, nil
+}
+
+// rewriteSchemaValue replaces the value of field in record, if present,
+// by the result of rewrite
+func rewriteSchemaValue(record map[string]interface{}, field inputField, rewrite func(interface{}) (interface{}, error)) error {
+	for _, name := range fieldSchemaNames(field) {
+		parts := strings.Split(name, ".")
+		m := record
+		for i, part := range parts {
+			key, found := "", false
+			for k := range m {
+				if normalizeSchemaName(k) == normalizeSchemaName(part) {
+					key, found = k, true
+					break
+				}
+			}
+			if !found {
+				break
+			}
+			if i < len(parts)-1 {
+				next, ok := m[key].(map[string]interface{})
+				if !ok {
+					break
+				}
+				m = next
+				continue
+			}
+			value, err := rewrite(m[key])
+			if err != nil {
+				return err
+			}
+			m[key] = value
+		}
+	}
+	return nil
+}
+
+// copyCSVRecord copies the nested maps of a record read from a CSV file,
+// so that it can be modified without affecting the original
+func copyCSVRecord(record map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(record))
+	for key, value := range record {
+		if m, ok := value.(map[string]interface{}); ok {
+			value = copyCSVRecord(m)
+		}
+		c[key] = value
+	}
+	return c
+}
@@ -0,0 +1,159 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nbio/st"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func runUsersAddWithGroups(groups string, extraArgs ...string) (string, error) {
+	cmd := rootCmd
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(append([]string{
+		"users",
+		"add",
+		"-o=json",
+		"--continue-on-error=false",
+		"--name=Alice",
+		"--email=alice@example.com",
+		"--groups=" + groups,
+	}, extraArgs...))
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func resetUsersAddGroupFlags() {
+	for _, flag := range []string{"name", "email", "groups", "strict-ids"} {
+		f := usersAddCmd.Flags().Lookup(flag)
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			// slice flags append to their value once set
+			v.Replace([]string{})
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+}
+
+func TestAddUserWithGroupNames(t *testing.T) {
+	defer gock.Off()
+	defer resetUsersAddGroupFlags()
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "engineering").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 7, "name": "Engineering"},
+			{"id": 8, "name": "Engineering Ops"},
+		})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		AddMatcher(func(req *http.Request, _ *gock.Request) (bool, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			return strings.Contains(string(body), `"group_ids":[7,5]`), err
+		}).
+		Reply(201).
+		JSON(map[string]interface{}{"id": 101, "name": "Alice", "email": "alice@example.com"})
+
+	_, err := runUsersAddWithGroups("engineering,5")
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+}
+
+func TestAddUserWithAmbiguousGroupName(t *testing.T) {
+	defer gock.Off()
+	defer resetUsersAddGroupFlags()
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "Ops").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 1, "name": "Ops"},
+			{"id": 2, "name": "ops"},
+		})
+
+	output, err := runUsersAddWithGroups("Ops")
+	st.Expect(t, err != nil, true)
+	st.Expect(t, strings.Contains(output, `group name "Ops" is ambiguous, it matches IDs 1, 2`), true)
+}
+
+func TestAddUserWithGroupNamesStrict(t *testing.T) {
+	defer gock.Off()
+	defer resetUsersAddGroupFlags()
+
+	output, err := runUsersAddWithGroups("Engineering", "--strict-ids")
+	st.Expect(t, err != nil, true)
+	st.Expect(t, strings.Contains(output, `invalid group ID "Engineering"`), true)
+}
+
+func TestReferenceResolverConcurrentLookups(t *testing.T) {
+	kind := referenceKinds["group"]
+	defer func() { referenceKinds["group"] = kind }()
+
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	searches := map[string]int{}
+	referenceKinds["group"] = referenceKind{
+		search: func(cmd *cobra.Command, query string) ([]referenceCandidate, error) {
+			mutex.Lock()
+			searches[query]++
+			mutex.Unlock()
+			if query == "Slow" {
+				<-release
+			}
+			return []referenceCandidate{{id: int64(len(query)), names: []string{query}}}, nil
+		},
+	}
+
+	r := &referenceResolver{cmd: usersAddCmd, cache: make(map[string]*referenceLookup)}
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := r.resolve("group", "Slow")
+			st.Expect(t, err, nil)
+			st.Expect(t, id, int64(4))
+		}()
+	}
+
+	// a slow lookup does not hold back lookups of other names
+	id, err := r.resolve("group", "Quick")
+	st.Assert(t, err, nil)
+	st.Expect(t, id, int64(5))
+
+	close(release)
+	wg.Wait()
+	st.Expect(t, searches, map[string]int{"Slow": 1, "Quick": 1})
+}
@@ -0,0 +1,354 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func inputPrintTemplate(cmd *cobra.Command) string {
+	if _, ok := cmd.Annotations[flagInitInput]; !ok {
+		return ""
+	}
+	template, err := cmd.Flags().GetString("print-template")
+	if err != nil {
+		return ""
+	}
+	return template
+}
+
+// schemaNode is an element of the record layout described by the schema
+// names of a command's input fields. Dotted schema names (e.g.
+// conditions.rbac.enabled) result in nested nodes
+type schemaNode struct {
+	key      string
+	field    *inputField
+	children []*schemaNode
+}
+
+func (n *schemaNode) child(key string) *schemaNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	c := &schemaNode{key: key}
+	n.children = append(n.children, c)
+	return c
+}
+
+func buildSchemaTree(fields []inputField) *schemaNode {
+	root := &schemaNode{}
+	for i := range fields {
+		if fields[i].SchemaName == "" {
+			continue
+		}
+		node := root
+		for _, part := range strings.Split(fields[i].SchemaName, ".") {
+			node = node.child(part)
+		}
+		node.field = &fields[i]
+	}
+	return root
+}
+
+func inputFieldVarType(field *inputField) string {
+	if field.SchemaVarType != "" {
+		return field.SchemaVarType
+	}
+	return field.VarType
+}
+
+func inputFieldTypeDescription(field *inputField) string {
+	switch inputFieldVarType(field) {
+	case "string":
+		return "string"
+	case "int":
+		return "integer"
+	case "int.nullable":
+		return "integer or null"
+	case "bool":
+		return "boolean"
+	case "[]string", "[]string.skipcomma":
+		return "list of strings"
+	case "[]int":
+		return "list of integers"
+	case "dnsservers":
+		return "object with protocol and list"
+	case "[]portmapping":
+		return "list of port mappings (external:internal:protocol)"
+	default:
+		panic("Unknown input variable type " + inputFieldVarType(field))
+	}
+}
+
+// inputFieldPlaceholder describes a field in templates for formats
+// that do not support comments
+func inputFieldPlaceholder(field *inputField) string {
+	if field.Mandatory {
+		return fmt.Sprintf("<%s, mandatory>", inputFieldTypeDescription(field))
+	}
+	return fmt.Sprintf("<%s>", inputFieldTypeDescription(field))
+}
+
+// inputFieldSample returns a value of the right type for field,
+// to be used in templates for formats that support comments
+func inputFieldSample(field *inputField) interface{} {
+	switch inputFieldVarType(field) {
+	case "string":
+		return ""
+	case "int":
+		return 0
+	case "int.nullable":
+		return nil
+	case "bool":
+		return false
+	case "[]string", "[]string.skipcomma", "[]int", "[]portmapping":
+		return []interface{}{}
+	case "dnsservers":
+		return map[string]string{"protocol": "", "list": ""}
+	default:
+		panic("Unknown input variable type " + inputFieldVarType(field))
+	}
+}
+
+// renderInputTemplate renders a sample input file, with a single record,
+// for a command with the given input fields
+func renderInputTemplate(fields []inputField, format string) (string, error) {
+	root := buildSchemaTree(fields)
+	switch format {
+	case "csv":
+		header := []string{}
+		row := []string{}
+		var walk func(node *schemaNode, prefix string)
+		walk = func(node *schemaNode, prefix string) {
+			for _, c := range node.children {
+				if c.field != nil {
+					header = append(header, prefix+c.key)
+					row = append(row, inputFieldPlaceholder(c.field))
+				}
+				walk(c, prefix+c.key+".")
+			}
+		}
+		walk(root, "")
+
+		buf := new(bytes.Buffer)
+		w := csv.NewWriter(buf)
+		w.Write(header)
+		w.Write(row)
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	case "json":
+		buf := new(bytes.Buffer)
+		buf.WriteString("[\n  ")
+		writeJSONTemplateObject(buf, root, "  ")
+		buf.WriteString("\n]")
+		return buf.String(), nil
+	case "yaml":
+		record := yamlTemplateNode(root)
+		doc := &yaml.Node{
+			Kind:    yaml.SequenceNode,
+			Content: []*yaml.Node{record},
+		}
+		buf := new(bytes.Buffer)
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	default:
+		return "", fmt.Errorf("invalid template format %s", format)
+	}
+}
+
+// writeJSONTemplateObject writes node as a JSON object, keeping the order
+// in which the input fields were declared
+func writeJSONTemplateObject(buf *bytes.Buffer, node *schemaNode, indent string) {
+	buf.WriteString("{\n")
+	for i, c := range node.children {
+		buf.WriteString(indent + "  " + jsonTemplateString(c.key) + ": ")
+		if len(c.children) > 0 {
+			writeJSONTemplateObject(buf, c, indent+"  ")
+		} else {
+			buf.WriteString(jsonTemplateString(inputFieldPlaceholder(c.field)))
+		}
+		if i < len(node.children)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent + "}")
+}
+
+func jsonTemplateString(s string) string {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func yamlTemplateNode(node *schemaNode) *yaml.Node {
+	m := &yaml.Node{Kind: yaml.MappingNode}
+	for _, c := range node.children {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: c.key}
+		var value *yaml.Node
+		if len(c.children) > 0 {
+			value = yamlTemplateNode(c)
+		} else {
+			value = &yaml.Node{}
+			value.Encode(inputFieldSample(c.field))
+			if value.Kind == yaml.SequenceNode || value.Kind == yaml.MappingNode {
+				value.Style = yaml.FlowStyle
+			}
+			comment := fmt.Sprintf("%s, %s", c.field.Name, inputFieldTypeDescription(c.field))
+			if c.field.Mandatory {
+				comment += ", mandatory"
+			}
+			value.LineComment = comment
+		}
+		m.Content = append(m.Content, key, value)
+	}
+	return m
+}
+
+// buildInputJSONSchema returns a JSON Schema describing the input files
+// accepted by a command with the given input fields
+func buildInputJSONSchema(fields []inputField, title string) map[string]interface{} {
+	return map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   title,
+		"type":    "array",
+		"items":   jsonSchemaObject(buildSchemaTree(fields)),
+	}
+}
+
+func jsonSchemaObject(node *schemaNode) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+	for _, c := range node.children {
+		if len(c.children) > 0 {
+			properties[c.key] = jsonSchemaObject(c)
+			continue
+		}
+		properties[c.key] = jsonSchemaProperty(c.field)
+		if c.field.Mandatory {
+			required = append(required, c.key)
+		}
+		for _, alias := range c.field.SchemaAliases {
+			p := jsonSchemaProperty(c.field)
+			p["description"] = fmt.Sprintf("alias of %s", c.field.SchemaName)
+			properties[alias] = p
+		}
+	}
+	o := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		o["required"] = required
+	}
+	return o
+}
+
+func jsonSchemaProperty(field *inputField) map[string]interface{} {
+	var p map[string]interface{}
+	switch inputFieldVarType(field) {
+	case "string":
+		p = map[string]interface{}{"type": "string"}
+	case "int":
+		p = map[string]interface{}{"type": "integer"}
+	case "int.nullable":
+		p = map[string]interface{}{"type": []string{"integer", "null"}}
+	case "bool":
+		p = map[string]interface{}{"type": "boolean"}
+	case "[]string", "[]string.skipcomma":
+		p = map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		}
+	case "[]int":
+		id := map[string]interface{}{"type": "integer"}
+		if field.Reference != "" {
+			// records can also be referenced by name
+			id = map[string]interface{}{"type": []string{"integer", "string"}}
+		}
+		p = map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"oneOf": []interface{}{
+					id,
+					map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"id": id},
+						"required":   []string{"id"},
+					},
+				},
+			},
+		}
+	case "dnsservers":
+		p = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"protocol": map[string]interface{}{"type": "string"},
+				"list":     map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"protocol", "list"},
+		}
+	case "[]portmapping":
+		ports := map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string", "pattern": `^[0-9]+(-[0-9]+)?$`},
+		}
+		p = map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"oneOf": []interface{}{
+					map[string]interface{}{
+						"type":    "string",
+						"pattern": `^[0-9,-]+(:[0-9,-]+)?(:(tcp|udp|both))?$`,
+					},
+					map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"public_ports":   ports,
+							"internal_ports": ports,
+							"protocol":       map[string]interface{}{"enum": []string{"tcp", "udp", "both"}},
+						},
+						"required": []string{"public_ports"},
+					},
+				},
+			},
+		}
+	default:
+		panic("Unknown input variable type " + inputFieldVarType(field))
+	}
+	p["description"] = field.FlagDescription
+	return p
+}
@@ -0,0 +1,44 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+import (
+	"testing"
+
+	"github.com/nbio/st"
+)
+
+func TestRenderInputTemplate(t *testing.T) {
+	fields := []inputField{
+		{Name: "Name", VarType: "string", Mandatory: true, SchemaName: "name"},
+		{Name: "Enabled", VarType: "bool", SchemaName: "conditions.rbac.enabled"},
+		{Name: "Group IDs", VarType: "[]int", SchemaName: "conditions.rbac.group_ids"},
+		{Name: "Deprecated", VarType: "string"},
+	}
+
+	out, err := renderInputTemplate(fields, "csv")
+	st.Expect(t, err, nil)
+	st.Expect(t, out, "name,conditions.rbac.enabled,conditions.rbac.group_ids\n"+
+		"\"<string, mandatory>\",<boolean>,<list of integers>")
+
+	schema := buildInputJSONSchema(fields, "test")
+	items := schema["items"].(map[string]interface{})
+	st.Expect(t, items["required"], []string{"name"})
+	properties := items["properties"].(map[string]interface{})
+	_, ok := properties["conditions"].(map[string]interface{})["properties"].(map[string]interface{})["rbac"]
+	st.Expect(t, ok, true)
+}
@@ -0,0 +1,345 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// SCIM 2.0 schema URNs, see RFC 7643 and RFC 7644
+const (
+	scimSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimSchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+)
+
+type scimListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimUser struct {
+	Schemas      []string         `json:"schemas"`
+	ID           string           `json:"id,omitempty"`
+	ExternalID   string           `json:"externalId,omitempty"`
+	UserName     string           `json:"userName"`
+	Name         *scimName        `json:"name,omitempty"`
+	DisplayName  string           `json:"displayName,omitempty"`
+	Emails       []scimMultiValue `json:"emails,omitempty"`
+	PhoneNumbers []scimMultiValue `json:"phoneNumbers,omitempty"`
+	Active       *bool            `json:"active,omitempty"`
+	Groups       []scimMultiValue `json:"groups,omitempty"`
+	Meta         *scimMeta        `json:"meta,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []scimMultiValue `json:"members,omitempty"`
+	Meta        *scimMeta        `json:"meta,omitempty"`
+}
+
+func scimTime(t strfmt.DateTime) string {
+	if time.Time(t).IsZero() {
+		return ""
+	}
+	return time.Time(t).UTC().Format(time.RFC3339)
+}
+
+// scimUserFromUser converts a console user into a SCIM User resource.
+// The console does not report phone numbers, so they are not included
+func scimUserFromUser(user models.User) *scimUser {
+	active := user.Enabled
+	u := &scimUser{
+		Schemas:     []string{scimSchemaUser},
+		ID:          strconv.FormatInt(user.ID, 10),
+		UserName:    string(user.Email),
+		DisplayName: user.Name,
+		Active:      &active,
+		Groups:      []scimMultiValue{},
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Created:      scimTime(user.CreatedAt),
+			LastModified: scimTime(user.UpdatedAt),
+		},
+	}
+	if user.Name != "" {
+		u.Name = &scimName{Formatted: user.Name}
+	}
+	if user.Email != "" {
+		u.Emails = []scimMultiValue{{Value: string(user.Email), Type: "work", Primary: true}}
+	}
+	for _, group := range user.Groups {
+		u.Groups = append(u.Groups, scimMultiValue{
+			Value:   strconv.FormatInt(group.ID, 10),
+			Display: group.Name,
+		})
+	}
+	return u
+}
+
+// scimGroupFromGroup converts a console group into a SCIM Group resource,
+// with the given members
+func scimGroupFromGroup(group models.Group, members []scimMultiValue) *scimGroup {
+	g := &scimGroup{
+		Schemas:     []string{scimSchemaGroup},
+		ID:          strconv.FormatInt(group.ID, 10),
+		DisplayName: group.Name,
+		Members:     members,
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Created:      scimTime(group.CreatedAt),
+			LastModified: scimTime(group.UpdatedAt),
+		},
+	}
+	if group.ExternalID != nil {
+		g.ExternalID = *group.ExternalID
+	}
+	return g
+}
+
+// scimGroupMembers returns the SCIM members of each group, by group ID,
+// from the groups of the given users
+func scimGroupMembers(users []models.User) map[int64][]scimMultiValue {
+	members := make(map[int64][]scimMultiValue)
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	for _, user := range users {
+		for _, group := range user.Groups {
+			members[group.ID] = append(members[group.ID], scimMultiValue{
+				Value:   strconv.FormatInt(user.ID, 10),
+				Display: string(user.Email),
+				Type:    "User",
+			})
+		}
+	}
+	return members
+}
+
+func newSCIMListResponse(resources []interface{}) *scimListResponse {
+	return &scimListResponse{
+		Schemas:      []string{scimSchemaListResponse},
+		TotalResults: len(resources),
+		Resources:    resources,
+	}
+}
+
+func initSCIMOutputFlags(cmd *cobra.Command) {
+	if _, ok := cmd.Annotations[flagInitOutput]; !ok {
+		panic("initSCIMOutputFlags called for command where output flags were not initialized. This is a bug!")
+	}
+	cmd.Annotations[flagInitSCIMOutput] = "yes"
+	flag := cmd.Flags().Lookup("output")
+	flag.Usage = strings.Replace(flag.Usage, "csv or csv-full", "csv, csv-full or scim", 1)
+}
+
+func outputIsSCIM(cmd *cobra.Command) bool {
+	if _, ok := cmd.Annotations[flagInitSCIMOutput]; !ok {
+		return false
+	}
+	output, err := cmd.Flags().GetString("output")
+	return err == nil && output == "scim"
+}
+
+// initSCIMInputFlags lets the input of cmd be read from SCIM documents
+// holding resources of the given schema
+func initSCIMInputFlags(cmd *cobra.Command, schema string) {
+	if _, ok := cmd.Annotations[flagInitInput]; !ok {
+		panic("initSCIMInputFlags called for command where input flags were not initialized. This is a bug!")
+	}
+	cmd.Annotations[flagInitSCIMInput] = schema
+	flag := cmd.Flags().Lookup("file-format")
+	flag.Usage = strings.Replace(flag.Usage, "yaml or ndjson", "yaml, ndjson or scim", 1)
+}
+
+// scimInputSource reads SCIM resources of the given schema, either as a
+// ListResponse, a JSON array or a single resource, and converts them into
+// records in the format of JSON input files
+type scimInputSource struct {
+	schema    string
+	resources []json.RawMessage
+	record    int
+}
+
+func newSCIMInputSource(reader io.Reader, schema string) (*scimInputSource, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid SCIM document: %w", err)
+	}
+	var resources []json.RawMessage
+	switch v := document.(type) {
+	case []interface{}:
+		err = json.Unmarshal(data, &resources)
+	case map[string]interface{}:
+		if _, ok := v["Resources"]; ok {
+			var list struct {
+				Resources []json.RawMessage `json:"Resources"`
+			}
+			err = json.Unmarshal(data, &list)
+			resources = list.Resources
+		} else {
+			resources = []json.RawMessage{data}
+		}
+	default:
+		err = fmt.Errorf("expected a SCIM ListResponse, a JSON array of SCIM resources or a single resource")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scimInputSource{schema: schema, resources: resources}, nil
+}
+
+func (s *scimInputSource) next() (*inputEntry, error) {
+	if s.record >= len(s.resources) {
+		return nil, io.EOF
+	}
+	resource := s.resources[s.record]
+	s.record++
+	record, err := scimResourceRecord(resource, s.schema)
+	if err != nil {
+		return nil, &inputRecordError{
+			err: fmt.Errorf("resource %d: %w", s.record, err),
+			entry: &inputEntry{
+				Type:   wholeJSONObject,
+				JSON:   resource,
+				Record: s.record,
+				Raw:    resource,
+			},
+		}
+	}
+	j, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return &inputEntry{
+		Type:   wholeJSONObject,
+		JSON:   j,
+		Record: s.record,
+		Raw:    resource,
+	}, nil
+}
+
+// scimResourceRecord converts a SCIM resource of the given schema into a
+// record. Resources of other schemas are rejected. Groups are referenced by
+// name when the resource includes it, as IDs usually differ between the
+// console and other systems
+func scimResourceRecord(resource json.RawMessage, schema string) (map[string]interface{}, error) {
+	var schemas struct {
+		Schemas []string `json:"schemas"`
+	}
+	if err := json.Unmarshal(resource, &schemas); err != nil {
+		return nil, err
+	}
+	if !funk.ContainsString(schemas.Schemas, schema) {
+		return nil, fmt.Errorf("not a SCIM %s resource", schema[strings.LastIndex(schema, ":")+1:])
+	}
+	switch schema {
+	case scimSchemaUser:
+		var user scimUser
+		if err := json.Unmarshal(resource, &user); err != nil {
+			return nil, err
+		}
+		return scimUserRecord(&user), nil
+	}
+	panic("scimResourceRecord called for unsupported schema " + schema + ". This is a bug!")
+}
+
+func scimUserRecord(user *scimUser) map[string]interface{} {
+	record := map[string]interface{}{
+		"enabled": user.Active == nil || *user.Active,
+	}
+	switch {
+	case user.DisplayName != "":
+		record["name"] = user.DisplayName
+	case user.Name != nil && user.Name.Formatted != "":
+		record["name"] = user.Name.Formatted
+	case user.Name != nil:
+		record["name"] = strings.TrimSpace(user.Name.GivenName + " " + user.Name.FamilyName)
+	}
+	if email := scimPrimaryValue(user.Emails); email != "" {
+		record["email"] = email
+	} else if strings.Contains(user.UserName, "@") {
+		record["email"] = user.UserName
+	}
+	if phone := scimPrimaryValue(user.PhoneNumbers); phone != "" {
+		record["phone_number"] = phone
+	}
+	if len(user.Groups) > 0 {
+		groups := []interface{}{}
+		for _, group := range user.Groups {
+			if group.Display != "" {
+				groups = append(groups, group.Display)
+			} else {
+				groups = append(groups, group.Value)
+			}
+		}
+		record["group_ids"] = groups
+	}
+	return record
+}
+
+// scimPrimaryValue returns the value marked as primary, or else the first
+func scimPrimaryValue(values []scimMultiValue) string {
+	for _, v := range values {
+		if v.Primary {
+			return v.Value
+		}
+	}
+	if len(values) > 0 {
+		return values[0].Value
+	}
+	return ""
+}
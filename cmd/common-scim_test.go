@@ -0,0 +1,171 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestGroupsExportSCIM(t *testing.T) {
+	defer gock.Off()
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 11, "name": "Ops", "total_users": map[string]interface{}{}},
+			{"id": 10, "name": "Sales", "external_id": "f1e2d3", "total_users": map[string]interface{}{"enrolled": 2}},
+		})
+	gock.New(baseURIinTests()).
+		Get("/users").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 2, "name": "Bob", "email": "bob@example.com", "enabled": false, "groups": []map[string]interface{}{{"id": 10, "name": "Sales"}}},
+			{"id": 1, "name": "Alice", "email": "alice@example.com", "enabled": true, "groups": []map[string]interface{}{{"id": 10, "name": "Sales"}}},
+		})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{"groups", "export", "-o=scim"})
+	err := cmd.Execute()
+	st.Assert(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	var list struct {
+		Schemas      []string    `json:"schemas"`
+		TotalResults int         `json:"totalResults"`
+		Resources    []scimGroup `json:"Resources"`
+	}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &list), nil)
+	st.Expect(t, list.Schemas, []string{scimSchemaListResponse})
+	st.Expect(t, list.TotalResults, 2)
+	st.Expect(t, list.Resources[0].ID, "10")
+	st.Expect(t, list.Resources[0].ExternalID, "f1e2d3")
+	st.Expect(t, list.Resources[0].DisplayName, "Sales")
+	st.Expect(t, list.Resources[0].Members, []scimMultiValue{
+		{Value: "1", Display: "alice@example.com", Type: "User"},
+		{Value: "2", Display: "bob@example.com", Type: "User"},
+	})
+	st.Expect(t, len(list.Resources[1].Members), 0)
+}
+
+func TestUsersImportSCIM(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer usersAddCmd.Flags().Set("from-file", "")
+	defer usersAddCmd.Flags().Set("file-format", "json")
+	defer usersAddCmd.Flags().Set("continue-on-error", "false")
+
+	inputFile := filepath.Join(dir, "users.json")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(`{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:ListResponse"],
+  "totalResults": 2,
+  "Resources": [{
+    "schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+    "id": "e9e30dba",
+    "displayName": "Sales"
+  }, {
+    "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+    "id": "2819c223",
+    "userName": "alice@corp.example.com",
+    "name": {"givenName": "Alice", "familyName": "Smith"},
+    "emails": [{"value": "alice@home.example.com"}, {"value": "alice@example.com", "primary": true}],
+    "phoneNumbers": [{"value": "+1 555 0100", "type": "work"}],
+    "active": false,
+    "groups": [{"value": "e9e30dba", "display": "Sales"}]
+  }]
+}`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		MatchParam("q", "Sales").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 10, "name": "Sales"}})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		BodyString(`"email":"alice@example.com"`).
+		BodyString(`"group_ids":\[10\]`).
+		BodyString(`"name":"Alice Smith"`).
+		BodyString(`"phone_number":"\+1 555 0100"`).
+		Reply(201).
+		JSON(map[string]interface{}{"id": 7, "name": "Alice Smith", "email": "alice@example.com"})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"users",
+		"import",
+		"-o=csv",
+		"--from-file=" + inputFile,
+		"--file-format=scim",
+		"--continue-on-error",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	// the group is not a user, so it is not created
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	st.Assert(t, len(lines), 3)
+	st.Expect(t, strings.Contains(lines[1], "record 1"), true)
+	st.Expect(t, strings.Contains(lines[1], "not a SCIM User resource"), true)
+	st.Expect(t, strings.HasPrefix(lines[2], "7,Alice Smith,"), true)
+
+	record, err := scimResourceRecord([]byte(`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "userName": "bob@example.com", "displayName": "Bob"}`), scimSchemaUser)
+	st.Assert(t, err, nil)
+	st.Expect(t, record, map[string]interface{}{"name": "Bob", "email": "bob@example.com", "enabled": true})
+	_, err = scimResourceRecord([]byte(`{"schemas": ["urn:example:Other"]}`), scimSchemaUser)
+	st.Reject(t, err, nil)
+}
+
+func TestAddResourcesRejectsSCIM(t *testing.T) {
+	defer resourcesAddCmd.Flags().Set("from-file", "")
+	defer resourcesAddCmd.Flags().Set("file-format", "json")
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"resources",
+		"add",
+		"--from-file=resources.json",
+		"--file-format=scim",
+	})
+	err := cmd.Execute()
+	st.Reject(t, err, nil)
+	st.Expect(t, err.Error(), "invalid input file format scim")
+}
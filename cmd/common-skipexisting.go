@@ -0,0 +1,276 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+
+	apipolicies "github.com/barracuda-cloudgen-access/access-cli/client/access_policies"
+	apiproxies "github.com/barracuda-cloudgen-access/access-cli/client/access_proxies"
+	apigroups "github.com/barracuda-cloudgen-access/access-cli/client/groups"
+	apiusers "github.com/barracuda-cloudgen-access/access-cli/client/users"
+	apiwebpolicies "github.com/barracuda-cloudgen-access/access-cli/client/web_policies"
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// inputExistsError replaces the error of an operation that was skipped
+// with --skip-existing, because the record already exists
+type inputExistsError struct {
+	id interface{} // nil if the existing record could not be found
+}
+
+func (e *inputExistsError) Error() string {
+	if e.id == nil {
+		return "skipped (exists)"
+	}
+	return fmt.Sprintf("skipped (exists, id=%v)", e.id)
+}
+
+func isInputExistsError(err error) bool {
+	_, ok := err.(*inputExistsError)
+	return ok
+}
+
+// inputErrorListItem returns the item listed in the JSON output of a
+// command in place of a record that failed with err: an object noting the
+// existing record when it was skipped with --skip-existing, item otherwise
+func inputErrorListItem(err error, item interface{}) interface{} {
+	if exists, ok := err.(*inputExistsError); ok {
+		return multiOpJSONResult{
+			ID:     exists.id,
+			OK:     true,
+			Result: "skipped (exists)",
+		}
+	}
+	return item
+}
+
+// errorRowPrefix returns the prefix for the ID column of table rows
+// reporting err
+func errorRowPrefix(err error) string {
+	if isInputExistsError(err) {
+		return "[SKIP]"
+	}
+	return "[ERR]"
+}
+
+// existingRecordLookups returns, for each kind of record, the records
+// matching query. Results may include records that are not an exact match
+var existingRecordLookups = map[string]func(cmd *cobra.Command, query string) ([]interface{}, error){
+	"user": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		items, err := listAllUsers(cmd, query)
+		return funk.Map(items, func(i *apiusers.ListUsersOKBodyItems0) interface{} { return i }).([]interface{}), err
+	},
+	"admin": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		items, err := listAllAdmins(cmd, query)
+		return funk.Map(items, func(i *models.Admin) interface{} { return i }).([]interface{}), err
+	},
+	"group": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		items, err := listAllGroups(cmd, query)
+		return funk.Map(items, func(i *apigroups.ListGroupsOKBodyItems0) interface{} { return i }).([]interface{}), err
+	},
+	"policy": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		items, err := listAllPolicies(cmd, query)
+		return funk.Map(items, func(i *apipolicies.ListPoliciesOKBodyItems0) interface{} { return i }).([]interface{}), err
+	},
+	"proxy": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		items, err := listAllProxies(cmd, query)
+		return funk.Map(items, func(i *apiproxies.ListProxiesOKBodyItems0) interface{} { return i }).([]interface{}), err
+	},
+	"resource": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		items, err := listAllResources(cmd, query)
+		return funk.Map(items, func(i *models.AccessResource) interface{} { return i }).([]interface{}), err
+	},
+	"domain": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		// assets can not be searched, so all domains are listed
+		items, err := listAllAssets(cmd, "domain")
+		return funk.Map(items, func(i *models.Asset) interface{} { return i }).([]interface{}), err
+	},
+	"webpolicy": func(cmd *cobra.Command, query string) ([]interface{}, error) {
+		// web policies are the rules of the main ruleset
+		params := apiwebpolicies.NewListWebPoliciesParams()
+		setTenant(cmd, params)
+		resp, err := global.Client.WebPolicies.ListWebPolicies(params, global.AuthWriter)
+		if err != nil {
+			return nil, err
+		}
+		items := []interface{}{}
+		for _, item := range resp.Payload.Rules {
+			items = append(items, item)
+		}
+		return items, nil
+	},
+}
+
+func initSkipExistingFlags(cmd *cobra.Command, kind string, keys ...string) {
+	if _, ok := cmd.Annotations[flagInitInput]; !ok {
+		panic("initSkipExistingFlags called for command where input flags were not initialized. This is a bug!")
+	}
+	if _, ok := existingRecordLookups[kind]; !ok {
+		panic("initSkipExistingFlags called for unknown record kind " + kind + ". This is a bug!")
+	}
+	cmd.Annotations[flagInitSkipExisting] = "yes"
+	data := global.InputData[cmd]
+	data.existingKind = kind
+	data.existingKeys = keys
+
+	names := []string{}
+	for _, key := range keys {
+		for _, field := range data.fields {
+			if field.SchemaName == key {
+				names = append(names, strings.ToLower(field.Name))
+			}
+		}
+	}
+	cmd.Flags().Bool("skip-existing", false, "skip "+pluralize(kind)+" that already exist (same "+strings.Join(names, " or ")+"), instead of failing")
+}
+
+func inputSkipExisting(cmd *cobra.Command) bool {
+	if _, ok := cmd.Annotations[flagInitSkipExisting]; !ok {
+		return false
+	}
+	skip, err := cmd.Flags().GetBool("skip-existing")
+	return err == nil && skip
+}
+
+// skipExistingDo wraps do so that records that already exist are skipped.
+// Existing records are looked up before each operation, and again when the
+// operation fails with a uniqueness error, as records may be created
+// concurrently (e.g. when the input has duplicates and --parallel is used)
+func skipExistingDo(cmd *cobra.Command, do func(entry *inputEntry) (interface{}, error)) func(entry *inputEntry) (interface{}, error) {
+	data := global.InputData[cmd]
+	return func(entry *inputEntry) (interface{}, error) {
+		id, found, err := findExistingRecord(cmd, entry, data.existingKeys)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			return nil, &inputExistsError{id: id}
+		}
+		res, err := do(entry)
+		keys := uniquenessErrorFields(err)
+		if keys == nil {
+			return res, err
+		}
+		id, found, lookupErr := findExistingRecord(cmd, entry, append(keys, data.existingKeys...))
+		if lookupErr != nil || !found {
+			id = nil
+		}
+		return nil, &inputExistsError{id: id}
+	}
+}
+
+// uniquenessErrorFields returns the fields that err reports as already
+// taken, or nil if err is not a uniqueness error
+func uniquenessErrorFields(err error) []string {
+	r, ok := err.(unprocessableEntityResponse)
+	if !ok || r.GetPayload() == nil {
+		return nil
+	}
+	var fields []string
+	for k, v := range r.GetPayload().UnprocessableEntityResponse {
+		msgs, ok := v.([]interface{})
+		if !ok {
+			continue
+		}
+		for _, msg := range msgs {
+			if s, ok := msg.(string); ok && strings.Contains(s, "has already been taken") {
+				fields = append(fields, k)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// findExistingRecord looks for an existing record whose value for any of
+// keys (ignoring case) is the same as in entry, and returns its ID
+func findExistingRecord(cmd *cobra.Command, entry *inputEntry, keys []string) (interface{}, bool, error) {
+	data := global.InputData[cmd]
+	lookup := existingRecordLookups[data.existingKind]
+	for _, key := range keys {
+		value := inputEntryFieldValue(data.fields, entry, key)
+		if value == nil || fmt.Sprint(value) == "" {
+			continue
+		}
+		items, err := lookup(cmd, fmt.Sprint(value))
+		if err != nil {
+			return nil, false, err
+		}
+		for _, item := range items {
+			object, err := existingRecordObject(item)
+			if err != nil {
+				return nil, false, err
+			}
+			if strings.EqualFold(fmt.Sprint(object[key]), fmt.Sprint(value)) {
+				return object["id"], true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// existingRecordObject converts a record returned by the API into a map
+// keyed by its JSON field names
+func existingRecordObject(item interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err = dec.Decode(&object)
+	return object, err
+}
+
+// inputEntryFieldValue returns the value of the field of entry with the
+// given schema name, or nil if it is not present. Keys of input files are
+// matched the same way as when records are decoded
+func inputEntryFieldValue(fields []inputField, entry *inputEntry, schemaName string) interface{} {
+	field := inputField{SchemaName: schemaName}
+	index := -1
+	for i, f := range fields {
+		if f.SchemaName == schemaName {
+			field, index = f, i
+			break
+		}
+	}
+	var record map[string]interface{}
+	switch entry.Type {
+	case individualValues:
+		if index >= 0 {
+			return entry.Values[index]
+		}
+		return nil
+	case wholeJSONObject:
+		if err := json.Unmarshal(entry.JSON, &record); err != nil {
+			return nil
+		}
+	case wholeCSVObject:
+		record, _ = entry.CSVdata.(map[string]interface{})
+	}
+	value, _ := lookupSchemaValue(record, field)
+	return value
+}
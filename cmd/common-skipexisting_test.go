@@ -0,0 +1,181 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestAddUsersSkipExisting(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer func() {
+		usersAddCmd.Flags().Set("from-file", "")
+		usersAddCmd.Flags().Set("skip-existing", "false")
+	}()
+
+	inputFile := filepath.Join(dir, "users.ndjson")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(
+		`{"name": "Alice", "email": "alice@example.com"}
+{"name": "Bob", "email": "bob@example.com"}
+{"name": "Carol", "email": "carol@example.com"}
+`), 0600), nil)
+
+	// Alice is found before creating
+	gock.New(baseURIinTests()).
+		Get("/users").
+		MatchParam("q", "alice@example.com").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 5, "name": "Alice", "email": "Alice@example.com"}})
+	// Bob is only found once creating fails
+	gock.New(baseURIinTests()).
+		Get("/users").
+		MatchParam("q", "bob@example.com").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(422).
+		JSON(map[string]interface{}{"email": []string{"has already been taken"}})
+	gock.New(baseURIinTests()).
+		Get("/users").
+		MatchParam("q", "bob@example.com").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 6, "name": "Bob", "email": "bob@example.com"}})
+	// Carol does not exist
+	gock.New(baseURIinTests()).
+		Get("/users").
+		MatchParam("q", "carol@example.com").
+		Reply(200).
+		SetHeader("total", "0").
+		JSON([]map[string]interface{}{})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		Reply(201).
+		JSON(map[string]interface{}{"id": 7, "name": "Carol", "email": "carol@example.com"})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"users",
+		"add",
+		"-o=csv",
+		"--continue-on-error=false",
+		"--from-file=" + inputFile,
+		"--file-format=ndjson",
+		"--skip-existing",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	lines := strings.Split(out.String(), "\n")
+	st.Assert(t, len(lines) > 3, true)
+	st.Expect(t, strings.HasPrefix(lines[1], "[SKIP] Alice,"), true)
+	st.Expect(t, strings.Contains(lines[1], "id=5)"), true)
+	st.Expect(t, strings.HasPrefix(lines[2], "[SKIP] Bob,"), true)
+	st.Expect(t, strings.Contains(lines[2], "id=6)"), true)
+	st.Expect(t, strings.HasPrefix(lines[3], "7,Carol,"), true)
+}
+
+func TestAddUsersSkipExistingJSON(t *testing.T) {
+	defer gock.Off()
+	defer resetUsersAddGroupFlags()
+	defer usersAddCmd.Flags().Set("skip-existing", "false")
+
+	gock.New(baseURIinTests()).
+		Get("/users").
+		MatchParam("q", "alice@example.com").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 5, "name": "Alice", "email": "alice@example.com"}})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"users",
+		"add",
+		"-o=json",
+		"--name=Alice",
+		"--email=alice@example.com",
+		"--skip-existing",
+	})
+	err := cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	st.Expect(t, strings.TrimSpace(out.String()), `[{"id":5,"ok":true,"result":"skipped (exists)"}]`)
+}
+
+func TestAddResourcesSkipExistingCSV(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	defer func() {
+		resourcesAddCmd.Flags().Set("from-file", "")
+		resourcesAddCmd.Flags().Set("file-format", "json")
+		resourcesAddCmd.Flags().Set("skip-existing", "false")
+	}()
+
+	inputFile := filepath.Join(dir, "resources.csv")
+	st.Assert(t, ioutil.WriteFile(inputFile, []byte(
+		"Name,public_host,internal_host,ports,access_proxy_id\nApp,APP.example.com,10.0.0.1,443:8443,d7b6f5a4-3c2b-4a19-8e7d-6c5b4a392817\n"), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/access_resources").
+		MatchParam("q", "APP.example.com").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": "0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9", "name": "App", "public_host": "app.example.com"}})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.SetArgs([]string{
+		"resources",
+		"add",
+		"-o=csv",
+		"--from-file=" + inputFile,
+		"--file-format=csv",
+		"--skip-existing",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+	st.Expect(t, strings.HasPrefix(strings.Split(out.String(), "\n")[1], "[SKIP],"), true)
+	st.Expect(t, strings.Contains(out.String(), "id=0f1e2d3c-4b5a-4978-8695-a4b3c2d1e0f9"), true)
+}
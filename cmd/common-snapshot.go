@@ -0,0 +1,339 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+)
+
+// snapshotTimeFormat is the format of the time in snapshot file names,
+// which sort in the order the snapshots were taken
+const snapshotTimeFormat = "20060102T150405.000Z"
+
+// snapshotExtension is the extension of snapshot files
+const snapshotExtension = ".json.gz"
+
+// snapshot is the configuration of a tenant at a point in time
+type snapshot struct {
+	Endpoint   string                 `json:"endpoint"`
+	Tenant     string                 `json:"tenant"`
+	TakenAt    time.Time              `json:"taken_at"`
+	CLIVersion string                 `json:"cli_version"`
+	Objects    map[string]interface{} `json:"objects"` // by export kind
+}
+
+// snapshotInfo describes a snapshot file
+type snapshotInfo struct {
+	TakenAt time.Time `json:"taken_at"`
+	File    string    `json:"file"`
+	Size    int64     `json:"size"`
+	Records int       `json:"records,omitempty"`
+}
+
+// snapshotDir returns the directory with the snapshots of a tenant
+func snapshotDir(tenant string) string {
+	return filepath.Join(cfgViper.GetString(ckeyCachePath), "snapshots", tenant)
+}
+
+// takeSnapshot stores a snapshot of the objects of every export kind of the
+// tenant the command operates on, and returns its description
+func takeSnapshot(cmd *cobra.Command) (*snapshotInfo, error) {
+	s := &snapshot{
+		Endpoint:   authViper.GetString(ckeyAuthEndpoint),
+		Tenant:     tenantID(cmd),
+		TakenAt:    time.Now().UTC().Truncate(time.Millisecond),
+		CLIVersion: version.Version,
+		Objects:    make(map[string]interface{}),
+	}
+	records := 0
+	for _, kind := range exportKinds {
+		objects, err := kind.fetch(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %v", kind.name, processErrorResponse(err))
+		}
+		plain, err := exportPlainValue(objects)
+		if err != nil {
+			return nil, err
+		}
+		if list, ok := plain.([]interface{}); ok {
+			records += len(list)
+		} else {
+			records++
+		}
+		s.Objects[kind.name] = plain
+	}
+
+	dir := snapshotDir(s.Tenant)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, s.TakenAt.Format(snapshotTimeFormat)+snapshotExtension)
+	if err := writeSnapshot(path, s); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotInfo{TakenAt: s.TakenAt, File: path, Size: info.Size(), Records: records}, nil
+}
+
+// writeSnapshot writes s to path, gzip-compressed
+func writeSnapshot(path string, s *snapshot) error {
+	return writeFileAtomically(path, func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(s)
+	})
+}
+
+// readSnapshot reads the snapshot at path
+func readSnapshot(path string) (*snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	s := &snapshot{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	for kind, objects := range s.Objects {
+		s.Objects[kind] = exportConvertNumbers(objects)
+	}
+	return s, nil
+}
+
+// listSnapshots returns the snapshots of a tenant, oldest first
+func listSnapshots(tenant string) ([]*snapshotInfo, error) {
+	dir := snapshotDir(tenant)
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []*snapshotInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []*snapshotInfo{}
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, snapshotExtension) {
+			continue
+		}
+		takenAt, err := time.Parse(snapshotTimeFormat, strings.TrimSuffix(name, snapshotExtension))
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, &snapshotInfo{
+			TakenAt: takenAt,
+			File:    filepath.Join(dir, name),
+			Size:    file.Size(),
+		})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].TakenAt.Before(snapshots[j].TakenAt)
+	})
+	return snapshots, nil
+}
+
+// snapshotObjectKinds maps the kinds of objects accepted by snapshot log
+// to the export kinds they are stored under
+var snapshotObjectKinds = map[string]string{
+	"user":      "users",
+	"group":     "groups",
+	"device":    "devices",
+	"resource":  "resources",
+	"policy":    "policies",
+	"proxy":     "proxies",
+	"admin":     "admins",
+	"asset":     "assets",
+	"source":    "sources",
+	"webpolicy": "webpolicies",
+	"settings":  "settings",
+}
+
+// parseSnapshotObject parses an object reference of the form kind/id
+func parseSnapshotObject(ref string) (kind, id string, err error) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid object %q, expected kind/id (e.g. resource/42)", ref)
+	}
+	if _, ok := snapshotObjectKinds[parts[0]]; !ok {
+		kinds := funk.Keys(snapshotObjectKinds).([]string)
+		sort.Strings(kinds)
+		return "", "", fmt.Errorf("invalid object kind %s, expected one of %s", parts[0], strings.Join(kinds, ", "))
+	}
+	return parts[0], parts[1], nil
+}
+
+// findSnapshotObject returns the object of the given kind with the given ID
+// in s, or nil if it is not there. Settings are identified by their name
+// (agent_configuration, analytics or enrollment), and web policies by the
+// ID of their rule
+func findSnapshotObject(s *snapshot, kind, id string) map[string]interface{} {
+	objects := s.Objects[snapshotObjectKinds[kind]]
+	switch kind {
+	case "settings":
+		m, _ := objects.(map[string]interface{})
+		settings, _ := m[id].(map[string]interface{})
+		return settings
+	case "webpolicy":
+		m, _ := objects.(map[string]interface{})
+		rules, _ := m["rules"].([]interface{})
+		for _, r := range rules {
+			jump, _ := r.(map[string]interface{})
+			ruleset, _ := jump["ruleset_jump"].(map[string]interface{})
+			inner, _ := ruleset["rules"].([]interface{})
+			for _, i := range inner {
+				rule, _ := i.(map[string]interface{})
+				if rule != nil && fmt.Sprint(rule["id"]) == id {
+					rule["groups"] = jump["groups"]
+					rule["users"] = jump["users"]
+					return rule
+				}
+			}
+		}
+		return nil
+	}
+	list, _ := objects.([]interface{})
+	for _, item := range list {
+		m, _ := item.(map[string]interface{})
+		if m != nil && fmt.Sprint(m["id"]) == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// snapshotLogEntry is a change to an object between two snapshots
+type snapshotLogEntry struct {
+	TakenAt time.Time   `json:"taken_at"`
+	Action  string      `json:"action"`
+	Fields  []diffField `json:"fields"`
+}
+
+// snapshotLog returns the changes to an object across the given snapshots,
+// oldest first. Volatile fields are not compared
+func snapshotLog(snapshots []*snapshotInfo, kind, id string) ([]snapshotLogEntry, error) {
+	entries := []snapshotLogEntry{}
+	var previous map[string]string
+	for _, info := range snapshots {
+		s, err := readSnapshot(info.File)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.Objects[snapshotObjectKinds[kind]]; !ok {
+			// the snapshot was taken by a version that did not store the kind
+			continue
+		}
+		var current map[string]string
+		if object := findSnapshotObject(s, kind, id); object != nil {
+			current = make(map[string]string)
+			diffFlatten("", driftRemoveVolatile(object), current)
+		}
+
+		entry := snapshotLogEntry{TakenAt: s.TakenAt, Action: diffChanged}
+		switch {
+		case previous == nil && current == nil:
+			continue
+		case previous == nil:
+			entry.Action = diffAdded
+		case current == nil:
+			entry.Action = diffRemoved
+		}
+		entry.Fields = diffFields(previous, current)
+		if entry.Action != diffChanged || len(entry.Fields) > 0 {
+			entries = append(entries, entry)
+		}
+		previous = current
+	}
+	return entries, nil
+}
+
+func snapshotBuildTableWriter() table.Writer {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Taken At",
+		"File",
+		"Size",
+	})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 3, Align: text.AlignRight},
+	})
+	return tw
+}
+
+func snapshotTableWriterAppend(tw table.Writer, info *snapshotInfo) {
+	tw.AppendRow(table.Row{info.TakenAt.Format(time.RFC3339), info.File, info.Size})
+}
+
+func snapshotLogBuildTableWriter() table.Writer {
+	tw := table.NewWriter()
+	tw.Style().Format.Header = text.FormatDefault
+	tw.AppendHeader(table.Row{
+		"Taken At",
+		"Change",
+		"Field",
+		"Old",
+		"New",
+	})
+	tw.SetColumnConfigs([]table.ColumnConfig{
+		{Number: 3, WidthMax: 30},
+		{Number: 4, WidthMax: 40},
+		{Number: 5, WidthMax: 40},
+	})
+	return tw
+}
+
+func snapshotLogTableWriterAppend(tw table.Writer, entry snapshotLogEntry) {
+	if len(entry.Fields) == 0 {
+		tw.AppendRow(table.Row{entry.TakenAt.Format(time.RFC3339), entry.Action, "", "", ""})
+		return
+	}
+	for i, field := range entry.Fields {
+		if i == 0 {
+			tw.AppendRow(table.Row{entry.TakenAt.Format(time.RFC3339), entry.Action, field.Field, diffFieldDisplay(field.Old), diffFieldDisplay(field.New)})
+		} else {
+			tw.AppendRow(table.Row{"", "", field.Field, diffFieldDisplay(field.Old), diffFieldDisplay(field.New)})
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSnapshotLog(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	previousCachePath := cfgViper.GetString(ckeyCachePath)
+	cfgViper.Set(ckeyCachePath, dir)
+	defer cfgViper.Set(ckeyCachePath, previousCachePath)
+
+	gockExportEndpoints()
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"snapshot",
+		"take",
+		"-o=json",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	info := snapshotInfo{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &info), nil)
+	st.Expect(t, filepath.Dir(info.File), filepath.Join(dir, "snapshots", "testTenantID"))
+	st.Expect(t, info.Records, 4)
+
+	// a later snapshot, where Sales was renamed and Engineering removed
+	s, err := readSnapshot(info.File)
+	st.Assert(t, err, nil)
+	s.TakenAt = s.TakenAt.Add(time.Hour)
+	s.Objects["groups"] = []interface{}{
+		map[string]interface{}{"id": 10, "name": "Sales EMEA", "updated_at": "2026-10-19T10:00:00Z"},
+	}
+	st.Assert(t, writeSnapshot(filepath.Join(filepath.Dir(info.File), s.TakenAt.Format(snapshotTimeFormat)+snapshotExtension), s), nil)
+
+	out.Reset()
+	cmd.SetArgs([]string{
+		"snapshot",
+		"log",
+		"-o=json",
+		"--object", "group/10",
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+
+	entries := []snapshotLogEntry{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &entries), nil)
+	st.Assert(t, len(entries), 2)
+	st.Expect(t, entries[0].Action, diffAdded)
+	st.Expect(t, entries[1].Action, diffChanged)
+	st.Expect(t, entries[1].TakenAt, s.TakenAt)
+	st.Expect(t, entries[1].Fields, []diffField{{Field: "name", Old: diffValue("Sales"), New: diffValue("Sales EMEA")}})
+}
@@ -0,0 +1,534 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+
+	apiusers "github.com/barracuda-cloudgen-access/access-cli/client/users"
+)
+
+// syncActionDisable is the action for console users that are not in the
+// source, unless --delete is used
+const syncActionDisable = "disable"
+
+// syncSourceUser is a user of the authoritative directory
+type syncSourceUser struct {
+	email  string
+	name   string
+	phone  string
+	groups []string // names
+}
+
+// syncSource is the set of users read from a directory export
+type syncSource struct {
+	users     []*syncSourceUser
+	hasGroups bool // whether the source lists group memberships at all
+}
+
+// syncCSVColumns maps the accepted CSV column names, lowercased and with
+// spaces replaced by underscores, to the fields of source users
+var syncCSVColumns = map[string]string{
+	"email":           "email",
+	"mail":            "email",
+	"name":            "name",
+	"display_name":    "name",
+	"displayname":     "name",
+	"full_name":       "name",
+	"phone":           "phone",
+	"phone_number":    "phone",
+	"telephone":       "phone",
+	"telephonenumber": "phone",
+	"mobile":          "phone",
+	"groups":          "groups",
+	"group_ids":       "groups",
+	"memberof":        "groups",
+	"member_of":       "groups",
+}
+
+// readSyncSource reads the users of a directory export, in LDIF or CSV
+// format. When format is empty, it is determined from the file extension.
+// columnMap, if not nil, maps the columns of CSV files
+func readSyncSource(path, format string, columnMap *inputColumnMap) (*syncSource, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".ldif", ".ldf":
+			format = "ldif"
+		case ".csv":
+			format = "csv"
+		default:
+			return nil, fmt.Errorf("unknown source format for %s, specify one with --source-format", path)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var source *syncSource
+	switch format {
+	case "ldif":
+		if columnMap != nil {
+			return nil, fmt.Errorf("--column-map can only be used with CSV sources")
+		}
+		source, err = readSyncLDIF(f)
+	case "csv":
+		source, err = readSyncCSV(f, columnMap)
+	default:
+		return nil, fmt.Errorf("unsupported source format %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, user := range source.users {
+		key := strings.ToLower(user.email)
+		if seen[key] {
+			return nil, fmt.Errorf("%s: duplicate email %s", path, user.email)
+		}
+		seen[key] = true
+	}
+	return source, nil
+}
+
+// readSyncLDIF reads users from LDIF. Entries without a mail attribute,
+// such as those of groups, are skipped. Names are taken from displayName
+// or cn, phones from telephoneNumber or mobile, and groups from the first
+// component of the DNs in memberOf
+func readSyncLDIF(r io.Reader) (*syncSource, error) {
+	source := &syncSource{users: []*syncSourceUser{}}
+	attrs := make(map[string][]string)
+	flush := func() {
+		if len(attrs["mail"]) > 0 {
+			user := &syncSourceUser{email: attrs["mail"][0]}
+			for _, attr := range []string{"displayname", "cn"} {
+				if len(attrs[attr]) > 0 && user.name == "" {
+					user.name = attrs[attr][0]
+				}
+			}
+			for _, attr := range []string{"telephonenumber", "mobile"} {
+				if len(attrs[attr]) > 0 && user.phone == "" {
+					user.phone = attrs[attr][0]
+				}
+			}
+			for _, dn := range attrs["memberof"] {
+				user.groups = append(user.groups, ldifFirstRDNValue(dn))
+			}
+			source.users = append(source.users, user)
+		}
+		attrs = make(map[string][]string)
+	}
+
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, " ") && len(lines) > 0 {
+			// folded line
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, line := range lines {
+		if line == "" {
+			flush()
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		sep := strings.Index(line, ":")
+		if sep < 0 {
+			return nil, fmt.Errorf("line %d: expected attribute: value", i+1)
+		}
+		attr := strings.ToLower(line[:sep])
+		value := line[sep+1:]
+		if strings.HasPrefix(value, ":") {
+			decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", i+1, err)
+			}
+			value = string(decoded)
+		} else {
+			value = strings.TrimSpace(value)
+		}
+		if attr == "memberof" {
+			source.hasGroups = true
+		}
+		attrs[attr] = append(attrs[attr], value)
+	}
+	flush()
+	return source, nil
+}
+
+// ldifFirstRDNValue returns the value of the first component of a DN, e.g.
+// Sales for cn=Sales,ou=Groups,dc=example,dc=com
+func ldifFirstRDNValue(dn string) string {
+	rdn := dn
+	for i := 0; i < len(dn); i++ {
+		if dn[i] == '\\' {
+			i++
+			continue
+		}
+		if dn[i] == ',' {
+			rdn = dn[:i]
+			break
+		}
+	}
+	if sep := strings.Index(rdn, "="); sep >= 0 {
+		rdn = rdn[sep+1:]
+	}
+	return strings.ReplaceAll(strings.TrimSpace(rdn), "\\", "")
+}
+
+// readSyncCSV reads users from CSV with a header row. Groups are separated
+// by semicolons, or by commas when columnMap is used, as in input files
+func readSyncCSV(r io.Reader, columnMap *inputColumnMap) (*syncSource, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	groupSeparator := ";"
+	sourceHeader := header
+	if columnMap != nil {
+		header, _ = columnMap.apply(sourceHeader, make([]string, len(sourceHeader)))
+		groupSeparator = ","
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if field, ok := syncCSVColumns[key]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("missing email column")
+	}
+
+	source := &syncSource{users: []*syncSourceUser{}}
+	_, source.hasGroups = columns["groups"]
+	value := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if columnMap != nil {
+			_, record = columnMap.apply(sourceHeader, record)
+		}
+		user := &syncSourceUser{
+			email: value(record, "email"),
+			name:  value(record, "name"),
+			phone: value(record, "phone"),
+		}
+		if user.email == "" {
+			return nil, fmt.Errorf("line %d: missing email", line)
+		}
+		for _, group := range strings.Split(value(record, "groups"), groupSeparator) {
+			if group = strings.TrimSpace(group); group != "" {
+				user.groups = append(user.groups, group)
+			}
+		}
+		source.users = append(source.users, user)
+	}
+	return source, nil
+}
+
+// syncConsoleUser is a user of the console, as needed for syncing. The API
+// does not return phone numbers, so they can not be compared
+type syncConsoleUser struct {
+	id      int64
+	email   string
+	name    string
+	enabled bool
+	groups  []int64
+}
+
+// planUserSync returns the changes needed for the console users to match
+// source: creations and updates first, then the users to disable (or
+// delete) because they are not in the source. Group names of the source
+// that do not exist in the console are reported with warn, and ignored
+func planUserSync(cmd *cobra.Command, source *syncSource, deleteMissing, updatePhones bool, warn func(string)) ([]*manifestChange, error) {
+	users := []*syncConsoleUser{}
+	items, err := listAllUsers(cmd, "")
+	if err != nil {
+		return nil, fmt.Errorf("fetching users: %v", processErrorResponse(err))
+	}
+	for _, item := range items {
+		user := &syncConsoleUser{
+			id:      item.ID,
+			email:   string(item.Email),
+			name:    item.Name,
+			enabled: item.Enabled,
+			groups:  []int64{},
+		}
+		for _, group := range item.Groups {
+			user.groups = append(user.groups, group.ID)
+		}
+		users = append(users, user)
+	}
+
+	groupIDs := make(map[string]int64)
+	groupNames := make(map[int64]string)
+	if source.hasGroups {
+		groups, err := listAllGroups(cmd, "")
+		if err != nil {
+			return nil, fmt.Errorf("fetching groups: %v", processErrorResponse(err))
+		}
+		for _, item := range groups {
+			groupIDs[strings.ToLower(item.Name)] = item.ID
+			groupNames[item.ID] = item.Name
+		}
+	}
+	displayGroups := func(ids []int64) string {
+		names := []string{}
+		for _, id := range ids {
+			if name, ok := groupNames[id]; ok {
+				names = append(names, name)
+			} else {
+				names = append(names, fmt.Sprint(id))
+			}
+		}
+		sort.Strings(names)
+		return strings.Join(names, ", ")
+	}
+
+	byEmail := make(map[string]*syncConsoleUser)
+	for _, user := range users {
+		byEmail[strings.ToLower(user.email)] = user
+	}
+	unknownGroups := make(map[string]bool)
+	changes := []*manifestChange{}
+	inSource := make(map[string]bool)
+	for _, desired := range source.users {
+		inSource[strings.ToLower(desired.email)] = true
+		ids := []int64{}
+		for _, name := range desired.groups {
+			id, ok := groupIDs[strings.ToLower(name)]
+			if !ok {
+				if !unknownGroups[strings.ToLower(name)] {
+					unknownGroups[strings.ToLower(name)] = true
+					warn(fmt.Sprintf("group %q is not in the console, memberships of it are ignored", name))
+				}
+				continue
+			}
+			if !funk.ContainsInt64(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+
+		live := byEmail[strings.ToLower(desired.email)]
+		if live == nil {
+			name := desired.name
+			if name == "" {
+				name = desired.email
+			}
+			change := &manifestChange{
+				Action: manifestActionCreate,
+				Kind:   "user",
+				Name:   desired.email,
+				Fields: []manifestFieldChange{{Field: "name", Desired: name}},
+				values: map[string]interface{}{"name": name, "email": desired.email, "phone_number": desired.phone, "group_ids": ids},
+			}
+			if desired.phone != "" {
+				change.Fields = append(change.Fields, manifestFieldChange{Field: "phone_number", Desired: desired.phone})
+			}
+			if len(ids) > 0 {
+				change.Fields = append(change.Fields, manifestFieldChange{Field: "groups", Desired: displayGroups(ids)})
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		change := &manifestChange{
+			Action: manifestActionUpdate,
+			Kind:   "user",
+			Name:   live.email,
+			ID:     live.id,
+			values: make(map[string]interface{}),
+		}
+		if desired.name != "" && desired.name != live.name {
+			change.Fields = append(change.Fields, manifestFieldChange{Field: "name", Current: live.name, Desired: desired.name})
+			change.values["name"] = desired.name
+		}
+		if source.hasGroups && displayGroups(ids) != displayGroups(live.groups) {
+			change.Fields = append(change.Fields, manifestFieldChange{Field: "groups", Current: displayGroups(live.groups), Desired: displayGroups(ids)})
+			change.values["group_ids"] = ids
+		}
+		if !live.enabled {
+			change.Fields = append(change.Fields, manifestFieldChange{Field: "enabled", Current: "false", Desired: "true"})
+			change.values["enabled"] = true
+		}
+		// as phones are unknown, they are only sent along with other changes,
+		// unless updatePhones is set
+		if desired.phone != "" && (updatePhones || len(change.Fields) > 0) {
+			change.Fields = append(change.Fields, manifestFieldChange{Field: "phone_number", Current: "(unknown)", Desired: desired.phone})
+			change.values["phone_number"] = desired.phone
+		}
+		if len(change.Fields) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, live := range users {
+		if inSource[strings.ToLower(live.email)] {
+			continue
+		}
+		switch {
+		case deleteMissing:
+			changes = append(changes, &manifestChange{
+				Action: manifestActionDelete,
+				Kind:   "user",
+				Name:   live.email,
+				ID:     live.id,
+			})
+		case live.enabled:
+			changes = append(changes, &manifestChange{
+				Action: syncActionDisable,
+				Kind:   "user",
+				Name:   live.email,
+				ID:     live.id,
+				Fields: []manifestFieldChange{{Field: "enabled", Current: "true", Desired: "false"}},
+			})
+		}
+	}
+	return changes, nil
+}
+
+// syncRemovals returns the number of changes that disable or delete users
+func syncRemovals(changes []*manifestChange) int {
+	n := 0
+	for _, change := range changes {
+		if change.Action == syncActionDisable || change.Action == manifestActionDelete {
+			n++
+		}
+	}
+	return n
+}
+
+// executeUserSync performs a change planned by planUserSync, with the
+// same requests as users add, users edit, users disable and users delete
+func executeUserSync(cmd *cobra.Command, change *manifestChange) error {
+	switch change.Action {
+	case manifestActionCreate:
+		params := apiusers.NewCreateUserParams()
+		setTenant(cmd, params)
+		params.SetUser(apiusers.CreateUserBody{User: &apiusers.CreateUserParamsBodyUser{
+			Name:        change.values["name"].(string),
+			Email:       strfmt.Email(change.values["email"].(string)),
+			PhoneNumber: change.values["phone_number"].(string),
+			GroupIds:    change.values["group_ids"].([]int64),
+			Enabled:     true, // the UI on the web console enables by default
+		}})
+		resp, err := global.Client.Users.CreateUser(params, global.AuthWriter)
+		if err != nil {
+			return err
+		}
+		change.ID = resp.Payload.ID
+		change.Result = "created"
+	case manifestActionUpdate:
+		params := apiusers.NewEditUserParams()
+		setTenant(cmd, params)
+		params.SetID(change.ID.(int64))
+		user := &apiusers.EditUserParamsBodyUser{}
+		if name, ok := change.values["name"].(string); ok {
+			user.Name = name
+		}
+		if phone, ok := change.values["phone_number"].(string); ok {
+			user.PhoneNumber = phone
+		}
+		if ids, ok := change.values["group_ids"].([]int64); ok {
+			user.GroupIds = ids
+		}
+		if enabled, ok := change.values["enabled"].(bool); ok {
+			user.Enabled = &enabled
+		}
+		params.SetUser(apiusers.EditUserBody{User: user})
+		if _, err := global.Client.Users.EditUser(params, global.AuthWriter); err != nil {
+			return err
+		}
+		change.Result = "updated"
+	case syncActionDisable:
+		params := apiusers.NewEditUserParams()
+		setTenant(cmd, params)
+		params.SetID(change.ID.(int64))
+		enabled := false
+		params.SetUser(apiusers.EditUserBody{User: &apiusers.EditUserParamsBodyUser{Enabled: &enabled}})
+		if _, err := global.Client.Users.EditUser(params, global.AuthWriter); err != nil {
+			return err
+		}
+		change.Result = "disabled"
+	case manifestActionDelete:
+		params := apiusers.NewDeleteUserParams()
+		setTenant(cmd, params)
+		params.SetID([]int64{change.ID.(int64)})
+		if _, err := global.Client.Users.DeleteUser(params, global.AuthWriter); err != nil {
+			return err
+		}
+		change.Result = "deleted"
+	default:
+		panic("executeUserSync called for unknown action " + change.Action + ". This is a bug!")
+	}
+	return nil
+}
+
+// syncSummary returns a summary of the number of changes of each action
+func syncSummary(changes []*manifestChange) string {
+	if len(changes) == 0 {
+		return "No changes"
+	}
+	counts := make(map[string]int)
+	for _, change := range changes {
+		counts[change.Action]++
+	}
+	return fmt.Sprintf("%d to create, %d to update, %d to disable, %d to delete",
+		counts[manifestActionCreate], counts[manifestActionUpdate], counts[syncActionDisable], counts[manifestActionDelete])
+}
@@ -0,0 +1,127 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nbio/st"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestReadSyncLDIF(t *testing.T) {
+	source, err := readSyncLDIF(strings.NewReader(`# people
+dn: cn=Alice Smith,ou=People,dc=example,dc=com
+cn: Alice Smith
+displayName:: QWxpY2UgU23DrXRo
+mail: alice@example.com
+telephoneNumber: +1 555 0100
+memberOf: cn=Sales,ou=Groups,dc=example,dc=com
+memberOf: cn=Engineering\, R&D,ou=Groups,dc=exa
+ mple,dc=com
+
+dn: cn=Sales,ou=Groups,dc=example,dc=com
+cn: Sales
+`))
+	st.Assert(t, err, nil)
+	st.Expect(t, source.hasGroups, true)
+	st.Assert(t, len(source.users), 1)
+	st.Expect(t, *source.users[0], syncSourceUser{
+		email:  "alice@example.com",
+		name:   "Alice Smíth",
+		phone:  "+1 555 0100",
+		groups: []string{"Sales", "Engineering, R&D"},
+	})
+}
+
+func TestUsersSync(t *testing.T) {
+	defer gock.Off()
+
+	dir, err := ioutil.TempDir("", "access-cli-test")
+	st.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+
+	sourceFile := filepath.Join(dir, "people.csv")
+	st.Assert(t, ioutil.WriteFile(sourceFile, []byte(`Email,Display Name,Phone,Groups
+Alice@example.com,Alice Smith,,Sales;Unknown
+bob@example.com,Bob,+1 555 0101,Sales
+`), 0600), nil)
+
+	gock.New(baseURIinTests()).
+		Get("/users").
+		Reply(200).
+		SetHeader("total", "2").
+		JSON([]map[string]interface{}{
+			{"id": 1, "name": "Alice", "email": "alice@example.com", "enabled": true, "groups": []interface{}{}},
+			{"id": 3, "name": "Carol", "email": "carol@example.com", "enabled": true, "groups": []interface{}{}},
+		})
+	gock.New(baseURIinTests()).
+		Get("/groups").
+		Reply(200).
+		SetHeader("total", "1").
+		JSON([]map[string]interface{}{{"id": 10, "name": "Sales"}})
+	gock.New(baseURIinTests()).
+		Patch("/users/1").
+		BodyString(`"group_ids":\[10\]`).
+		Reply(200).
+		JSON(map[string]interface{}{"id": 1})
+	gock.New(baseURIinTests()).
+		Post("/users").
+		BodyString(`"phone_number":"\+1 555 0101"`).
+		Reply(201).
+		JSON(map[string]interface{}{"id": 4, "email": "bob@example.com"})
+	gock.New(baseURIinTests()).
+		Patch("/users/3").
+		BodyString(`"enabled":false`).
+		Reply(200).
+		JSON(map[string]interface{}{"id": 3})
+
+	cmd := rootCmd
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{
+		"users",
+		"sync",
+		"-o=json",
+		"--continue-on-error=false",
+		"--source", sourceFile,
+	})
+	err = cmd.Execute()
+	st.Expect(t, err, nil)
+	st.Expect(t, gock.IsDone(), true)
+
+	changes := []manifestChange{}
+	st.Assert(t, json.Unmarshal(out.Bytes(), &changes), nil)
+	st.Assert(t, len(changes), 3)
+	st.Expect(t, changes[0].Action, manifestActionUpdate)
+	st.Expect(t, changes[0].Fields, []manifestFieldChange{
+		{Field: "name", Current: "Alice", Desired: "Alice Smith"},
+		{Field: "groups", Current: "", Desired: "Sales"},
+	})
+	st.Expect(t, changes[1].Action, manifestActionCreate)
+	st.Expect(t, changes[1].Result, "created")
+	st.Expect(t, changes[2].Action, syncActionDisable)
+	st.Expect(t, changes[2].Name, "carol@example.com")
+}
@@ -38,9 +38,25 @@ func setTenant(cmd *cobra.Command, t tenantable) {
 	if _, ok := cmd.Annotations[flagInitTenant]; !ok {
 		panic("setTenant called for command where tenant flag was not initialized. This is a bug!")
 	}
+	t.SetTenantID(strfmt.UUID(tenantID(cmd)))
+}
+
+// tenantID returns the ID of the tenant the command operates on
+func tenantID(cmd *cobra.Command) string {
 	tenant, err := cmd.Flags().GetString("tenant")
 	if err != nil || tenant == "" {
 		tenant = global.CurrentTenant
 	}
-	t.SetTenantID(strfmt.UUID(tenant))
+	return tenant
+}
+
+// withTenant calls do with cmd operating on the given tenant
+func withTenant(cmd *cobra.Command, tenant string, do func() error) error {
+	previous, err := cmd.Flags().GetString("tenant")
+	if err != nil {
+		panic("withTenant called for command where tenant flag was not initialized. This is a bug!")
+	}
+	cmd.Flags().Set("tenant", tenant)
+	defer cmd.Flags().Set("tenant", previous)
+	return do()
 }
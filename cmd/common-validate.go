@@ -0,0 +1,488 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// inputValidationProblem is a problem found in a record of an input file.
+// Warnings do not prevent the record from being processed
+type inputValidationProblem struct {
+	record  int
+	line    int
+	message string
+	warning bool
+}
+
+type inputValidationReport struct {
+	records  int
+	problems []inputValidationProblem
+}
+
+func (r *inputValidationReport) add(entry *inputEntry, warning bool, format string, a ...interface{}) {
+	r.problems = append(r.problems, inputValidationProblem{
+		record:  entry.Record,
+		line:    entry.Line,
+		message: fmt.Sprintf(format, a...),
+		warning: warning,
+	})
+}
+
+func (r *inputValidationReport) errorCount() int {
+	count := 0
+	for _, p := range r.problems {
+		if !p.warning {
+			count++
+		}
+	}
+	return count
+}
+
+func inputValidateOnly(cmd *cobra.Command) bool {
+	if _, ok := cmd.Annotations[flagInitInput]; !ok {
+		return false
+	}
+	validateOnly, err := cmd.Flags().GetBool("validate-only")
+	return err == nil && validateOnly
+}
+
+// inputNeedsNoServer returns whether the input flags passed to cmd request an
+// operation that is performed without contacting the server
+func inputNeedsNoServer(cmd *cobra.Command) bool {
+	return inputValidateOnly(cmd) || inputPrintTemplate(cmd) != ""
+}
+
+// validateInputFile reads the whole input file and checks every record
+// against the input fields of cmd, without performing any operations.
+// The report is kept so that printListOutputAndError can render it
+func validateInputFile(cmd *cobra.Command) error {
+	data := global.InputData[cmd]
+	interpolator, err := newInputInterpolator(cmd, false)
+	if err != nil {
+		return err
+	}
+	source, closer, err := openInputSource(cmd, interpolator)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	report := &inputValidationReport{}
+	data.validation = report
+
+	for {
+		entry, err := source.next()
+		if err == io.EOF {
+			break
+		}
+		var recordErr *inputRecordError
+		if errors.As(err, &recordErr) {
+			report.records++
+			report.add(&inputEntry{Record: report.records}, false, "%v", recordErr)
+			continue
+		}
+		if err != nil {
+			// the rest of the file can not be read, but problems
+			// found so far are still reported
+			report.add(&inputEntry{Record: report.records + 1}, false, "%v", err)
+			break
+		}
+		report.records++
+		validateInputEntry(data.fields, entry, inputStrictIDs(cmd), report)
+	}
+	for _, u := range interpolator.unresolved {
+		report.add(&inputEntry{Line: u.line}, false, "unresolved variable %s", u.name)
+	}
+
+	if count := report.errorCount(); count > 0 {
+		return fmt.Errorf("found %d problem(s) while validating %d record(s)", count, report.records)
+	}
+	return nil
+}
+
+func validateInputEntry(fields []inputField, entry *inputEntry, strictIDs bool, report *inputValidationReport) {
+	var record map[string]interface{}
+	isCSV := entry.Type == wholeCSVObject
+	if isCSV {
+		record, _ = entry.CSVdata.(map[string]interface{})
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(entry.JSON))
+		decoder.UseNumber()
+		if err := decoder.Decode(&record); err != nil || record == nil {
+			report.add(entry, false, "record is not an object")
+			return
+		}
+	}
+
+	known := make(map[string]bool)
+	for _, field := range fields {
+		for _, name := range fieldSchemaNames(field) {
+			known[normalizeSchemaName(strings.Split(name, ".")[0])] = true
+		}
+	}
+	for key := range record {
+		if key == inputErrorField {
+			// added by --failed-out
+			continue
+		}
+		if !known[normalizeSchemaName(key)] {
+			report.add(entry, true, "unknown field %s will be ignored", key)
+		}
+	}
+
+	for _, field := range fields {
+		if field.SchemaName == "" {
+			continue
+		}
+		value, present := lookupSchemaValue(record, field)
+		if !present || value == nil || value == "" {
+			if field.Mandatory {
+				report.add(entry, false, "missing mandatory field %s", field.SchemaName)
+			}
+			continue
+		}
+
+		varType := field.SchemaVarType
+		if varType == "" {
+			varType = field.VarType
+		}
+		if field.Reference != "" && varType == "[]int" && !strictIDs {
+			// names are resolved to IDs when the record is processed
+			varType = "[]reference"
+		}
+		if err := checkInputValueType(varType, value, isCSV); err != nil {
+			report.add(entry, false, "field %s: %v", field.SchemaName, err)
+			continue
+		}
+		if field.Validator != nil && !field.Validator(value) {
+			report.add(entry, false, "invalid value for field %s", field.SchemaName)
+		}
+	}
+}
+
+func fieldSchemaNames(field inputField) []string {
+	if field.SchemaName == "" {
+		return field.SchemaAliases
+	}
+	return append([]string{field.SchemaName}, field.SchemaAliases...)
+}
+
+// normalizeSchemaName makes names comparable the same way they are
+// matched when records are decoded: ignoring case and underscores
+func normalizeSchemaName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// lookupSchemaValue finds the value of field in record, following
+// dotted schema names (e.g. conditions.rbac.enabled) into nested objects
+func lookupSchemaValue(record map[string]interface{}, field inputField) (interface{}, bool) {
+	for _, name := range fieldSchemaNames(field) {
+		var cur interface{} = record
+		found := true
+		for _, part := range strings.Split(name, ".") {
+			m, ok := cur.(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			found = false
+			for key, value := range m {
+				if normalizeSchemaName(key) == normalizeSchemaName(part) {
+					cur = value
+					found = true
+					break
+				}
+			}
+			if !found {
+				break
+			}
+		}
+		if found {
+			return cur, true
+		}
+	}
+	return nil, false
+}
+
+// checkInputValueType checks that value, as read from an input file, can be
+// converted to varType. Values read from CSV files are always strings
+func checkInputValueType(varType string, value interface{}, isCSV bool) error {
+	s, isString := value.(string)
+	switch varType {
+	case "string":
+		if !isString {
+			return fmt.Errorf("expected a string")
+		}
+	case "int":
+		if err := checkInputInt(value); err != nil {
+			return err
+		}
+	case "int.nullable":
+		if isString && s == "null" {
+			return nil
+		}
+		if err := checkInputInt(value); err != nil {
+			return err
+		}
+	case "bool":
+		if isCSV && isString {
+			if _, err := strconv.ParseBool(s); err != nil {
+				return fmt.Errorf("expected a boolean, got %q", s)
+			}
+		} else if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a boolean")
+		}
+	case "[]string", "[]string.skipcomma":
+		if isCSV && isString {
+			return nil
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of strings")
+		}
+		for _, e := range list {
+			if _, ok := e.(string); !ok {
+				return fmt.Errorf("expected a list of strings")
+			}
+		}
+	case "[]int":
+		if isCSV && isString {
+			for _, e := range commaSeparatedListToStringSlice(s) {
+				if e == "" {
+					continue
+				}
+				if _, err := strconv.Atoi(e); err != nil {
+					return fmt.Errorf("expected a list of integers, got %q", s)
+				}
+			}
+			return nil
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of integers")
+		}
+		for _, e := range list {
+			// lists of objects are accepted as long as they have an integer id,
+			// so that the output of list commands can be used as input
+			if m, ok := e.(map[string]interface{}); ok {
+				e = m["id"]
+			}
+			if err := checkInputInt(e); err != nil {
+				return fmt.Errorf("expected a list of integers")
+			}
+		}
+	case "[]reference":
+		if isCSV && isString {
+			return nil
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list of IDs or names")
+		}
+		for _, e := range list {
+			if m, ok := e.(map[string]interface{}); ok {
+				e = m["id"]
+			}
+			if _, ok := e.(string); ok {
+				continue
+			}
+			if err := checkInputInt(e); err != nil {
+				return fmt.Errorf("expected a list of IDs or names")
+			}
+		}
+	case "dnsservers":
+		if isCSV && isString {
+			return nil
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object with protocol and list")
+		}
+		for _, key := range []string{"protocol", "list"} {
+			if _, ok := m[key]; !ok {
+				return fmt.Errorf("expected an object with protocol and list")
+			}
+		}
+	case "[]portmapping":
+		return checkPortMappings(value)
+	default:
+		panic("Unknown input variable type " + varType)
+	}
+	return nil
+}
+
+func checkInputInt(value interface{}) error {
+	switch v := value.(type) {
+	case json.Number:
+		if _, err := v.Int64(); err != nil {
+			return fmt.Errorf("expected an integer, got %s", v)
+		}
+	case string:
+		if _, err := strconv.Atoi(v); err != nil {
+			return fmt.Errorf("expected an integer, got %q", v)
+		}
+	case int, int64:
+	default:
+		return fmt.Errorf("expected an integer")
+	}
+	return nil
+}
+
+// validatePortMappings is the inputField validator for resource port mappings
+func validatePortMappings(input interface{}) bool {
+	return checkPortMappings(input) == nil
+}
+
+// checkPortMappings checks port mappings in any of the forms they can take:
+// colon-separated strings (external:internal:protocol) as given on the command
+// line, objects as found in JSON and YAML files, and the result of parsing the
+// port mappings column of CSV files
+func checkPortMappings(input interface{}) error {
+	switch v := input.(type) {
+	case string:
+		return checkPortMapping(colonMappingToPortMapping(v))
+	case []string:
+		for _, mapping := range v {
+			if err := checkPortMapping(colonMappingToPortMapping(mapping)); err != nil {
+				return err
+			}
+		}
+	case []*models.AccessResourcePortMapping:
+		for _, mapping := range v {
+			if err := checkPortMapping(mapping); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			var err error
+			switch mapping := e.(type) {
+			case string:
+				err = checkPortMapping(colonMappingToPortMapping(mapping))
+			case map[string]interface{}:
+				err = checkPortMappingObject(mapping)
+			default:
+				err = fmt.Errorf("invalid port mapping %v", e)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("expected a list of port mappings")
+	}
+	return nil
+}
+
+func checkPortMappingObject(m map[string]interface{}) error {
+	mapping := &models.AccessResourcePortMapping{Protocol: "tcp"}
+	for key, value := range m {
+		var ports []string
+		switch v := value.(type) {
+		case string:
+			ports = commaSeparatedListToStringSlice(v)
+		case []interface{}:
+			for _, p := range v {
+				ports = append(ports, strings.TrimSpace(fmt.Sprint(p)))
+			}
+		}
+		switch normalizeSchemaName(key) {
+		case "publicports":
+			mapping.PublicPorts = ports
+		case "internalports":
+			mapping.InternalPorts = ports
+		case "protocol":
+			mapping.Protocol = value
+		default:
+			return fmt.Errorf("unknown port mapping field %s", key)
+		}
+	}
+	return checkPortMapping(mapping)
+}
+
+func checkPortMapping(mapping *models.AccessResourcePortMapping) error {
+	if len(mapping.PublicPorts) == 0 {
+		return fmt.Errorf("port mapping is missing the external port")
+	}
+	if len(mapping.InternalPorts) > 0 && len(mapping.InternalPorts) != len(mapping.PublicPorts) {
+		return fmt.Errorf("port mapping has %d external and %d internal ports",
+			len(mapping.PublicPorts), len(mapping.InternalPorts))
+	}
+	for _, port := range append(mapping.PublicPorts, mapping.InternalPorts...) {
+		if err := checkPortOrRange(port); err != nil {
+			return err
+		}
+	}
+	switch mapping.Protocol {
+	case "tcp", "udp", "both":
+	default:
+		return fmt.Errorf("invalid protocol %v in port mapping, must be tcp, udp or both", mapping.Protocol)
+	}
+	return nil
+}
+
+func checkPortOrRange(port string) error {
+	parts := strings.SplitN(port, "-", 2)
+	bounds := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("invalid port %q in port mapping", port)
+		}
+		bounds[i] = n
+	}
+	if len(bounds) == 2 && bounds[0] > bounds[1] {
+		return fmt.Errorf("invalid port range %q in port mapping", port)
+	}
+	return nil
+}
+
+// renderInputValidationReport builds the output for --validate-only,
+// in the same format used for the results of multiple operations
+func renderInputValidationReport(report *inputValidationReport) (table.Writer, []multiOpJSONResult) {
+	tw, j := multiOpBuildTableWriter()
+	for _, p := range report.problems {
+		id := fmt.Sprintf("record %d", p.record)
+		if p.record == 0 {
+			id = fmt.Sprintf("line %d", p.line)
+		} else if p.line > 0 {
+			id = fmt.Sprintf("record %d (line %d)", p.record, p.line)
+		}
+		var result interface{} = errors.New(p.message)
+		if p.warning {
+			result = "warning: " + p.message
+		}
+		multiOpTableWriterAppend(tw, &j, id, result)
+	}
+	if len(report.problems) == 0 {
+		multiOpTableWriterAppend(tw, &j, "*", fmt.Sprintf("%d records validated, no problems found", report.records))
+	}
+	return tw, j
+}
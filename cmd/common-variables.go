@@ -0,0 +1,273 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// matches ${NAME}, ${env:NAME} and the escaped form $${...}
+var inputVariableRegexp = regexp.MustCompile(`\$(\$)?\{([^}]*)\}`)
+
+type unresolvedInputVariable struct {
+	name string
+	line int
+}
+
+// inputInterpolator replaces variable references in input files.
+// When strict, unresolved references cause reading to fail; otherwise they
+// are left untouched and collected in unresolved. Without --var or
+// --var-file, only environment references are replaced and anything else,
+// including $${, is left untouched, as input files written before variables
+// existed may contain a literal ${
+type inputInterpolator struct {
+	vars       map[string]string
+	format     string
+	strict     bool
+	lenient    bool
+	unresolved []unresolvedInputVariable
+}
+
+func newInputInterpolator(cmd *cobra.Command, strict bool) (*inputInterpolator, error) {
+	vars := make(map[string]string)
+
+	inputFormat, err := cmd.Flags().GetString("file-format")
+	if err != nil {
+		return nil, err
+	}
+
+	varFile, err := cmd.Flags().GetString("var-file")
+	if err != nil {
+		return nil, err
+	}
+	if varFile != "" {
+		contents, err := ioutil.ReadFile(varFile)
+		if err != nil {
+			return nil, err
+		}
+		for i, line := range strings.Split(string(contents), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			key, value, err := parseInputVariable(line)
+			if err != nil {
+				return nil, fmt.Errorf("%s line %d: %v", varFile, i+1, err)
+			}
+			vars[key] = value
+		}
+	}
+
+	// variables passed on the command line take precedence over those in files
+	assignments, err := cmd.Flags().GetStringArray("var")
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		key, value, err := parseInputVariable(assignment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --var %q: %v", assignment, err)
+		}
+		vars[key] = value
+	}
+
+	return &inputInterpolator{
+		vars:    vars,
+		format:  inputFormat,
+		strict:  strict,
+		lenient: !cmd.Flags().Changed("var") && !cmd.Flags().Changed("var-file"),
+	}, nil
+}
+
+func parseInputVariable(assignment string) (string, string, error) {
+	parts := strings.SplitN(assignment, "=", 2)
+	key := strings.TrimSpace(parts[0])
+	if len(parts) != 2 || key == "" {
+		return "", "", fmt.Errorf("expected key=value")
+	}
+	if strings.HasPrefix(key, "env:") {
+		return "", "", fmt.Errorf("variable names can not start with env:")
+	}
+	return key, parts[1], nil
+}
+
+func (i *inputInterpolator) lookup(name string) (string, bool) {
+	if strings.HasPrefix(name, "env:") {
+		return os.LookupEnv(strings.TrimPrefix(name, "env:"))
+	}
+	value, ok := i.vars[name]
+	return value, ok
+}
+
+// interpolate replaces the variable references in a line of an input file
+func (i *inputInterpolator) interpolate(line []byte, lineNumber int) ([]byte, error) {
+	var err error
+	result := []byte{}
+	last := 0
+	for _, loc := range inputVariableRegexp.FindAllSubmatchIndex(line, -1) {
+		result = append(result, line[last:loc[0]]...)
+		last = loc[1]
+		match := line[loc[0]:loc[1]]
+		if loc[3] > loc[2] {
+			// $${...} is written as ${...}
+			if i.lenient {
+				result = append(result, match...)
+			} else {
+				result = append(result, match[1:]...)
+			}
+			continue
+		}
+		name := strings.TrimSpace(string(line[loc[4]:loc[5]]))
+		env := strings.HasPrefix(name, "env:")
+		if i.lenient && !env {
+			result = append(result, match...)
+			continue
+		}
+		if value, ok := i.lookup(name); ok {
+			result = append(result, i.escape(value, line[:loc[0]])...)
+			continue
+		}
+		if i.strict && err == nil {
+			err = fmt.Errorf("line %d: unresolved variable %s", lineNumber, name)
+		}
+		i.unresolved = append(i.unresolved, unresolvedInputVariable{name: name, line: lineNumber})
+		result = append(result, match...)
+	}
+	result = append(result, line[last:]...)
+	return result, err
+}
+
+var jsonValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// escape makes value safe to insert in the input format after prefix, the
+// part of the line before the reference. Within a JSON string, values are
+// escaped as string contents; elsewhere they are inserted verbatim, so that
+// they can hold numbers and lists. In CSV, quotes are doubled within a
+// quoted field, and values that contain separators are quoted elsewhere
+func (i *inputInterpolator) escape(value string, prefix []byte) []byte {
+	switch i.format {
+	case "json", "ndjson", "scim":
+		if insideJSONString(prefix) {
+			return []byte(jsonValueEscaper.Replace(value))
+		}
+	case "csv":
+		if bytes.Count(prefix, []byte(`"`))%2 == 1 {
+			return []byte(strings.ReplaceAll(value, `"`, `""`))
+		}
+		if strings.ContainsAny(value, ",\"\r\n") {
+			return []byte(`"` + strings.ReplaceAll(value, `"`, `""`) + `"`)
+		}
+	}
+	return []byte(value)
+}
+
+// insideJSONString tells whether a JSON string is still open at the end of
+// prefix. JSON strings can not span lines, so the line is enough
+func insideJSONString(prefix []byte) bool {
+	inside := false
+	for j := 0; j < len(prefix); j++ {
+		switch {
+		case inside && prefix[j] == '\\':
+			j++
+		case prefix[j] == '"':
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// reader wraps r so that variable references are replaced as it is read
+func (i *inputInterpolator) reader(r io.Reader) io.Reader {
+	return &interpolatingReader{
+		reader:       bufio.NewReader(r),
+		interpolator: i,
+	}
+}
+
+type interpolatingReader struct {
+	reader       *bufio.Reader
+	interpolator *inputInterpolator
+	pending      []byte
+	lineNumber   int
+	err          error
+}
+
+func (r *interpolatingReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		line, err := r.reader.ReadBytes('\n')
+		if len(line) > 0 {
+			r.lineNumber++
+			var ierr error
+			r.pending, ierr = r.interpolator.interpolate(line, r.lineNumber)
+			if ierr != nil {
+				err = ierr
+			}
+		}
+		r.err = err
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// checkInputFileVariables reads the whole input file looking for unresolved
+// variables, so that they can be reported before any record is processed.
+// It does nothing when reading from stdin, which can only be read once
+func checkInputFileVariables(cmd *cobra.Command) error {
+	inputFile, err := cmd.Flags().GetString("from-file")
+	if err != nil {
+		return err
+	}
+	if inputFile == "-" {
+		return nil
+	}
+
+	interpolator, err := newInputInterpolator(cmd, false)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(ioutil.Discard, interpolator.reader(f)); err != nil {
+		return err
+	}
+
+	if len(interpolator.unresolved) > 0 {
+		msgs := []string{}
+		for _, u := range interpolator.unresolved {
+			msgs = append(msgs, fmt.Sprintf("line %d: unresolved variable %s", u.line, u.name))
+		}
+		return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+	}
+	return nil
+}
@@ -39,6 +39,14 @@ func preRunCheckEndpoint(cmd *cobra.Command, args []string) error {
 }
 
 func preRunCheckAuth(cmd *cobra.Command, args []string) error {
+	if inputNeedsNoServer(cmd) {
+		// validation and templates do not need the server
+		return nil
+	}
+	if global.Offline != "" {
+		// requests are served from the saved output
+		return nil
+	}
 	err := preRunCheckEndpoint(cmd, args)
 	if err != nil {
 		return err
@@ -48,15 +48,19 @@ const (
 	// DefaultEndpoint is the default endpoint used by the client
 	DefaultEndpoint = "api.us.access.barracuda.com"
 
-	flagInitFilter      = "filter_flags_init"
-	flagInitPagination  = "pagination_flags_init"
-	flagInitSort        = "sort_flags_init"
-	flagInitSearch      = "search_flags_init"
-	flagInitTenant      = "tenant_flags_init"
-	flagInitOutput      = "output_flags_init"
-	flagInitInput       = "input_flags_init"
-	flagInitMultiOpArg  = "multi_op_arg_flags_init"
-	flagInitLoopControl = "loop_control_flags_init"
+	flagInitFilter       = "filter_flags_init"
+	flagInitPagination   = "pagination_flags_init"
+	flagInitSort         = "sort_flags_init"
+	flagInitSearch       = "search_flags_init"
+	flagInitTenant       = "tenant_flags_init"
+	flagInitOutput       = "output_flags_init"
+	flagInitInput        = "input_flags_init"
+	flagInitMultiOpArg   = "multi_op_arg_flags_init"
+	flagInitLoopControl  = "loop_control_flags_init"
+	flagInitParallel     = "parallel_flags_init"
+	flagInitSkipExisting = "skip_existing_flags_init"
+	flagInitSCIMOutput   = "scim_output_flags_init"
+	flagInitSCIMInput    = "scim_input_flags_init"
 
 	authMethodBearerToken = "bearerToken"
 )
@@ -58,7 +58,7 @@ var adminDeleteCmd = &cobra.Command{
 			setTenant(cmd, params)
 			params.SetID(ids)
 
-			_, err = global.Client.Admins.DeleteAdmin(params, global.AuthWriter)
+			_, err := global.Client.Admins.DeleteAdmin(params, global.AuthWriter)
 			if err != nil {
 				return processErrorResponse(err)
 			}
@@ -71,15 +71,17 @@ var adminDeleteCmd = &cobra.Command{
 			// then we must delete individually, because on a request for multiple deletions,
 			// the server does nothing if one fails
 
-			for _, id := range adminIDs {
-				err = delete([]int64{id})
+			loopControlForEachArg(cmd, len(adminIDs), func(i int) error {
+				return delete([]int64{adminIDs[i]})
+			}, func(i int, err error) bool {
 				var result interface{}
 				result = "success"
 				if err != nil {
 					result = err
 				}
-				multiOpTableWriterAppend(tw, &j, id, result)
-			}
+				multiOpTableWriterAppend(tw, &j, adminIDs[i], result)
+				return true
+			})
 			err = nil
 		} else {
 			err = delete(adminIDs)
@@ -111,5 +113,6 @@ func init() {
 	initMultiOpArgFlags(adminDeleteCmd, "admin", "delete", "id", "[]int64")
 	initOutputFlags(adminDeleteCmd)
 	initLoopControlFlags(adminDeleteCmd)
+	initParallelFlags(adminDeleteCmd)
 	initTenantFlags(adminDeleteCmd)
 }
@@ -77,18 +77,22 @@ var deviceDeleteCmd = &cobra.Command{
 
 		tw, j := multiOpBuildTableWriter()
 
-		for _, arg := range deviceIDs {
-			err = delete(arg)
-			if err != nil {
-				multiOpTableWriterAppend(tw, &j, arg, processErrorResponse(err))
-				if loopControlContinueOnError(cmd) {
-					err = nil
-					continue
+		loopControlForEachArg(cmd, len(deviceIDs), func(i int) error {
+			return delete(deviceIDs[i])
+		}, func(i int, opErr error) bool {
+			if opErr != nil {
+				multiOpTableWriterAppend(tw, &j, deviceIDs[i], processErrorResponse(opErr))
+				if loopControlContinueOnError(cmd) || isDryRunError(opErr) {
+					return true
+				}
+				if err == nil {
+					err = opErr
 				}
-				return printListOutputAndError(cmd, j, tw, len(deviceIDs), err)
+				return false
 			}
-			multiOpTableWriterAppend(tw, &j, arg, "success")
-		}
+			multiOpTableWriterAppend(tw, &j, deviceIDs[i], "success")
+			return true
+		})
 		return printListOutputAndError(cmd, j, tw, len(deviceIDs), err)
 	},
 }
@@ -109,5 +113,6 @@ func init() {
 	initMultiOpArgFlags(deviceDeleteCmd, "device", "delete", "id", "[]strfmt.UUID")
 	initOutputFlags(deviceDeleteCmd)
 	initLoopControlFlags(deviceDeleteCmd)
+	initParallelFlags(deviceDeleteCmd)
 	initTenantFlags(deviceDeleteCmd)
 }
@@ -58,7 +58,7 @@ var domainDeleteCmd = &cobra.Command{
 			setTenant(cmd, params)
 			params.SetID(ids)
 
-			_, err = global.Client.Assets.DeleteAsset(params, global.AuthWriter)
+			_, err := global.Client.Assets.DeleteAsset(params, global.AuthWriter)
 			if err != nil {
 				return processErrorResponse(err)
 			}
@@ -71,15 +71,17 @@ var domainDeleteCmd = &cobra.Command{
 			// then we must delete individually, because on a request for multiple deletions,
 			// the server does nothing if one fails
 
-			for _, id := range assetIDs {
-				err = delete([]int64{id})
+			loopControlForEachArg(cmd, len(assetIDs), func(i int) error {
+				return delete([]int64{assetIDs[i]})
+			}, func(i int, err error) bool {
 				var result interface{}
 				result = "success"
 				if err != nil {
 					result = err
 				}
-				multiOpTableWriterAppend(tw, &j, id, result)
-			}
+				multiOpTableWriterAppend(tw, &j, assetIDs[i], result)
+				return true
+			})
 			err = nil
 		} else {
 			err = delete(assetIDs)
@@ -111,5 +113,6 @@ func init() {
 	initMultiOpArgFlags(domainDeleteCmd, "domain", "delete", "id", "[]int64")
 	initOutputFlags(domainDeleteCmd)
 	initLoopControlFlags(domainDeleteCmd)
+	initParallelFlags(domainDeleteCmd)
 	initTenantFlags(domainDeleteCmd)
 }
@@ -58,7 +58,7 @@ var groupDeleteCmd = &cobra.Command{
 			setTenant(cmd, params)
 			params.SetID(ids)
 
-			_, err = global.Client.Groups.DeleteGroup(params, global.AuthWriter)
+			_, err := global.Client.Groups.DeleteGroup(params, global.AuthWriter)
 			if err != nil {
 				return processErrorResponse(err)
 			}
@@ -71,15 +71,17 @@ var groupDeleteCmd = &cobra.Command{
 			// then we must delete individually, because on a request for multiple deletions,
 			// the server does nothing if one fails
 
-			for _, id := range groupIDs {
-				err = delete([]int64{id})
+			loopControlForEachArg(cmd, len(groupIDs), func(i int) error {
+				return delete([]int64{groupIDs[i]})
+			}, func(i int, err error) bool {
 				var result interface{}
 				result = "success"
 				if err != nil {
 					result = err
 				}
-				multiOpTableWriterAppend(tw, &j, id, result)
-			}
+				multiOpTableWriterAppend(tw, &j, groupIDs[i], result)
+				return true
+			})
 			err = nil
 		} else {
 			err = delete(groupIDs)
@@ -111,5 +113,6 @@ func init() {
 	initMultiOpArgFlags(groupDeleteCmd, "group", "delete", "id", "[]int64")
 	initOutputFlags(groupDeleteCmd)
 	initLoopControlFlags(groupDeleteCmd)
+	initParallelFlags(groupDeleteCmd)
 	initTenantFlags(groupDeleteCmd)
 }
@@ -58,7 +58,7 @@ var policyDeleteCmd = &cobra.Command{
 			setTenant(cmd, params)
 			params.SetID(ids)
 
-			_, err = global.Client.AccessPolicies.DeletePolicy(params, global.AuthWriter)
+			_, err := global.Client.AccessPolicies.DeletePolicy(params, global.AuthWriter)
 			if err != nil {
 				return processErrorResponse(err)
 			}
@@ -71,15 +71,17 @@ var policyDeleteCmd = &cobra.Command{
 			// then we must delete individually, because on a request for multiple deletions,
 			// the server does nothing if one fails
 
-			for _, id := range policyIDs {
-				err = delete([]int64{id})
+			loopControlForEachArg(cmd, len(policyIDs), func(i int) error {
+				return delete([]int64{policyIDs[i]})
+			}, func(i int, err error) bool {
 				var result interface{}
 				result = "success"
 				if err != nil {
 					result = err
 				}
-				multiOpTableWriterAppend(tw, &j, id, result)
-			}
+				multiOpTableWriterAppend(tw, &j, policyIDs[i], result)
+				return true
+			})
 			err = nil
 		} else {
 			err = delete(policyIDs)
@@ -111,5 +113,6 @@ func init() {
 	initMultiOpArgFlags(policyDeleteCmd, "policy", "delete", "id", "[]int64")
 	initOutputFlags(policyDeleteCmd)
 	initLoopControlFlags(policyDeleteCmd)
+	initParallelFlags(policyDeleteCmd)
 	initTenantFlags(policyDeleteCmd)
 }
@@ -72,15 +72,17 @@ var proxyDeleteCmd = &cobra.Command{
 			// then we must delete individually, because on a request for multiple deletions,
 			// the server does nothing if one fails
 
-			for _, id := range proxyIDs {
-				err = delete([]strfmt.UUID{id})
+			loopControlForEachArg(cmd, len(proxyIDs), func(i int) error {
+				return delete([]strfmt.UUID{proxyIDs[i]})
+			}, func(i int, err error) bool {
 				var result interface{}
 				result = "success"
 				if err != nil {
 					result = err
 				}
-				multiOpTableWriterAppend(tw, &j, id, result)
-			}
+				multiOpTableWriterAppend(tw, &j, proxyIDs[i], result)
+				return true
+			})
 			err = nil
 		} else {
 			err = delete(proxyIDs)
@@ -112,5 +114,6 @@ func init() {
 	initMultiOpArgFlags(proxyDeleteCmd, "proxy", "delete", "id", "[]strfmt.UUID")
 	initOutputFlags(proxyDeleteCmd)
 	initLoopControlFlags(proxyDeleteCmd)
+	initParallelFlags(proxyDeleteCmd)
 	initTenantFlags(proxyDeleteCmd)
 }
@@ -72,15 +72,17 @@ var resourceDeleteCmd = &cobra.Command{
 			// then we must delete individually, because on a request for multiple deletions,
 			// the server does nothing if one fails
 
-			for _, id := range resourceIDs {
-				err = delete([]strfmt.UUID{id})
+			loopControlForEachArg(cmd, len(resourceIDs), func(i int) error {
+				return delete([]strfmt.UUID{resourceIDs[i]})
+			}, func(i int, err error) bool {
 				var result interface{}
 				result = "success"
 				if err != nil {
 					result = err
 				}
-				multiOpTableWriterAppend(tw, &j, id, result)
-			}
+				multiOpTableWriterAppend(tw, &j, resourceIDs[i], result)
+				return true
+			})
 			err = nil
 		} else {
 			err = delete(resourceIDs)
@@ -112,5 +114,6 @@ func init() {
 	initMultiOpArgFlags(resourceDeleteCmd, "resource", "delete", "id", "[]strfmt.UUID")
 	initOutputFlags(resourceDeleteCmd)
 	initLoopControlFlags(resourceDeleteCmd)
+	initParallelFlags(resourceDeleteCmd)
 	initTenantFlags(resourceDeleteCmd)
 }
@@ -58,7 +58,7 @@ var userDeleteCmd = &cobra.Command{
 			setTenant(cmd, params)
 			params.SetID(ids)
 
-			_, err = global.Client.Users.DeleteUser(params, global.AuthWriter)
+			_, err := global.Client.Users.DeleteUser(params, global.AuthWriter)
 			if err != nil {
 				return processErrorResponse(err)
 			}
@@ -71,15 +71,17 @@ var userDeleteCmd = &cobra.Command{
 			// then we must delete individually, because on a request for multiple deletions,
 			// the server does nothing if one fails
 
-			for _, id := range userIDs {
-				err = delete([]int64{id})
+			loopControlForEachArg(cmd, len(userIDs), func(i int) error {
+				return delete([]int64{userIDs[i]})
+			}, func(i int, err error) bool {
 				var result interface{}
 				result = "success"
 				if err != nil {
 					result = err
 				}
-				multiOpTableWriterAppend(tw, &j, id, result)
-			}
+				multiOpTableWriterAppend(tw, &j, userIDs[i], result)
+				return true
+			})
 			err = nil
 		} else {
 			err = delete(userIDs)
@@ -111,5 +113,6 @@ func init() {
 	initMultiOpArgFlags(userDeleteCmd, "user", "delete", "id", "[]int64")
 	initOutputFlags(userDeleteCmd)
 	initLoopControlFlags(userDeleteCmd)
+	initParallelFlags(userDeleteCmd)
 	initTenantFlags(userDeleteCmd)
 }
@@ -151,3 +151,42 @@ func TestDeleteUsersIndividualRequestsOneFail(t *testing.T) {
 	st.Expect(t, r[1].OK, false)
 	st.Expect(t, r[2].OK, true)
 }
+
+func TestDeleteUsersDryRun(t *testing.T) {
+	defer gock.Off()
+	defer func() { global.DryRun = false }()
+
+	gock.New(baseURIinTests()).
+		Delete("/users/345,9845").
+		Reply(204)
+
+	cmd := rootCmd
+
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{
+		"users",
+		"delete",
+		"--dry-run",
+		"--continue-on-error=false",
+		"-o=json",
+		"345",
+		"9845",
+	})
+	err := cmd.Execute()
+	st.Expect(t, err, nil)
+	// the request must not have been sent
+	st.Expect(t, gock.IsPending(), true)
+
+	output, err := ioutil.ReadAll(buf)
+	st.Expect(t, err, nil)
+
+	r := []multiOpJSONResult{}
+	err = json.Unmarshal(output, &r)
+	st.Expect(t, err, nil)
+	st.Expect(t, len(r), 1)
+	st.Expect(t, r[0].ID, "345,9845")
+	st.Expect(t, r[0].OK, true)
+	st.Expect(t, r[0].Result, "would delete: DELETE /api/v1/tenants/testTenantID/users/345,9845")
+}
@@ -117,7 +117,7 @@ var webPolicyDeleteCmd = &cobra.Command{
 					result = err
 				}
 				multiOpTableWriterAppend(tw, &j, id, result)
-				if err != nil {
+				if err != nil && !isDryRunError(err) {
 					return err
 				}
 			}
@@ -0,0 +1,94 @@
+// Package cmd implements fyde-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc. <hello@barracuda.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// driftCheckCmd represents the check command
+var driftCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that the tenant configuration matches YAML manifests",
+	Long: `Check that the groups, proxies, resources, policies, web policies, domains and
+settings declared in YAML manifests (see the plan command) match the live
+objects of the tenant. Each object is compared by a hash of the normalized JSON
+of the fields in the manifests, leaving out volatile fields (created_at,
+updated_at, last_access_at and access_count).
+With --prune, objects of the kinds in the manifests that are not declared are
+reported as unmanaged.
+Exits with status 0 when everything matches, 2 when some objects drifted, and 1
+when the check could not be performed.
+For example: ` + ApplicationName + ` drift check -f manifests/ -o json`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		set, prune, err := readManifestsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		results, err := checkDrift(cmd, set, prune)
+		if err != nil {
+			return err
+		}
+
+		tw := driftBuildTableWriter()
+		for _, result := range results {
+			driftTableWriterAppend(tw, result)
+		}
+		// the report is output in full before failing, so that it can be
+		// written with --output-file
+		err = printListOutputAndError(cmd, results, tw, len(results), nil)
+		if err != nil {
+			return err
+		}
+		return driftResultsError(results)
+	},
+}
+
+func init() {
+	driftCmd.AddCommand(driftCheckCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// driftCheckCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// driftCheckCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(driftCheckCmd)
+	initTenantFlags(driftCheckCmd)
+	initManifestFlags(driftCheckCmd)
+	driftCheckCmd.Flags().Lookup("prune").Usage = "report objects that are not in the manifests, for the kinds present in the manifests"
+}
@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -46,10 +48,10 @@ var adminsEditCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := adminBuildTableWriter()
 		createdList := []*models.Admin{}
-		total := 0
+		var total int64
 		err := forAllInput(cmd, args, false,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				params := apiadmins.NewEditAdminParams()
 				setTenant(cmd, params)
 				// IDs are not part of the request body, so we use this workaround
@@ -85,7 +87,7 @@ var adminsEditCmd = &cobra.Command{
 				createdList = append(createdList, nil)
 				adminTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -104,6 +106,7 @@ func init() {
 
 	initOutputFlags(adminsEditCmd)
 	initLoopControlFlags(adminsEditCmd)
+	initParallelFlags(adminsEditCmd)
 	initTenantFlags(adminsEditCmd)
 	initInputFlags(adminsEditCmd, "admin",
 		inputField{
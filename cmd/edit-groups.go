@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/spf13/cobra"
 
 	apigroups "github.com/barracuda-cloudgen-access/access-cli/client/groups"
@@ -44,10 +46,10 @@ var groupsEditCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := groupBuildTableWriter()
 		createdList := []*apigroups.EditGroupOKBody{}
-		total := 0
+		var total int64
 		err := forAllInput(cmd, args, false,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				params := apigroups.NewEditGroupParams()
 				setTenant(cmd, params)
 				// IDs are not part of the request body, so we use this workaround
@@ -81,7 +83,7 @@ var groupsEditCmd = &cobra.Command{
 				createdList = append(createdList, nil)
 				groupTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -100,6 +102,7 @@ func init() {
 
 	initOutputFlags(groupsEditCmd)
 	initLoopControlFlags(groupsEditCmd)
+	initParallelFlags(groupsEditCmd)
 	initTenantFlags(groupsEditCmd)
 	initInputFlags(groupsEditCmd, "group",
 		inputField{
@@ -119,6 +122,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "name",
 		},
 		inputField{
 			Name:            "Description",
@@ -127,6 +131,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "description",
 		},
 		inputField{
 			Name:            "Color",
@@ -136,5 +141,6 @@ func init() {
 			Mandatory:       false,
 			DefaultValue:    "",
 			Validator:       validateHTMLHexColor,
+			SchemaName:      "color",
 		})
 }
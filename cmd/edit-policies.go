@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -44,10 +46,10 @@ var policiesEditCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := policyBuildTableWriter()
 		createdList := []*apipolicies.EditPolicyOKBody{}
-		total := 0
+		var total int64
 		err := forAllInput(cmd, args, false,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				params := apipolicies.NewEditPolicyParams()
 				setTenant(cmd, params)
 				// IDs are not part of the request body, so we use this workaround
@@ -112,7 +114,7 @@ var policiesEditCmd = &cobra.Command{
 				createdList = append(createdList, nil)
 				policyTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -131,6 +133,7 @@ func init() {
 
 	initOutputFlags(policiesEditCmd)
 	initLoopControlFlags(policiesEditCmd)
+	initParallelFlags(policiesEditCmd)
 	initTenantFlags(policiesEditCmd)
 	initInputFlags(policiesEditCmd, "policy",
 		inputField{
@@ -150,6 +153,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "name",
 		},
 		inputField{
 			Name:            "Resources",
@@ -158,6 +162,8 @@ func init() {
 			VarType:         "[]string",
 			Mandatory:       false,
 			DefaultValue:    []string{},
+			SchemaName:      "access_resource_ids",
+			Reference:       "resource",
 		},
 		inputField{
 			Name:            "RBAC",
@@ -166,6 +172,7 @@ func init() {
 			VarType:         "bool",
 			Mandatory:       false,
 			DefaultValue:    false,
+			SchemaName:      "conditions.rbac.enabled",
 		},
 		inputField{
 			Name:            "Groups",
@@ -174,6 +181,8 @@ func init() {
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "conditions.rbac.group_ids",
+			Reference:       "group",
 		},
 		inputField{
 			Name:            "Users",
@@ -182,5 +191,7 @@ func init() {
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "conditions.rbac.user_ids",
+			Reference:       "user",
 		})
 }
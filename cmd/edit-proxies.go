@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -45,10 +47,10 @@ var proxiesEditCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := proxyBuildTableWriter()
 		createdList := []*apiproxies.EditProxyOKBody{}
-		total := 0
+		var total int64
 		err := forAllInput(cmd, args, false,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				params := apiproxies.NewEditProxyParams()
 				setTenant(cmd, params)
 				// IDs are not part of the request body, so we use this workaround
@@ -84,7 +86,7 @@ var proxiesEditCmd = &cobra.Command{
 				createdList = append(createdList, nil)
 				proxyTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -103,6 +105,7 @@ func init() {
 
 	initOutputFlags(proxiesEditCmd)
 	initLoopControlFlags(proxiesEditCmd)
+	initParallelFlags(proxiesEditCmd)
 	initTenantFlags(proxiesEditCmd)
 	initInputFlags(proxiesEditCmd, "proxy",
 		inputField{
@@ -122,6 +125,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "name",
 		},
 		inputField{
 			Name:            "Location",
@@ -130,7 +134,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
-			SchemaName:      "name",
+			SchemaName:      "location",
 		},
 		inputField{
 			Name:            "Host",
@@ -139,6 +143,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "host",
 		},
 		inputField{
 			Name:            "Port",
@@ -147,5 +152,6 @@ func init() {
 			VarType:         "int",
 			Mandatory:       false,
 			DefaultValue:    0,
+			SchemaName:      "port",
 		})
 }
@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -47,10 +49,10 @@ var resourcesEditCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := resourceBuildTableWriter()
 		createdList := []*models.AccessResource{}
-		total := 0
+		var total int64
 		err := forAllInput(cmd, args, false,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				params := apiresources.NewEditResourceParams()
 				setTenant(cmd, params)
 				// IDs are not part of the request body, so we use this workaround
@@ -108,7 +110,7 @@ var resourcesEditCmd = &cobra.Command{
 				createdList = append(createdList, nil)
 				resourceTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -127,6 +129,7 @@ func init() {
 
 	initOutputFlags(resourcesEditCmd)
 	initLoopControlFlags(resourcesEditCmd)
+	initParallelFlags(resourcesEditCmd)
 	initTenantFlags(resourcesEditCmd)
 	initInputFlags(resourcesEditCmd, "resource",
 		inputField{
@@ -155,6 +158,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "public_host",
 		},
 		inputField{
 			Name:            "Resource host",
@@ -163,6 +167,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "internal_host",
 		},
 		inputField{
 			Name:            "Port mappings",
@@ -171,22 +176,29 @@ func init() {
 			VarType:         "[]string.skipcomma",
 			Mandatory:       false,
 			DefaultValue:    []string{},
+			SchemaName:      "port_mappings",
+			SchemaVarType:   "[]portmapping",
+			Validator:       validatePortMappings,
 		},
 		inputField{
 			Name:            "Proxy",
 			FlagName:        "proxy",
-			FlagDescription: "specify the new proxy ID for the resource",
+			FlagDescription: "specify the new proxy ID or name for the resource",
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "access_proxy_id",
+			Reference:       "proxy",
 		},
 		inputField{
 			Name:            "Policies",
 			FlagName:        "policies",
-			FlagDescription: "specify a list of comma-separated policy IDs for the created resource",
+			FlagDescription: "specify a list of comma-separated policy IDs or names for the resource",
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "access_policy_ids",
+			Reference:       "policy",
 		},
 		inputField{
 			Name:            "Wildcard Exceptions",
@@ -195,6 +207,7 @@ func init() {
 			VarType:         "[]string",
 			Mandatory:       false,
 			DefaultValue:    []string{},
+			SchemaName:      "wildcard_exceptions",
 		},
 		inputField{
 			Name:            "Notes",
@@ -203,6 +216,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "notes",
 		},
 		inputField{
 			Name:            "Fixed Last Octet",
@@ -211,5 +225,7 @@ func init() {
 			VarType:         "string", // use string to read "null" pseudo value
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "fixed_last_octet",
+			SchemaVarType:   "int.nullable",
 		})
 }
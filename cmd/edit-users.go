@@ -18,6 +18,8 @@ limitations under the License.
 */
 
 import (
+	"sync/atomic"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/spf13/cobra"
 
@@ -45,7 +47,7 @@ var usersEditCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := userBuildTableWriter()
 		createdList := []*models.User{}
-		total := 0
+		var total int64
 
 		// Assign deprecated username if name was not supplied
 		name, _ := cmd.Flags().GetString("name")
@@ -56,7 +58,7 @@ var usersEditCmd = &cobra.Command{
 
 		err := forAllInput(cmd, args, false,
 			func(values *inputEntry) (interface{}, error) { // do func
-				total++ // this is the total of successful+failures, must increment before failure
+				atomic.AddInt64(&total, 1) // this is the total of successful+failures, must increment before failure
 				params := apiusers.NewEditUserParams()
 				setTenant(cmd, params)
 				// IDs are not part of the request body, so we use this workaround
@@ -106,7 +108,7 @@ var usersEditCmd = &cobra.Command{
 				createdList = append(createdList, nil)
 				userTableWriterAppendError(tw, err, id)
 			})
-		return printListOutputAndError(cmd, createdList, tw, total, err)
+		return printListOutputAndError(cmd, createdList, tw, int(total), err)
 	},
 }
 
@@ -125,6 +127,7 @@ func init() {
 
 	initOutputFlags(usersEditCmd)
 	initLoopControlFlags(usersEditCmd)
+	initParallelFlags(usersEditCmd)
 	initTenantFlags(usersEditCmd)
 
 	initInputFlags(usersEditCmd, "user",
@@ -154,6 +157,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "name",
 		},
 		inputField{
 			Name:            "Email",
@@ -162,6 +166,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "email",
 		},
 		inputField{
 			Name:            "Phone",
@@ -170,14 +175,18 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "phone_number",
 		},
 		inputField{
 			Name:            "Groups",
 			FlagName:        "groups",
-			FlagDescription: "specify the new group IDs for the user",
+			FlagDescription: "specify the new group IDs or names for the user",
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "group_ids",
+			SchemaAliases:   []string{"groups"},
+			Reference:       "group",
 		},
 		inputField{
 			Name:            "Enabled",
@@ -186,6 +195,7 @@ func init() {
 			VarType:         "bool",
 			Mandatory:       false,
 			DefaultValue:    true,
+			SchemaName:      "enabled",
 		})
 	usersEditCmd.Flags().MarkDeprecated("username", "use name instead")
 
@@ -44,12 +44,16 @@ var webPoliciesEditCmd = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		tw := setWebPolicyBuildTableWriter()
-		gparams := apiwebpolicies.NewListWebPoliciesParams()
-		setTenant(cmd, gparams)
-		resp, err := global.Client.WebPolicies.ListWebPolicies(gparams, global.AuthWriter)
+		resp := &apiwebpolicies.ListWebPoliciesOK{Payload: &models.WebPolicy{}}
+		var err error
+		if !inputNeedsNoServer(cmd) {
+			gparams := apiwebpolicies.NewListWebPoliciesParams()
+			setTenant(cmd, gparams)
+			resp, err = global.Client.WebPolicies.ListWebPolicies(gparams, global.AuthWriter)
 
-		if err != nil {
-			return processErrorResponse(err)
+			if err != nil {
+				return processErrorResponse(err)
+			}
 		}
 		mainRulesetId := resp.Payload.ID
 		policies := resp.Payload
@@ -100,10 +104,10 @@ var webPoliciesEditCmd = &cobra.Command{
 						idx := int64(s)
 						policy.Index = idx
 					},
-					func(s bool) { policy.Log = s },
-					func(s bool) { policy.Notify = s },
-					func(s bool) { policy.Disabled = s },
-					func(s bool) { policy.Alert = s },
+					func(s bool) { policy.Log = &s },
+					func(s bool) { policy.Notify = &s },
+					func(s bool) { policy.Disabled = &s },
+					func(s bool) { policy.Alert = &s },
 				)
 				if err != nil {
 					fmt.Println("error: ", err)
@@ -118,7 +122,7 @@ var webPoliciesEditCmd = &cobra.Command{
 				params.SetWebPolicy(apiwebpolicies.EditWebPolicyBody{Data: &policy.EditWebPolicyParamsBodyData})
 
 				_, err = global.Client.WebPolicies.EditWebPolicy(params, global.AuthWriter)
-				if err != nil {
+				if err != nil && !isDryRunError(err) {
 					return nil, err
 				}
 
@@ -152,15 +156,23 @@ var webPoliciesEditCmd = &cobra.Command{
 				}
 
 				modifiedPolicy.Action = policy.Action
-				modifiedPolicy.Alert = policy.Alert
+				if policy.Alert != nil {
+					modifiedPolicy.Alert = *policy.Alert
+				}
 				modifiedPolicy.Categories = policy.Categories
-				modifiedPolicy.Disabled = policy.Disabled
+				if policy.Disabled != nil {
+					modifiedPolicy.Disabled = *policy.Disabled
+				}
 				modifiedPolicy.Domains = policy.Domains
 				modifiedPolicy.GroupIds = policy.GroupIds
 				modifiedPolicy.Index = policy.Index
 				modifiedPolicy.Label = policy.Label
-				modifiedPolicy.Log = policy.Log
-				modifiedPolicy.Notify = policy.Notify
+				if policy.Log != nil {
+					modifiedPolicy.Log = *policy.Log
+				}
+				if policy.Notify != nil {
+					modifiedPolicy.Notify = *policy.Notify
+				}
 				modifiedPolicy.Type = policy.Type
 				modifiedPolicy.UserIds = policy.UserIds
 				modifiedPolicy.ID = policy.ID
@@ -208,6 +220,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       true,
 			DefaultValue:    "",
+			SchemaName:      "id",
 		},
 		inputField{
 			Name:            "Label",
@@ -216,6 +229,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "label",
 		},
 		inputField{
 			Name:            "Action",
@@ -224,6 +238,7 @@ func init() {
 			VarType:         "string",
 			Mandatory:       false,
 			DefaultValue:    "",
+			SchemaName:      "action",
 		},
 		inputField{
 			Name:            "PolicyType",
@@ -242,6 +257,7 @@ func init() {
 			VarType:         "[]string",
 			Mandatory:       false,
 			DefaultValue:    []string{},
+			SchemaName:      "domains",
 		},
 		inputField{
 			Name:            "Categories",
@@ -250,6 +266,7 @@ func init() {
 			VarType:         "[]int",
 			Mandatory:       false,
 			DefaultValue:    []int{},
+			SchemaName:      "categories",
 		},
 		inputField{
 			Name:            "Users",
@@ -260,6 +277,7 @@ func init() {
 			DefaultValue:    []int{},
 			MainField:       true,
 			SchemaName:      "user_ids",
+			Reference:       "user",
 		},
 		inputField{
 			Name:            "Groups",
@@ -270,6 +288,7 @@ func init() {
 			DefaultValue:    []int{},
 			MainField:       true,
 			SchemaName:      "group_ids",
+			Reference:       "group",
 		},
 		inputField{
 			Name:            "Index",
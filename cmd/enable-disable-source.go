@@ -57,27 +57,32 @@ var sourceEnableCmd = &cobra.Command{
 
 		tw, j := multiOpBuildTableWriter()
 
-		for _, arg := range uuidArgs {
+		loopControlForEachArg(cmd, len(uuidArgs), func(i int) error {
 			params := apisources.NewEditAssetSourceParams()
 			setTenant(cmd, params)
-			params.SetID(arg)
+			params.SetID(uuidArgs[i])
 			params.SetAssetSource(apisources.EditAssetSourceBody{
 				AssetSource: &apisources.EditAssetSourceParamsBodyAssetSource{
 					Enabled: &enable,
 				},
 			})
 
-			_, err = global.Client.AssetSources.EditAssetSource(params, global.AuthWriter)
-			if err != nil {
-				multiOpTableWriterAppend(tw, &j, arg, processErrorResponse(err))
-				if loopControlContinueOnError(cmd) {
-					err = nil
-					continue
+			_, err := global.Client.AssetSources.EditAssetSource(params, global.AuthWriter)
+			return err
+		}, func(i int, opErr error) bool {
+			if opErr != nil {
+				multiOpTableWriterAppend(tw, &j, uuidArgs[i], processErrorResponse(opErr))
+				if loopControlContinueOnError(cmd) || isDryRunError(opErr) {
+					return true
+				}
+				if err == nil {
+					err = opErr
 				}
-				return printListOutputAndError(cmd, j, tw, len(uuidArgs), err)
+				return false
 			}
-			multiOpTableWriterAppend(tw, &j, arg, "success")
-		}
+			multiOpTableWriterAppend(tw, &j, uuidArgs[i], "success")
+			return true
+		})
 		return printListOutputAndError(cmd, j, tw, len(uuidArgs), err)
 	},
 }
@@ -110,7 +115,9 @@ func init() {
 	initOutputFlags(sourceDisableCmd)
 
 	initLoopControlFlags(sourceEnableCmd)
+	initParallelFlags(sourceEnableCmd)
 	initLoopControlFlags(sourceDisableCmd)
+	initParallelFlags(sourceDisableCmd)
 
 	initTenantFlags(sourceEnableCmd)
 	initTenantFlags(sourceDisableCmd)
@@ -57,27 +57,32 @@ var userEnableCmd = &cobra.Command{
 
 		tw, j := multiOpBuildTableWriter()
 
-		for _, arg := range intArgs {
+		loopControlForEachArg(cmd, len(intArgs), func(i int) error {
 			params := apiusers.NewEditUserParams()
 			setTenant(cmd, params)
-			params.SetID(arg)
+			params.SetID(intArgs[i])
 			params.SetUser(apiusers.EditUserBody{
 				User: &apiusers.EditUserParamsBodyUser{
 					Enabled: &enable,
 				},
 			})
 
-			_, err = global.Client.Users.EditUser(params, global.AuthWriter)
-			if err != nil {
-				multiOpTableWriterAppend(tw, &j, arg, processErrorResponse(err))
-				if loopControlContinueOnError(cmd) {
-					err = nil
-					continue
+			_, err := global.Client.Users.EditUser(params, global.AuthWriter)
+			return err
+		}, func(i int, opErr error) bool {
+			if opErr != nil {
+				multiOpTableWriterAppend(tw, &j, intArgs[i], processErrorResponse(opErr))
+				if loopControlContinueOnError(cmd) || isDryRunError(opErr) {
+					return true
+				}
+				if err == nil {
+					err = opErr
 				}
-				return printListOutputAndError(cmd, j, tw, len(intArgs), err)
+				return false
 			}
-			multiOpTableWriterAppend(tw, &j, arg, "success")
-		}
+			multiOpTableWriterAppend(tw, &j, intArgs[i], "success")
+			return true
+		})
 		return printListOutputAndError(cmd, j, tw, len(intArgs), err)
 	},
 }
@@ -110,7 +115,9 @@ func init() {
 	initOutputFlags(userDisableCmd)
 
 	initLoopControlFlags(userEnableCmd)
+	initParallelFlags(userEnableCmd)
 	initLoopControlFlags(userDisableCmd)
+	initParallelFlags(userDisableCmd)
 
 	initTenantFlags(userEnableCmd)
 	initTenantFlags(userDisableCmd)
@@ -0,0 +1,99 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/barracuda-cloudgen-access/access-cli/models"
+)
+
+// groupsExportCmd represents the export command
+var groupsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all groups",
+	Long: `Export all groups of the tenant, ordered by ID.
+With -o scim, groups are written as a SCIM 2.0 ListResponse of Group resources,
+including their members and, for groups synced from a directory, their
+external ID.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups, err := listAllGroups(cmd, "")
+		if err != nil {
+			return processErrorResponse(err)
+		}
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].ID < groups[j].ID })
+
+		tw := groupBuildTableWriter()
+		for _, item := range groups {
+			groupTableWriterAppendFromMultiple(tw, item)
+		}
+
+		var data interface{} = groups
+		if outputIsSCIM(cmd) {
+			// members are only listed in the groups of each user
+			users, err := listAllUsers(cmd, "")
+			if err != nil {
+				return processErrorResponse(err)
+			}
+			list := []models.User{}
+			for _, item := range users {
+				list = append(list, item.User)
+			}
+			members := scimGroupMembers(list)
+			resources := []interface{}{}
+			for _, item := range groups {
+				resources = append(resources, scimGroupFromGroup(item.Group, members[item.ID]))
+			}
+			data = newSCIMListResponse(resources)
+		}
+		return printListOutputAndError(cmd, data, tw, len(groups), nil)
+	},
+}
+
+func init() {
+	groupsCmd.AddCommand(groupsExportCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// groupsExportCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// groupsExportCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(groupsExportCmd)
+	initSCIMOutputFlags(groupsExportCmd)
+	initTenantFlags(groupsExportCmd)
+}
@@ -0,0 +1,86 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// usersExportCmd represents the export command
+var usersExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export all users",
+	Long: `Export all users of the tenant, ordered by ID.
+With -o scim, users are written as a SCIM 2.0 ListResponse of User resources,
+which can be imported with: ` + ApplicationName + ` users import --file-format scim -f <file>`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		users, err := listAllUsers(cmd, "")
+		if err != nil {
+			return processErrorResponse(err)
+		}
+		sort.SliceStable(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+		tw := userBuildTableWriter()
+		for _, item := range users {
+			userTableWriterAppend(tw, item.User)
+		}
+
+		var data interface{} = users
+		if outputIsSCIM(cmd) {
+			resources := []interface{}{}
+			for _, item := range users {
+				resources = append(resources, scimUserFromUser(item.User))
+			}
+			data = newSCIMListResponse(resources)
+		}
+		return printListOutputAndError(cmd, data, tw, len(users), nil)
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersExportCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// usersExportCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// usersExportCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(usersExportCmd)
+	initSCIMOutputFlags(usersExportCmd)
+	initTenantFlags(usersExportCmd)
+}
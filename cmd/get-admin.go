@@ -118,7 +118,7 @@ func adminTableWriterAppend(tw table.Writer, admin *models.Admin) {
 }
 
 func adminTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
@@ -108,7 +108,7 @@ func domainTableWriterAppend(tw table.Writer, asset *models.Asset) {
 }
 
 func domainTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
@@ -121,7 +121,7 @@ func groupTableWriterAppendFromMultiple(tw table.Writer, item *apigroups.ListGro
 }
 
 func groupTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
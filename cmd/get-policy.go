@@ -108,7 +108,7 @@ func policyTableWriterAppend(tw table.Writer, policy models.AccessPolicy, access
 }
 
 func policyTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
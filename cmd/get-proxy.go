@@ -131,7 +131,7 @@ func proxyTableWriterAppend(tw table.Writer, proxy models.AccessProxy, accessRes
 }
 
 func proxyTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
@@ -115,7 +115,7 @@ func resourceTableWriterAppend(tw table.Writer, resource models.AccessResource)
 }
 
 func resourceTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
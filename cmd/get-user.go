@@ -123,7 +123,7 @@ func userTableWriterAppend(tw table.Writer, user models.User) {
 }
 
 func userTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
@@ -0,0 +1,128 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// groupsMembersAddCmd represents the members add command
+var groupsMembersAddCmd = &cobra.Command{
+	Use:   "add [group ID or name] [user ID or email]...",
+	Short: "Add users to a group",
+	Long: `Add users to a group, keeping their other groups.
+Users are given by ID or email, as arguments or through stdin, either as the
+JSON output of a users command or one per line.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			return fmt.Errorf("missing group ID or name argument")
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		add := strings.HasPrefix(cmd.Use, "add")
+
+		users, err := groupMembersReadUsers(args[1:])
+		if err != nil {
+			return err
+		}
+
+		resolver := newGroupMembersResolver(cmd)
+		groupID, err := resolver.resolve("group", args[0])
+		if err != nil {
+			return err
+		}
+
+		tw, j := multiOpBuildTableWriter()
+
+		results := make([]string, len(users))
+		loopControlForEachArg(cmd, len(users), func(i int) error {
+			userID, err := resolver.resolve("user", users[i])
+			if err != nil {
+				return err
+			}
+			results[i], err = changeGroupMembership(cmd, userID.(int64), groupID.(int64), add)
+			return err
+		}, func(i int, opErr error) bool {
+			if opErr != nil {
+				multiOpTableWriterAppend(tw, &j, users[i], processErrorResponse(opErr))
+				if loopControlContinueOnError(cmd) || isDryRunError(opErr) {
+					return true
+				}
+				if err == nil {
+					err = opErr
+				}
+				return false
+			}
+			multiOpTableWriterAppend(tw, &j, users[i], results[i])
+			return true
+		})
+		return printListOutputAndError(cmd, j, tw, len(users), err)
+	},
+}
+
+// groupsMembersRemoveCmd represents the members remove command
+var groupsMembersRemoveCmd *cobra.Command
+
+func init() {
+	removeCmd := *groupsMembersAddCmd
+	removeCmd.Use = "remove [group ID or name] [user ID or email]..."
+	removeCmd.Aliases = []string{"rm"}
+	removeCmd.Short = "Remove users from a group"
+	removeCmd.Long = `Remove users from a group, keeping their other groups.
+Users are given by ID or email, as arguments or through stdin, either as the
+JSON output of a users command or one per line.`
+	groupsMembersRemoveCmd = &removeCmd
+	groupsMembersCmd.AddCommand(groupsMembersAddCmd)
+	groupsMembersCmd.AddCommand(groupsMembersRemoveCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// groupsMembersAddCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// groupsMembersAddCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(groupsMembersAddCmd)
+	initOutputFlags(groupsMembersRemoveCmd)
+
+	initLoopControlFlags(groupsMembersAddCmd)
+	initParallelFlags(groupsMembersAddCmd)
+	initLoopControlFlags(groupsMembersRemoveCmd)
+	initParallelFlags(groupsMembersRemoveCmd)
+
+	initTenantFlags(groupsMembersAddCmd)
+	initTenantFlags(groupsMembersRemoveCmd)
+}
@@ -0,0 +1,105 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	apiusers "github.com/barracuda-cloudgen-access/access-cli/client/users"
+)
+
+// groupsMembersListCmd represents the members list command
+var groupsMembersListCmd = &cobra.Command{
+	Use:     "list [group ID or name]",
+	Aliases: []string{"ls"},
+	Short:   "List the users of a group",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		if len(args) != 1 {
+			return fmt.Errorf("expected one group ID or name argument")
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groupID, err := newGroupMembersResolver(cmd).resolve("group", args[0])
+		if err != nil {
+			return err
+		}
+
+		params := apiusers.NewListUsersParams()
+		setSort(cmd, params)
+		setSearchQuery(cmd, params)
+		setTenant(cmd, params)
+		params.SetGroupID([]int64{groupID.(int64)})
+		completePayload := []*apiusers.ListUsersOKBodyItems0{}
+		total := 0
+		cutStart, cutEnd, err := forAllPages(cmd, params, func() (int, int64, error) {
+			resp, err := global.Client.Users.ListUsers(params, global.AuthWriter)
+			if err != nil {
+				return 0, 0, err
+			}
+			completePayload = append(completePayload, resp.Payload...)
+			total = int(resp.Total)
+			return len(resp.Payload), resp.Total, err
+		})
+		if err != nil {
+			return processErrorResponse(err)
+		}
+		completePayload = completePayload[cutStart:cutEnd]
+
+		tw := userBuildTableWriter()
+
+		for _, item := range completePayload {
+			userTableWriterAppend(tw, item.User)
+		}
+
+		return printListOutputAndError(cmd, completePayload, tw, total, err)
+	},
+}
+
+func init() {
+	groupsMembersCmd.AddCommand(groupsMembersListCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// groupsMembersListCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// groupsMembersListCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initPaginationFlags(groupsMembersListCmd)
+	initSortFlags(groupsMembersListCmd)
+	initTenantFlags(groupsMembersListCmd)
+	initSearchFlags(groupsMembersListCmd)
+	initOutputFlags(groupsMembersListCmd)
+}
@@ -178,7 +178,7 @@ func setWebPolicyTableWriterAppend(tw table.Writer, ruleID strfmt.UUID, policy m
 }
 
 func webPolicyTableWriterAppendError(tw table.Writer, err error, id interface{}) {
-	idStr := "[ERR]"
+	idStr := errorRowPrefix(err)
 	if id != nil {
 		idStr += fmt.Sprintf(" %v", id)
 	}
@@ -54,22 +54,27 @@ var deviceRevokeCmd = &cobra.Command{
 
 		tw, j := multiOpBuildTableWriter()
 
-		for _, id := range uuidArgs {
+		loopControlForEachArg(cmd, len(uuidArgs), func(i int) error {
 			params := apidevices.NewRevokeDeviceParams()
 			setTenant(cmd, params)
-			params.SetID(id)
-
-			_, err = global.Client.Devices.RevokeDevice(params, global.AuthWriter)
-			if err != nil {
-				multiOpTableWriterAppend(tw, &j, id, processErrorResponse(err))
-				if loopControlContinueOnError(cmd) {
-					err = nil
-					continue
+			params.SetID(uuidArgs[i])
+
+			_, err := global.Client.Devices.RevokeDevice(params, global.AuthWriter)
+			return err
+		}, func(i int, opErr error) bool {
+			if opErr != nil {
+				multiOpTableWriterAppend(tw, &j, uuidArgs[i], processErrorResponse(opErr))
+				if loopControlContinueOnError(cmd) || isDryRunError(opErr) {
+					return true
 				}
-				return printListOutputAndError(cmd, j, tw, len(uuidArgs), err)
+				if err == nil {
+					err = opErr
+				}
+				return false
 			}
-			multiOpTableWriterAppend(tw, &j, id, "success")
-		}
+			multiOpTableWriterAppend(tw, &j, uuidArgs[i], "success")
+			return true
+		})
 		return printListOutputAndError(cmd, j, tw, len(uuidArgs), err)
 	},
 }
@@ -90,5 +95,6 @@ func init() {
 	initMultiOpArgFlags(deviceRevokeCmd, "device", "revoke", "id", "[]strfmt.UUID")
 	initOutputFlags(deviceRevokeCmd)
 	initLoopControlFlags(deviceRevokeCmd)
+	initParallelFlags(deviceRevokeCmd)
 	initTenantFlags(deviceRevokeCmd)
 }
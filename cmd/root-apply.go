@@ -0,0 +1,82 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Change the tenant configuration to match YAML manifests",
+	Long: `Create, update and, with --prune, delete objects so that the tenant
+configuration matches YAML manifests (see the plan command).
+Objects are created and updated in dependency order: settings, proxies,
+groups, resources, policies, web policies and domains. Objects are deleted
+last, in the reverse order. Pruning only applies to the kinds present in the
+manifests, and never to settings.
+For example: ` + ApplicationName + ` apply -f tenant/ --prune`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		set, prune, err := readManifestsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		tw := manifestApplyBuildTableWriter()
+		changes := []*manifestChange{}
+		err = runManifests(cmd, set, prune, true, func(change *manifestChange, err error) {
+			manifestApplyTableWriterAppend(tw, change, err)
+			changes = append(changes, change)
+		})
+		return printListOutputAndError(cmd, changes, tw, len(changes), err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// applyCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// applyCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(applyCmd)
+	initLoopControlFlags(applyCmd)
+	initTenantFlags(applyCmd)
+	initManifestFlags(applyCmd)
+}
@@ -0,0 +1,168 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+	"golang.org/x/term"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the configuration of a tenant with another tenant or a saved copy",
+	Long: `Compare users, admins, groups, proxies, resources, policies, web policies and
+settings of a tenant with those of another tenant (--against-tenant), or with
+those saved to a directory (--against-dir) by the export command or by list
+commands with -o json (one file per kind, named users.json, groups.json, etc.).
+Objects are matched by email (users and admins), label (web policies) or name,
+ignoring case, rather than by ID, and references to other objects are compared
+by name. IDs and timestamps are not compared.
+Objects and fields only in the tenant are shown as added, and those only in the
+other tenant or directory as removed.
+For example: ` + ApplicationName + ` diff --tenant <staging> --against-tenant <production>`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		againstTenant, err := cmd.Flags().GetString("against-tenant")
+		if err != nil {
+			return err
+		}
+		againstDir, err := cmd.Flags().GetString("against-dir")
+		if err != nil {
+			return err
+		}
+		if (againstTenant == "") == (againstDir == "") {
+			return fmt.Errorf("exactly one of --against-tenant and --against-dir must be specified")
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		if !funk.Contains([]string{"unified", "json", "json-pretty", "markdown"}, output) {
+			return fmt.Errorf("invalid output format %s", output)
+		}
+
+		only, err := cmd.Flags().GetStringSlice("only")
+		if err != nil {
+			return err
+		}
+		for _, kind := range only {
+			found := false
+			for _, k := range diffKinds {
+				found = found || k.name == kind
+			}
+			if !found {
+				return fmt.Errorf("unknown kind %s in --only", kind)
+			}
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		againstTenant, err := cmd.Flags().GetString("against-tenant")
+		if err != nil {
+			return err
+		}
+		againstDir, err := cmd.Flags().GetString("against-dir")
+		if err != nil {
+			return err
+		}
+		only, err := cmd.Flags().GetStringSlice("only")
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+		color, err := cmd.Flags().GetBool("color")
+		if err != nil {
+			return err
+		}
+
+		new := diffTenantSource(cmd, tenantID(cmd))
+		var old diffSource
+		if againstDir != "" {
+			old = diffDirSource(againstDir)
+		} else {
+			tenant, err := resolveTenant(cmd, againstTenant)
+			if err != nil {
+				return fmt.Errorf("--against-tenant: %v", err)
+			}
+			old = diffTenantSource(cmd, tenant)
+		}
+
+		entries, err := diffSources(old, new, only)
+		if err != nil {
+			return err
+		}
+
+		var result string
+		switch output {
+		case "unified":
+			result = renderDiffUnified(entries, old.name, new.name, color)
+		case "markdown":
+			result = renderDiffMarkdown(entries, old.name, new.name)
+		case "json":
+			result, err = renderJSON(entries)
+		case "json-pretty":
+			result, err = renderPrettyJSON(entries)
+		}
+		if err != nil {
+			return err
+		}
+		cmd.Println(result)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// diffCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// diffCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initTenantFlags(diffCmd)
+	diffCmd.Flags().String("against-tenant", "", "ID or name of the tenant to compare with")
+	diffCmd.Flags().String("against-dir", "", "directory with saved objects to compare with")
+	diffCmd.Flags().StringSlice("only", []string{}, "kinds of objects to compare: users, admins, groups, proxies, resources, policies, webpolicies and/or settings")
+	diffCmd.Flags().StringP("output", "o", "unified", "output format (unified, json, json-pretty or markdown)")
+	diffCmd.Flags().Bool("color", term.IsTerminal(int(os.Stdout.Fd())), "color the unified output (default true if terminal)")
+}
@@ -0,0 +1,88 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the tenant configuration to a directory",
+	Long: `Export users, groups, devices, resources, policies, proxies, admins, assets,
+asset sources, web policy rulesets and settings to a directory, one file per
+kind of object, with records ordered by ID.
+A manifest file records the endpoint, tenant, time of the export and CLI version.
+For example: ` + ApplicationName + ` export --dir backup/`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("file-format")
+		if err != nil {
+			return err
+		}
+
+		manifest, err := exportTenant(cmd, dir, format)
+		if err != nil {
+			return err
+		}
+
+		tw := exportBuildTableWriter()
+		for _, file := range manifest.Files {
+			exportTableWriterAppend(tw, file)
+		}
+		return printListOutputAndError(cmd, manifest.Files, tw, len(manifest.Files), nil)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// exportCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// exportCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(exportCmd)
+	initTenantFlags(exportCmd)
+	exportCmd.Flags().String("dir", "", "directory to write the export to")
+	exportCmd.Flags().String("file-format", "yaml", "format of the exported files: yaml or json")
+	exportCmd.MarkFlagRequired("dir")
+}
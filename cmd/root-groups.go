@@ -28,9 +28,17 @@ var groupsCmd = &cobra.Command{
 	Short:   "Operations on groups",
 }
 
+var groupsMembersCmd = &cobra.Command{
+	Use:     "members",
+	Aliases: []string{"member"},
+	Short:   "Operations on group members",
+}
+
 func init() {
 	rootCmd.AddCommand(groupsCmd)
 
+	groupsCmd.AddCommand(groupsMembersCmd)
+
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
@@ -0,0 +1,123 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create objects in a tenant from an export",
+	Long: `Create groups, users, proxies, policies, resources and web policies in a tenant,
+from a directory of files written by the export command (or by list commands
+with -o json, one file per kind named groups.json, users.json, etc.).
+As the objects are created with new IDs, references between them are remapped.
+The mapping between the IDs in the export and those of the created objects is
+written to a mapping file; objects already in that file are not created again,
+so an interrupted import can be run again, and kinds can be imported separately
+with --only.
+For example: ` + ApplicationName + ` import --dir backup/ --tenant <target tenant ID>`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		only, err := cmd.Flags().GetStringSlice("only")
+		if err != nil {
+			return err
+		}
+		for _, kind := range only {
+			found := false
+			for _, k := range importKinds {
+				found = found || k.name == kind
+			}
+			if !found {
+				return fmt.Errorf("unknown kind %s in --only", kind)
+			}
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return err
+		}
+		only, err := cmd.Flags().GetStringSlice("only")
+		if err != nil {
+			return err
+		}
+		mappingFile, err := cmd.Flags().GetString("mapping-file")
+		if err != nil {
+			return err
+		}
+		if mappingFile == "" {
+			mappingFile = filepath.Join(dir, "import-mapping-"+tenantID(cmd)+".json")
+		}
+		mapping, err := readImportMapping(mappingFile)
+		if err != nil {
+			return err
+		}
+
+		tw, j := importBuildTableWriter()
+		loopErr := importTenant(cmd, dir, only, mapping, func(result importJSONResult) {
+			importTableWriterAppend(tw, &j, result)
+		})
+		if !global.DryRun {
+			// the mapping is written even if the import failed, to resume it
+			if err := writeImportMapping(mappingFile, mapping); err != nil {
+				return err
+			}
+		}
+		return printListOutputAndError(cmd, j, tw, len(j), loopErr)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// importCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// importCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(importCmd)
+	initLoopControlFlags(importCmd)
+	initTenantFlags(importCmd)
+	importCmd.Flags().String("dir", "", "directory with the export to import")
+	importCmd.Flags().StringSlice("only", []string{}, "kinds of objects to import: groups, users, proxies, policies, resources and/or webpolicies")
+	importCmd.Flags().String("mapping-file", "", "file mapping exported IDs to the IDs of the created objects (default import-mapping-<tenant>.json in the export directory)")
+	importCmd.MarkFlagRequired("dir")
+}
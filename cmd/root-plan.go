@@ -0,0 +1,84 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the changes needed to match YAML manifests",
+	Long: `Show the changes needed for the tenant configuration to match YAML manifests.
+Manifests may list groups, proxies, resources, policies, webpolicies and
+domains, identified by name (label for web policies), and settings.
+Only the fields present in the manifests are compared.
+For example: ` + ApplicationName + ` plan -f tenant/`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		set, prune, err := readManifestsFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		tw := manifestPlanBuildTableWriter()
+		changes := []*manifestChange{}
+		err = runManifests(cmd, set, prune, false, func(change *manifestChange, err error) {
+			changes = append(changes, change)
+			manifestPlanTableWriterAppend(tw, change)
+		})
+		if err != nil {
+			return err
+		}
+		err = printListOutputAndError(cmd, changes, tw, len(changes), nil)
+		cmd.PrintErrln("Plan: " + manifestPlanSummary(changes))
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// planCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// planCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(planCmd)
+	initTenantFlags(planCmd)
+	initManifestFlags(planCmd)
+}
@@ -0,0 +1,68 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema [command]",
+	Short: "Output the JSON Schema of input files for a command",
+	Long: `Output a JSON Schema describing the records accepted by a command in --from-file input files.
+For example: ` + ApplicationName + ` schema users add`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		target, remaining, err := rootCmd.Find(args)
+		if err != nil {
+			return err
+		}
+		if len(remaining) > 0 || target == rootCmd {
+			return fmt.Errorf("unknown command %s", strings.Join(args, " "))
+		}
+		if _, ok := target.Annotations[flagInitInput]; !ok {
+			return fmt.Errorf("command %s does not accept input files", target.CommandPath())
+		}
+
+		schema := buildInputJSONSchema(global.InputData[target].fields, target.CommandPath()+" input file")
+		o, err := renderPrettyJSON(schema)
+		if err != nil {
+			return err
+		}
+		cmd.Println(o)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// schemaCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// schemaCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
@@ -0,0 +1,42 @@
+// Package cmd implements fyde-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc. <hello@barracuda.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+import (
+	"github.com/spf13/cobra"
+)
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:     "snapshot",
+	Aliases: []string{"snapshots"},
+	Short:   "Local history of the tenant configuration",
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// snapshotCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// snapshotCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+}
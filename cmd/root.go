@@ -18,8 +18,11 @@ limitations under the License.
 */
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 	"os"
@@ -52,6 +55,9 @@ type globalInfo struct {
 	Client           *apiclient.CloudGenAccessConsole
 	AuthWriter       runtime.ClientAuthInfoWriter
 	VerboseLevel     int
+	DryRun           bool
+	Offline          string
+	DryRunRequests   []*dryRunRequest
 	WriteFiles       bool
 	FetchPerPage     int
 	DefaultRangeSize int
@@ -78,6 +84,10 @@ var rootCmd = &cobra.Command{
 func Execute(versionInfo *VersionInformation) {
 	version = *versionInfo
 	if err := rootCmd.Execute(); err != nil {
+		// some errors, like drift being detected, have their own exit code
+		if e, ok := err.(interface{ ExitCode() int }); ok {
+			os.Exit(e.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
@@ -97,6 +107,8 @@ func init() {
 	d = filepath.Join(getUserConfigPath(), AuthFileName)
 	rootCmd.PersistentFlags().StringVar(&authFile, "auth", "", "credentials file (default is "+d+")")
 	rootCmd.PersistentFlags().IntVarP(&global.VerboseLevel, "verbose", "v", 0, "verbose output level, higher levels are more verbose")
+	rootCmd.PersistentFlags().BoolVar(&global.DryRun, "dry-run", false, "show the requests that would modify data, without sending them")
+	rootCmd.PersistentFlags().StringVar(&global.Offline, "offline", "", "serve list and get commands from a directory of saved list -o json output (or an export), without contacting the endpoint")
 
 	rootCmd.PersistentFlags().SetNormalizeFunc(aliasNormalizeFunc)
 
@@ -123,7 +135,9 @@ func aliasNormalizeFunc(f *pflag.FlagSet, name string) pflag.NormalizedName {
 func initClient() {
 
 	endpoint := authViper.GetString(ckeyAuthEndpoint)
-	if endpoint == "" {
+	if global.Offline != "" {
+		endpoint = offlineEndpoint
+	} else if endpoint == "" {
 		return
 	}
 	if strings.Contains(endpoint, "fyde") {
@@ -141,6 +155,9 @@ func initClient() {
 		}
 	}
 	transport := http.DefaultTransport
+	if global.Offline != "" {
+		transport = &offlineTransport{dir: global.Offline}
+	}
 
 	schemes := []string{"https"}
 	insecureUseHTTP := authViper.GetBool(ckeyAuthUseInsecureHTTP)
@@ -149,8 +166,9 @@ func initClient() {
 		schemes = []string{"http"}
 	}
 
+	// offline data is read from disk, so TLS settings do not apply to it
 	insecureSkipVerify := authViper.GetBool(ckeyAuthSkipTLSVerify)
-	if insecureSkipVerify && !insecureUseHTTP {
+	if insecureSkipVerify && !insecureUseHTTP && global.Offline == "" {
 		fmt.Fprintln(os.Stderr, "WARNING: TLS certificate verification is being skipped for the endpoint. THIS IS INSECURE.")
 		transport = &http.Transport{
 			TLSClientConfig: &tls.Config{
@@ -188,6 +206,13 @@ func initClient() {
 		T: transport,
 	}
 
+	// dryRunTransport must wrap at the very end, so that requests it holds back
+	// are not logged as if they had been sent
+	global.DryRunRequests = nil
+	transport = &dryRunTransport{
+		T: transport,
+	}
+
 	global.Transport = httptransport.New(endpoint, "/api", schemes)
 	global.Transport.Transport = transport
 
@@ -245,6 +270,44 @@ func (t *setUserAgentTransport) RoundTrip(req *http.Request) (*http.Response, er
 	return t.T.RoundTrip(req)
 }
 
+// dryRunTransport lets through requests that only read data. When --dry-run
+// is passed, all other requests are recorded and fail with a dryRunError
+type dryRunTransport struct {
+	T http.RoundTripper
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return t.T.RoundTrip(req)
+	}
+	if !global.DryRun {
+		return t.T.RoundTrip(req)
+	}
+
+	r := &dryRunRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+	if req.URL.RawQuery != "" {
+		r.Path += "?" + req.URL.RawQuery
+	}
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = string(b)
+		buf := new(bytes.Buffer)
+		if json.Compact(buf, b) == nil {
+			r.Body = buf.String()
+		}
+	}
+	global.DryRunRequests = append(global.DryRunRequests, r)
+	return nil, &dryRunError{request: r}
+}
+
 type dumpRequestResponseTransport struct {
 	T http.RoundTripper
 }
@@ -133,6 +133,8 @@ func init() {
 			FlagDescription: "specify period (in days) for the device certificate",
 			VarType:         "string", // use string to read "null" pseudo value
 			DefaultValue:    "30",
+			SchemaName:      "certificate_period",
+			SchemaVarType:   "int.nullable",
 		},
 		inputField{
 			Name:            "Contact Admin Action",
@@ -140,6 +142,7 @@ func init() {
 			FlagDescription: "Configured url action to contact admin. Example: mailto:support@acme.corp",
 			VarType:         "string",
 			DefaultValue:    "",
+			SchemaName:      "contact_admin_action",
 		},
 		inputField{
 			Name:            "Enrollment Polling Time",
@@ -147,6 +150,8 @@ func init() {
 			FlagDescription: "Configured polling time to update agent settings (in seconds)",
 			VarType:         "string", // use string to read "null" pseudo value
 			DefaultValue:    "600",
+			SchemaName:      "enrollment_polling_time_in_seconds",
+			SchemaVarType:   "int.nullable",
 		},
 		inputField{
 			Name:            "History Screen Disabled",
@@ -154,6 +159,7 @@ func init() {
 			FlagDescription: "Turn on to disable the CloudGen Access App history screen.",
 			VarType:         "bool",
 			DefaultValue:    false,
+			SchemaName:      "history_screen_disabled",
 		},
 		inputField{
 			Name:            "DNS Servers List",
@@ -161,6 +167,8 @@ func init() {
 			FlagDescription: "Enforce agent to use a DNS server config. Format: \"protocol:IP1,IP2\". Protocols: [plain, dns_over_tls]",
 			VarType:         "string",
 			DefaultValue:    "",
+			SchemaName:      "dns_servers",
+			SchemaVarType:   "dnsservers",
 		},
 	)
 }
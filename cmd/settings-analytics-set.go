@@ -104,6 +104,7 @@ func init() {
 			FlagDescription: "Analytics server host url. Use empty string to disable.",
 			VarType:         "string",
 			DefaultValue:    "",
+			SchemaName:      "url",
 		},
 		inputField{
 			Name:            "Disable SSL",
@@ -111,6 +112,7 @@ func init() {
 			FlagDescription: "Skips SSL server certificate checking for HTTPS events. WARNING: only use for development purposes.",
 			VarType:         "bool",
 			DefaultValue:    false,
+			SchemaName:      "disable_ssl",
 		},
 		inputField{
 			Name:            "Intercept All Domains",
@@ -118,6 +120,7 @@ func init() {
 			FlagDescription: "Logs all domain resolutions. Note: think before enabling, since this is an intrusive setting.",
 			VarType:         "bool",
 			DefaultValue:    false,
+			SchemaName:      "intercept_all_domains",
 		},
 	)
 }
@@ -103,6 +103,7 @@ func init() {
 			FlagDescription: "specify period (in days) for the expiration of the enrollment link",
 			VarType:         "int",
 			DefaultValue:    0,
+			SchemaName:      "expiration_days",
 		},
 		inputField{
 			Name:            "Refcount",
@@ -110,6 +111,7 @@ func init() {
 			FlagDescription: "Available slots for enrollment",
 			VarType:         "int",
 			DefaultValue:    0,
+			SchemaName:      "refcount",
 		},
 	)
 }
@@ -0,0 +1,68 @@
+// Package cmd implements fyde-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc. <hello@barracuda.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotListCmd represents the list command
+var snapshotListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the stored snapshots of the tenant",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// snapshots are stored locally, so there is no need to be logged in
+		return preRunFlagChecks(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		tenant := tenantID(cmd)
+		if tenant == "" {
+			return fmt.Errorf("no current tenant, specify one with --tenant")
+		}
+		snapshots, err := listSnapshots(tenant)
+		if err != nil {
+			return err
+		}
+
+		tw := snapshotBuildTableWriter()
+		for _, info := range snapshots {
+			snapshotTableWriterAppend(tw, info)
+		}
+		return printListOutputAndError(cmd, snapshots, tw, len(snapshots), nil)
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotListCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// snapshotListCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// snapshotListCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(snapshotListCmd)
+	initTenantFlags(snapshotListCmd)
+}
@@ -0,0 +1,100 @@
+// Package cmd implements fyde-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc. <hello@barracuda.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotLogCmd represents the log command
+var snapshotLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show how an object changed across the stored snapshots",
+	Long: `Show how an object changed across the stored snapshots of the tenant, oldest
+first: when it appeared, the fields that changed between snapshots, and when it
+was removed. Snapshots where the object did not change are not shown, and
+volatile fields (created_at, updated_at, last_access_at and access_count) are
+not compared.
+Objects are given as kind/id, where kind is user, group, device, resource,
+policy, proxy, admin, asset, source, webpolicy or settings (whose IDs are
+agent_configuration, analytics and enrollment).
+For example: ` + ApplicationName + ` snapshot log --object resource/42`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		// snapshots are stored locally, so there is no need to be logged in
+		err := preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		object, err := cmd.Flags().GetString("object")
+		if err != nil {
+			return err
+		}
+		_, _, err = parseSnapshotObject(object)
+		return err
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		object, err := cmd.Flags().GetString("object")
+		if err != nil {
+			return err
+		}
+		kind, id, err := parseSnapshotObject(object)
+		if err != nil {
+			return err
+		}
+		tenant := tenantID(cmd)
+		if tenant == "" {
+			return fmt.Errorf("no current tenant, specify one with --tenant")
+		}
+		snapshots, err := listSnapshots(tenant)
+		if err != nil {
+			return err
+		}
+		entries, err := snapshotLog(snapshots, kind, id)
+		if err != nil {
+			return err
+		}
+
+		tw := snapshotLogBuildTableWriter()
+		for _, entry := range entries {
+			snapshotLogTableWriterAppend(tw, entry)
+		}
+		return printListOutputAndError(cmd, entries, tw, len(entries), nil)
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotLogCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// snapshotLogCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// snapshotLogCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(snapshotLogCmd)
+	initTenantFlags(snapshotLogCmd)
+	snapshotLogCmd.Flags().String("object", "", "object to show the changes of, as kind/id (e.g. resource/42)")
+	snapshotLogCmd.MarkFlagRequired("object")
+}
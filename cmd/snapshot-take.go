@@ -0,0 +1,74 @@
+// Package cmd implements fyde-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc. <hello@barracuda.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+import (
+	"github.com/spf13/cobra"
+)
+
+// snapshotTakeCmd represents the take command
+var snapshotTakeCmd = &cobra.Command{
+	Use:   "take",
+	Short: "Store a snapshot of the tenant configuration",
+	Long: `Store a compressed, timestamped snapshot of the users, groups, devices,
+resources, policies, proxies, admins, assets, asset sources, web policy rulesets
+and settings of the tenant in the cache directory.
+Taking snapshots regularly (e.g. from cron) builds a history of configuration
+changes, which can be inspected with snapshot log.
+For example: ` + ApplicationName + ` snapshot take`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		info, err := takeSnapshot(cmd)
+		if err != nil {
+			return err
+		}
+
+		tw := snapshotBuildTableWriter()
+		snapshotTableWriterAppend(tw, info)
+		return printListOutputAndError(cmd, info, tw, 1, nil)
+	},
+}
+
+func init() {
+	snapshotCmd.AddCommand(snapshotTakeCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// snapshotTakeCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// snapshotTakeCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(snapshotTakeCmd)
+	initTenantFlags(snapshotTakeCmd)
+}
@@ -0,0 +1,173 @@
+// Package cmd implements access-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// usersSyncCmd represents the sync command
+var usersSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Reconcile users with a directory export",
+	Long: `Reconcile the users of the console with an authoritative directory export in
+LDIF or CSV format, matching users by email (ignoring case).
+Users missing from the console are created. The names and group memberships of
+existing users are updated to those in the source, and disabled users in the
+source are enabled again. Console users that are not in the source are disabled,
+or deleted with --delete.
+In LDIF, users are entries with a mail attribute; names are taken from
+displayName or cn, phones from telephoneNumber or mobile, and groups from the
+first component of each memberOf DN. CSV files need a header row with an email
+column, and may have name, phone and groups columns (groups separated by ";").
+Groups that do not exist in the console are ignored. When the source has no
+group information at all, memberships are left unchanged.
+As the console does not return phone numbers, phones are only sent for users
+that are created or otherwise updated, unless --update-phones is used.
+Use --plan to preview the changes. To guard against syncing with a truncated
+export, no changes are made if more than --max-disable users would be disabled
+or deleted.
+For example: ` + ApplicationName + ` users sync --source people.ldif --plan`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		path, err := cmd.Flags().GetString("source")
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("source-format")
+		if err != nil {
+			return err
+		}
+		deleteMissing, err := cmd.Flags().GetBool("delete")
+		if err != nil {
+			return err
+		}
+		updatePhones, err := cmd.Flags().GetBool("update-phones")
+		if err != nil {
+			return err
+		}
+		maxDisable, err := cmd.Flags().GetInt("max-disable")
+		if err != nil {
+			return err
+		}
+		plan, err := cmd.Flags().GetBool("plan")
+		if err != nil {
+			return err
+		}
+
+		columnMap, err := inputColumnMapFromFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		source, err := readSyncSource(path, format, columnMap)
+		if err != nil {
+			return err
+		}
+		changes, err := planUserSync(cmd, source, deleteMissing, updatePhones, func(warning string) {
+			cmd.PrintErrln("WARNING: " + warning)
+		})
+		if err != nil {
+			return err
+		}
+
+		removals := syncRemovals(changes)
+		tooManyRemovals := fmt.Errorf("%d users would be disabled or deleted, more than --max-disable=%d", removals, maxDisable)
+		if plan {
+			tw := manifestPlanBuildTableWriter()
+			for _, change := range changes {
+				manifestPlanTableWriterAppend(tw, change)
+			}
+			err = printListOutputAndError(cmd, changes, tw, len(changes), nil)
+			cmd.PrintErrln("Plan: " + syncSummary(changes))
+			if removals > maxDisable {
+				cmd.PrintErrln("WARNING: " + tooManyRemovals.Error() + ", the sync would not be performed")
+			}
+			return err
+		}
+		if removals > maxDisable {
+			return tooManyRemovals
+		}
+
+		tw := manifestApplyBuildTableWriter()
+		performed := []*manifestChange{}
+		var loopErr error
+		for _, change := range changes {
+			err := executeUserSync(cmd, change)
+			if isDryRunError(err) {
+				err = nil
+			}
+			performed = append(performed, change)
+			manifestApplyTableWriterAppend(tw, change, err)
+			if err != nil {
+				if loopErr == nil {
+					loopErr = err
+				}
+				if !loopControlContinueOnError(cmd) {
+					break
+				}
+			}
+		}
+		if loopControlContinueOnError(cmd) {
+			loopErr = nil
+		}
+		return printListOutputAndError(cmd, performed, tw, len(changes), loopErr)
+	},
+}
+
+func init() {
+	usersCmd.AddCommand(usersSyncCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// usersSyncCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// usersSyncCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(usersSyncCmd)
+	initLoopControlFlags(usersSyncCmd)
+	initTenantFlags(usersSyncCmd)
+	usersSyncCmd.Flags().String("source", "", "directory export with the users, in LDIF or CSV format")
+	usersSyncCmd.Flags().String("source-format", "", "format of the source: ldif or csv (default from the file extension)")
+	usersSyncCmd.Flags().String("column-map", "", "YAML file, or preset ("+strings.Join(inputColumnMapPresetNames(), ", ")+"), mapping the columns of a CSV source to the expected ones")
+	usersSyncCmd.Flags().Bool("plan", false, "show the changes without performing them")
+	usersSyncCmd.Flags().Bool("delete", false, "delete console users that are not in the source, instead of disabling them")
+	usersSyncCmd.Flags().Bool("update-phones", false, "send the phone of every user in the source, as phones in the console can not be compared")
+	usersSyncCmd.Flags().Int("max-disable", 10, "maximum number of users that may be disabled or deleted")
+	usersSyncCmd.MarkFlagRequired("source")
+}
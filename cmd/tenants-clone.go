@@ -0,0 +1,122 @@
+// Package cmd implements fyde-cli commands
+package cmd
+
+/*
+Copyright © 2023 Barracuda Networks, Inc. <hello@barracuda.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// tenantsCloneCmd represents the clone command
+var tenantsCloneCmd = &cobra.Command{
+	Use:   "clone",
+	Short: "Copy configuration from one tenant to another",
+	Long: `Copy groups, proxies, resources, policies, web policies and settings from one
+tenant to another. Tenants may be given by ID or name.
+Objects the included kinds depend on are copied too (e.g. policies need their
+groups and resources, resources need their proxies), and references between
+them are remapped to the IDs in the target tenant. Objects that already exist
+in the target tenant with the same name are not copied, and are referenced
+instead. Users are not copied: policies and web policies reference the users
+of the target tenant with the same email, if any.
+For example: ` + ApplicationName + ` tenants clone --from template --to "Customer A" --include policies,webpolicies,settings`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		err := preRunCheckAuth(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		err = preRunFlagChecks(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		include, err := cmd.Flags().GetStringSlice("include")
+		if err != nil {
+			return err
+		}
+		_, err = cloneIncludedKinds(include)
+		return err
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		include, err := cmd.Flags().GetStringSlice("include")
+		if err != nil {
+			return err
+		}
+		tenants := []string{}
+		for _, flag := range []string{"from", "to"} {
+			value, err := cmd.Flags().GetString(flag)
+			if err != nil {
+				return err
+			}
+			tenant, err := resolveTenant(cmd, value)
+			if err != nil {
+				return fmt.Errorf("--%s: %v", flag, err)
+			}
+			tenants = append(tenants, tenant)
+		}
+		if tenants[0] == tenants[1] {
+			return fmt.Errorf("--from and --to are the same tenant")
+		}
+
+		tw, j := multiOpBuildTableWriter()
+		loopErr := cloneTenant(cmd, tenants[0], tenants[1], include, func(result importJSONResult) {
+			name := result.Kind + " " + result.Name
+			if kind, ok := cloneRecordKinds[result.Kind]; ok {
+				name = kind + " " + result.Name
+			}
+			if !result.OK {
+				multiOpTableWriterAppend(tw, &j, result.OldID, errors.New(name+": "+result.Result))
+			} else if result.NewID == nil {
+				multiOpTableWriterAppend(tw, &j, result.OldID, name+": "+result.Result)
+			} else {
+				multiOpTableWriterAppend(tw, &j, result.NewID, name+": "+result.Result)
+			}
+		})
+		return printListOutputAndError(cmd, j, tw, len(j), loopErr)
+	},
+}
+
+func init() {
+	tenantsCmd.AddCommand(tenantsCloneCmd)
+
+	// Here you will define your flags and configuration settings.
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// tenantsCloneCmd.PersistentFlags().String("foo", "", "A help for foo")
+
+	// Cobra supports local flags which will only run when this command
+	// is called directly, e.g.:
+	// tenantsCloneCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+
+	initOutputFlags(tenantsCloneCmd)
+	initLoopControlFlags(tenantsCloneCmd)
+	// the tenant is switched between --from and --to while cloning
+	initTenantFlags(tenantsCloneCmd)
+	tenantsCloneCmd.Flags().MarkHidden("tenant")
+	tenantsCloneCmd.Flags().String("from", "", "ID or name of the tenant to copy from")
+	tenantsCloneCmd.Flags().String("to", "", "ID or name of the tenant to copy to")
+	tenantsCloneCmd.Flags().StringSlice("include", []string{}, "kinds of objects to copy: groups, proxies, resources, policies, webpolicies and/or settings")
+	tenantsCloneCmd.MarkFlagRequired("from")
+	tenantsCloneCmd.MarkFlagRequired("to")
+	tenantsCloneCmd.MarkFlagRequired("include")
+}
@@ -121,7 +121,7 @@ var enrollmentGenerateCmd = &cobra.Command{
 				})
 				createdList = append(createdList, nil)
 
-				if loopControlContinueOnError(cmd) {
+				if loopControlContinueOnError(cmd) || isDryRunError(err) {
 					err = nil
 					continue
 				}
@@ -191,7 +191,7 @@ var enrollmentRevokeCmd = &cobra.Command{
 				}
 
 				multiOpTableWriterAppend(tw, &j, arg, processErrorResponse(err))
-				if loopControlContinueOnError(cmd) {
+				if loopControlContinueOnError(cmd) || isDryRunError(err) {
 					err = nil
 					continue
 				}
@@ -333,7 +333,7 @@ var enrollmentChangeCmd = &cobra.Command{
 				})
 				editedList = append(editedList, nil)
 
-				if loopControlContinueOnError(cmd) {
+				if loopControlContinueOnError(cmd) || isDryRunError(err) {
 					err = nil
 					continue
 				}
@@ -463,7 +463,7 @@ var enrollmentEmailCmd = &cobra.Command{
 				}
 
 				multiOpTableWriterAppend(tw, &j, arg, processErrorResponse(err))
-				if loopControlContinueOnError(cmd) {
+				if loopControlContinueOnError(cmd) || isDryRunError(err) {
 					err = nil
 					continue
 				}
@@ -21,6 +21,7 @@ require (
 	github.com/thoas/go-funk v0.7.0
 	golang.org/x/term v0.0.0-20201210144234-2321bbc49cbf
 	gopkg.in/h2non/gock.v1 v1.0.16
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -61,5 +62,4 @@ require (
 	golang.org/x/text v0.3.7 // indirect
 	gopkg.in/ini.v1 v1.62.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )